@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/mrcnserkan/crypto/models"
@@ -27,11 +28,15 @@ AVAILABLE COMMANDS:
   add     Set a new price alert
   list    View active alerts
   remove  Remove specific alerts
+  history Show triggered alerts
 
 EXAMPLES:
   1. Set price alerts:
      crypto alert add bitcoin 50000 above    # Alert when BTC goes above $50,000
      crypto alert add ethereum 2000 below    # Alert when ETH goes below $2,000
+     crypto alert add bitcoin change +10% 24h
+     crypto alert add bitcoin cross-above 50 # Price crosses its 50-day average
+     crypto alert add portfolio value above 100000
 
   2. View alerts:
      crypto alert list                       # Show all active alerts
@@ -45,51 +50,95 @@ EXAMPLES:
      crypto alert start                      # Background daemon
      crypto alert stop                       # Stop daemon
      crypto alert status                     # Daemon status
+     crypto alert install-service            # Run the daemon with systemd
+
+  5. Review triggered alerts:
+     crypto alert history --since 7d         # Alerts fired in the last week
 
 NOTE: Alerts are NOT checked automatically. Run 'crypto alert watch' or 'crypto alert start' after adding alerts.`,
 }
 
 var alertAddCmd = &cobra.Command{
-	Use:   "add [coin-id] [price] [above/below]",
+	Use:   "add [coin-id] [condition...]",
 	Short: "Add price alert",
-	Long: `Set a new price alert for a cryptocurrency.
-
-ARGUMENTS:
-  coin-id  ID of the cryptocurrency (e.g., bitcoin)
-  price    Target price for the alert
-  type     Alert type: 'above' or 'below'
+	Long: `Set a new alert for a cryptocurrency.
+
+CONDITIONS:
+  <price> above|below       Price goes above or below a target
+  change <+/-pct>% [window] Price rises (+) or falls (-) by pct within window (default 24h)
+  cross-above <days>        Price crosses above its <days>-day moving average
+  cross-below <days>        Price crosses below its <days>-day moving average
+  volume <n>x [window]      24h volume reaches n times its daily average over window (default 7d)
+  rank [positions]          Market-cap rank moves by positions (default 1) from today
+  range <low> <high>        Price leaves the range
+  weight <pct>%             The coin's share of your portfolio value exceeds pct
+
+PORTFOLIO CONDITIONS (use 'portfolio' as the coin-id):
+  value above|below <value> Total portfolio value goes above or below value
+  daily <pct>%              Portfolio value moves by pct in either direction within 24h
+
+Windows are written like 1h, 24h, 7d or 2w.
+
+REPEATING:
+  Alerts are removed when they fire unless --repeat says otherwise:
+  --repeat recurring   Fire again after --cooldown (default 1h)
+  --repeat rearm       Fire again once the price moves back past the target
+                       by --hysteresis percent (default 1)
+  --expires            Remove the alert after a window (e.g. 7d) or at the
+                       end of a date (YYYY-MM-DD)
 
 EXAMPLES:
   crypto alert add bitcoin 50000 above    # Alert when BTC goes above $50,000
-  crypto alert add ethereum 2000 below    # Alert when ETH goes below $2,000`,
-	Args: cobra.ExactArgs(3),
+  crypto alert add ethereum 2000 below    # Alert when ETH goes below $2,000
+  crypto alert add bitcoin change +10% 24h
+  crypto alert add solana change -5% 1h
+  crypto alert add bitcoin cross-below 200
+  crypto alert add ethereum volume 3x
+  crypto alert add solana rank 2
+  crypto alert add bitcoin range 60000 70000
+  crypto alert add bitcoin weight 40%
+  crypto alert add portfolio value above 100000
+  crypto alert add portfolio daily 5%
+  crypto alert add bitcoin 70000 above --repeat rearm --hysteresis 2
+  crypto alert add bitcoin change -5% 1h --repeat recurring --cooldown 6h --expires 30d`,
+	Args: cobra.RangeArgs(2, 4),
 	Run: func(cmd *cobra.Command, args []string) {
 		coinID := utils.NormalizeCoinID(args[0])
-		price, err := strconv.ParseFloat(args[1], 64)
-		if err != nil || price <= 0 {
-			fmt.Println("Error: Invalid price value (must be greater than zero)")
-			os.Exit(1)
-		}
-		condition := strings.ToLower(args[2])
-		if condition != "above" && condition != "below" {
-			fmt.Println("Error: Condition must be 'above' or 'below'")
+		alert, err := parseAlertCondition(args[1:])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
 
 		currency, _ := rootCmd.PersistentFlags().GetString("currency")
 		currency = utils.NormalizeCurrency(currency)
+		alert.CoinID = coinID
+		alert.Currency = currency
+		if err := applyRepeatFlags(cmd, &alert, time.Now()); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := checkAlertTarget(alert); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if alert.IsPortfolioAlert() {
+			addPortfolioAlert(alert)
+			return
+		}
 
 		coin, err := coinGecko.GetCoinDetail(coinID)
 		if err != nil || coin.ID == "" {
 			fmt.Printf("Error: Could not find coin with ID '%s'\n", coinID)
 			os.Exit(1)
 		}
-
-		alert := models.Alert{
-			CoinID:    coinID,
-			Price:     price,
-			Condition: condition,
-			Currency:  currency,
+		if alert.Condition == models.ConditionRankChange {
+			if coin.MarketCapRank <= 0 {
+				fmt.Printf("Error: %s has no market-cap rank\n", coinID)
+				os.Exit(1)
+			}
+			alert.Rank = int(coin.MarketCapRank)
 		}
 
 		if err := alertManager.AddAlert(alert); err != nil {
@@ -99,15 +148,239 @@ EXAMPLES:
 
 		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
 		fmt.Printf("\n%s Alert added successfully!\n", titleColor("🔔"))
-		fmt.Printf("You will be notified when %s goes %s %s%.2f\n",
-			strings.ToUpper(coinID),
-			condition,
-			utils.CurrencySymbol(currency),
-			price)
+		if alert.IsPriceTarget() {
+			fmt.Printf("You will be notified when %s goes %s %s%.2f\n",
+				strings.ToUpper(coinID),
+				alert.Condition,
+				utils.CurrencySymbol(currency),
+				alert.Price)
+		} else {
+			fmt.Printf("You will be notified when %s: %s\n",
+				strings.ToUpper(coinID),
+				alert.Describe(utils.CurrencySymbol(currency)))
+		}
 		fmt.Println("\nRun `crypto alert watch` (foreground) or `crypto alert start` (background) to monitor alerts.")
 	},
 }
 
+// applyRepeatFlags sets the repeat mode, cooldown, hysteresis and expiry of
+// alert from the add command's flags.
+func applyRepeatFlags(cmd *cobra.Command, alert *models.Alert, now time.Time) error {
+	repeat, _ := cmd.Flags().GetString("repeat")
+	alert.Repeat = strings.ToLower(repeat)
+	if cmd.Flags().Changed("cooldown") {
+		alert.Cooldown, _ = cmd.Flags().GetString("cooldown")
+	} else if alert.Repeat == models.RepeatRecurring {
+		alert.Cooldown = models.DefaultCooldown
+	}
+	if cmd.Flags().Changed("hysteresis") {
+		alert.Hysteresis, _ = cmd.Flags().GetFloat64("hysteresis")
+	} else if alert.Repeat == models.RepeatRearm {
+		alert.Hysteresis = models.DefaultHysteresis
+	}
+	if alert.Repeat == models.RepeatOnce {
+		alert.Repeat = ""
+	}
+
+	if expires, _ := cmd.Flags().GetString("expires"); expires != "" {
+		at, err := parseAlertExpiry(expires, now)
+		if err != nil {
+			return err
+		}
+		alert.ExpiresAt = &at
+	}
+	// The condition is checked by AddAlert, once rank alerts have their
+	// current rank
+	return alert.ValidateRepeat()
+}
+
+// parseAlertExpiry accepts a window from now (e.g. 7d) or a date, meaning
+// the end of that day in local time.
+func parseAlertExpiry(s string, now time.Time) (time.Time, error) {
+	if window, err := models.ParseAlertWindow(s); err == nil {
+		return now.Add(window), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid expiry %q (use a window like 7d or a date like 2026-12-31)", s)
+	}
+	return day.AddDate(0, 0, 1), nil
+}
+
+// checkAlertTarget rejects portfolio-wide conditions on a coin and coin
+// conditions on the portfolio.
+func checkAlertTarget(alert models.Alert) error {
+	wholePortfolio := alert.IsPortfolioAlert() && alert.Condition != models.ConditionWeightAbove
+	if wholePortfolio && alert.CoinID != models.PortfolioAlertID {
+		return fmt.Errorf("value and daily alerts apply to the whole portfolio; use 'crypto alert add portfolio ...'")
+	}
+	if !wholePortfolio && alert.CoinID == models.PortfolioAlertID {
+		return fmt.Errorf("portfolio alerts support 'value above|below <value>' and 'daily <pct>%%'")
+	}
+	return nil
+}
+
+// addPortfolioAlert stores an alert evaluated against the portfolio, which
+// needs no coin lookup.
+func addPortfolioAlert(alert models.Alert) {
+	if alert.Condition == models.ConditionWeightAbove && portfolio.GetHolding(alert.CoinID) <= 0 {
+		fmt.Printf("Warning: %s is not in your portfolio; the alert can trigger once it is\n", alert.CoinID)
+	}
+	if err := alertManager.AddAlert(alert); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	fmt.Printf("\n%s Alert added successfully!\n", titleColor("🔔"))
+	subject := "your portfolio"
+	if alert.Condition == models.ConditionWeightAbove {
+		subject = strings.ToUpper(alert.CoinID) + " in your portfolio"
+	}
+	fmt.Printf("You will be notified when %s: %s\n", subject, alert.Describe(utils.CurrencySymbol(alert.Currency)))
+	fmt.Println("\nRun `crypto alert watch` (foreground) or `crypto alert start` (background) to monitor alerts.")
+}
+
+// parseAlertCondition parses the condition arguments of alert add and
+// remove; see alertAddCmd for the syntax.
+func parseAlertCondition(args []string) (models.Alert, error) {
+	kind := strings.ToLower(args[0])
+	rest := args[1:]
+	argCount := func(min, max int) error {
+		if len(rest) < min || len(rest) > max {
+			return fmt.Errorf("wrong number of arguments for %s (see 'crypto alert add --help')", kind)
+		}
+		return nil
+	}
+	parsePositive := func(s, what string) (float64, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v <= 0 {
+			return 0, fmt.Errorf("invalid %s %q (must be greater than zero)", what, s)
+		}
+		return v, nil
+	}
+
+	switch kind {
+	case "change":
+		if err := argCount(1, 2); err != nil {
+			return models.Alert{}, err
+		}
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(rest[0], "%"), 64)
+		if err != nil || percent == 0 {
+			return models.Alert{}, fmt.Errorf("invalid percent %q (use e.g. +10%% or -5%%)", rest[0])
+		}
+		alert := models.Alert{Condition: models.ConditionChange, Percent: percent, Window: models.DefaultChangeWindow}
+		if len(rest) == 2 {
+			alert.Window = rest[1]
+		}
+		return alert, alert.Validate()
+
+	case "cross-above", "cross_above", "cross-below", "cross_below":
+		if err := argCount(1, 1); err != nil {
+			return models.Alert{}, err
+		}
+		period, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(rest[0]), "d"))
+		if err != nil {
+			return models.Alert{}, fmt.Errorf("invalid moving average period %q (use days, e.g. 50)", rest[0])
+		}
+		alert := models.Alert{Condition: models.ConditionCrossAbove, MAPeriod: period}
+		if strings.HasSuffix(kind, "below") {
+			alert.Condition = models.ConditionCrossBelow
+		}
+		return alert, alert.Validate()
+
+	case "volume":
+		if err := argCount(1, 2); err != nil {
+			return models.Alert{}, err
+		}
+		multiplier, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(rest[0]), "x"), 64)
+		if err != nil {
+			return models.Alert{}, fmt.Errorf("invalid volume multiplier %q (use e.g. 3x)", rest[0])
+		}
+		alert := models.Alert{Condition: models.ConditionVolumeSpike, Multiplier: multiplier, Window: models.DefaultVolumeWindow}
+		if len(rest) == 2 {
+			alert.Window = rest[1]
+		}
+		return alert, alert.Validate()
+
+	case "rank":
+		if err := argCount(0, 1); err != nil {
+			return models.Alert{}, err
+		}
+		move := 1
+		if len(rest) == 1 {
+			n, err := strconv.Atoi(rest[0])
+			if err != nil || n <= 0 {
+				return models.Alert{}, fmt.Errorf("invalid rank move %q (must be a positive number of positions)", rest[0])
+			}
+			move = n
+		}
+		// The baseline rank is filled in from current market data
+		return models.Alert{Condition: models.ConditionRankChange, RankMove: move}, nil
+
+	case "range":
+		if err := argCount(2, 2); err != nil {
+			return models.Alert{}, err
+		}
+		low, err := parsePositive(rest[0], "low price")
+		if err != nil {
+			return models.Alert{}, err
+		}
+		high, err := parsePositive(rest[1], "high price")
+		if err != nil {
+			return models.Alert{}, err
+		}
+		alert := models.Alert{Condition: models.ConditionRangeExit, Low: low, High: high}
+		return alert, alert.Validate()
+
+	case "value":
+		if err := argCount(2, 2); err != nil {
+			return models.Alert{}, err
+		}
+		direction := strings.ToLower(rest[0])
+		if direction != models.ConditionAbove && direction != models.ConditionBelow {
+			return models.Alert{}, fmt.Errorf("value alerts need 'above' or 'below', got %q", rest[0])
+		}
+		value, err := parsePositive(rest[1], "portfolio value")
+		if err != nil {
+			return models.Alert{}, err
+		}
+		alert := models.Alert{Condition: models.ConditionValueAbove, Price: value}
+		if direction == models.ConditionBelow {
+			alert.Condition = models.ConditionValueBelow
+		}
+		return alert, alert.Validate()
+
+	case "daily", "weight":
+		if err := argCount(1, 1); err != nil {
+			return models.Alert{}, err
+		}
+		percent, err := parsePositive(strings.TrimSuffix(rest[0], "%"), "percent")
+		if err != nil {
+			return models.Alert{}, err
+		}
+		alert := models.Alert{Condition: models.ConditionDailyPnL, Percent: percent}
+		if kind == "weight" {
+			alert.Condition = models.ConditionWeightAbove
+		}
+		return alert, alert.Validate()
+	}
+
+	// <price> above|below
+	if len(args) != 2 {
+		return models.Alert{}, fmt.Errorf("unknown alert condition %q (see 'crypto alert add --help')", args[0])
+	}
+	price, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || price <= 0 {
+		return models.Alert{}, fmt.Errorf("Invalid price value (must be greater than zero)")
+	}
+	condition := strings.ToLower(args[1])
+	if condition != models.ConditionAbove && condition != models.ConditionBelow {
+		return models.Alert{}, fmt.Errorf("Condition must be 'above' or 'below'")
+	}
+	return models.Alert{Condition: condition, Price: price}, nil
+}
+
 var alertListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List active alerts",
@@ -115,8 +388,8 @@ var alertListCmd = &cobra.Command{
 
 OUTPUT INCLUDES:
   • Cryptocurrency name
-  • Alert condition (above/below)
-  • Target price
+  • Alert condition and target
+  • Repeat mode and expiry
   • Creation date and time
 
 EXAMPLE:
@@ -133,7 +406,7 @@ EXAMPLE:
 		fmt.Printf("\n%s Active Price Alerts\n\n", titleColor("🔔"))
 
 		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Coin", "Condition", "Target Price", "Currency", "Created At"})
+		table.SetHeader([]string{"Coin", "Condition", "Target", "Currency", "Repeat", "Expires", "Created At"})
 		table.SetBorder(false)
 		table.SetHeaderColor(
 			tablewriter.Colors{tablewriter.FgHiBlueColor},
@@ -141,6 +414,8 @@ EXAMPLE:
 			tablewriter.Colors{tablewriter.FgHiBlueColor},
 			tablewriter.Colors{tablewriter.FgHiBlueColor},
 			tablewriter.Colors{tablewriter.FgHiBlueColor},
+			tablewriter.Colors{tablewriter.FgHiBlueColor},
+			tablewriter.Colors{tablewriter.FgHiBlueColor},
 		)
 
 		for _, alert := range alerts {
@@ -152,8 +427,10 @@ EXAMPLE:
 			table.Rich([]string{
 				strings.ToUpper(alert.CoinID),
 				alert.Condition,
-				fmt.Sprintf("%s%.2f", utils.CurrencySymbol(currency), alert.Price),
+				alertTarget(alert, utils.CurrencySymbol(currency)),
 				strings.ToUpper(currency),
+				alertRepeat(alert),
+				alertExpiry(alert),
 				alert.CreatedAt.Format("2006-01-02 15:04"),
 			}, []tablewriter.Colors{
 				{tablewriter.FgHiWhiteColor},
@@ -161,6 +438,8 @@ EXAMPLE:
 				{tablewriter.FgHiWhiteColor},
 				{tablewriter.FgHiWhiteColor},
 				{tablewriter.FgHiWhiteColor},
+				{tablewriter.FgHiWhiteColor},
+				{tablewriter.FgHiWhiteColor},
 			})
 		}
 
@@ -168,20 +447,50 @@ EXAMPLE:
 	},
 }
 
+// alertTarget is the list column describing what an alert waits for.
+func alertTarget(alert models.Alert, currencySymbol string) string {
+	if alert.IsPriceTarget() {
+		return fmt.Sprintf("%s%.2f", currencySymbol, alert.Price)
+	}
+	return alert.Describe(currencySymbol)
+}
+
+// alertRepeat is the list column describing when an alert fires again.
+func alertRepeat(alert models.Alert) string {
+	switch alert.RepeatMode() {
+	case models.RepeatRecurring:
+		return "every " + alert.Cooldown
+	case models.RepeatRearm:
+		if alert.Disarmed {
+			return fmt.Sprintf("rearm ±%g%% (waiting)", alert.Hysteresis)
+		}
+		return fmt.Sprintf("rearm ±%g%%", alert.Hysteresis)
+	}
+	return "once"
+}
+
+func alertExpiry(alert models.Alert) string {
+	if alert.ExpiresAt == nil {
+		return "-"
+	}
+	return alert.ExpiresAt.Format("2006-01-02 15:04")
+}
+
 var alertRemoveCmd = &cobra.Command{
-	Use:   "remove [coin-id] [price] [above/below]",
+	Use:   "remove [coin-id] [condition...]",
 	Short: "Remove price alert",
 	Long: `Remove price alerts for a cryptocurrency.
 
 ARGUMENTS:
-  coin-id  ID of the cryptocurrency (e.g., bitcoin)
-  price    Optional target price (requires condition)
-  type     Optional alert type: 'above' or 'below'
+  coin-id    ID of the cryptocurrency (e.g., bitcoin)
+  condition  Optional condition, written as for 'crypto alert add'
 
 EXAMPLES:
   crypto alert remove bitcoin                    # Remove all alerts for Bitcoin
-  crypto alert remove bitcoin 50000 above        # Remove specific alert`,
-	Args: cobra.RangeArgs(1, 3),
+  crypto alert remove bitcoin 50000 above        # Remove specific alert
+  crypto alert remove bitcoin change +10% 24h    # Remove a change alert
+  crypto alert remove portfolio daily 5%         # Remove a portfolio alert`,
+	Args: cobra.RangeArgs(1, 4),
 	Run: func(cmd *cobra.Command, args []string) {
 		coinID := utils.NormalizeCoinID(args[0])
 
@@ -190,24 +499,24 @@ EXAMPLES:
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-		} else if len(args) == 3 {
-			price, err := strconv.ParseFloat(args[1], 64)
-			if err != nil || price <= 0 {
-				fmt.Println("Error: Invalid price value (must be greater than zero)")
+		} else {
+			alert, err := parseAlertCondition(args[1:])
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-			condition := strings.ToLower(args[2])
-			if condition != "above" && condition != "below" {
-				fmt.Println("Error: Condition must be 'above' or 'below'")
-				os.Exit(1)
+			if alert.IsPriceTarget() {
+				err = alertManager.RemoveAlertByTarget(coinID, alert.Price, alert.Condition)
+			} else {
+				currency, _ := rootCmd.PersistentFlags().GetString("currency")
+				alert.CoinID = coinID
+				alert.Currency = utils.NormalizeCurrency(currency)
+				err = alertManager.RemoveMatchingAlert(alert)
 			}
-			if err := alertManager.RemoveAlertByTarget(coinID, price, condition); err != nil {
+			if err != nil {
 				fmt.Printf("Error: %v\n", err)
 				os.Exit(1)
 			}
-		} else {
-			fmt.Println("Error: Provide coin-id only, or coin-id + price + condition")
-			os.Exit(1)
 		}
 
 		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
@@ -216,6 +525,10 @@ EXAMPLES:
 }
 
 func init() {
+	alertAddCmd.Flags().String("repeat", models.RepeatOnce, "Repeat mode: once, recurring or rearm")
+	alertAddCmd.Flags().String("cooldown", "", "Time between recurring alerts, e.g. 1h or 1d (default 1h)")
+	alertAddCmd.Flags().Float64("hysteresis", 0, "Percent the price must move back before a rearm alert fires again (default 1)")
+	alertAddCmd.Flags().String("expires", "", "Remove the alert after a window (e.g. 7d) or at the end of a date (YYYY-MM-DD)")
 	alertCmd.AddCommand(alertAddCmd)
 	alertCmd.AddCommand(alertListCmd)
 	alertCmd.AddCommand(alertRemoveCmd)
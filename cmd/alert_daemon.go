@@ -5,10 +5,13 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/service"
 	"github.com/spf13/cobra"
 )
 
@@ -20,11 +23,36 @@ var alertStartCmd = &cobra.Command{
 The daemon writes its PID to ~/.crypto/alert.pid and checks alerts
 every 5 minutes (configurable via config.json).
 
+Use --stream to also check price alerts on live exchange ticks, and
+--metrics-addr to serve Prometheus metrics (see 'crypto alert watch
+--help').
+
+When the systemd user service is installed (see 'crypto alert
+install-service'), the service is started instead. Its --stream and
+--metrics-addr are set when it is installed.
+
 EXAMPLE:
-  crypto alert start`,
+  crypto alert start
+  crypto alert start --stream
+  crypto alert start --metrics-addr 127.0.0.1:9464`,
 	Run: func(cmd *cobra.Command, args []string) {
-		if running, pid := daemonState.IsRunning(); running {
-			fmt.Printf("Alert daemon already running (PID %d)\n", pid)
+		daemon := findAlertDaemon()
+		if daemon.running {
+			fmt.Printf("Alert daemon already running (PID %d)\n", daemon.pid)
+			return
+		}
+		if daemon.service != nil {
+			if flags := serviceInstallFlags(cmd); flags != "" {
+				fmt.Printf("Error: %s cannot be passed to the installed service; reinstall it with 'crypto alert install-service %s'\n", flags, flags)
+				os.Exit(1)
+			}
+			if _, err := systemctl("start", alertServiceName); err != nil {
+				fmt.Printf("Error starting daemon: %v\n", err)
+				os.Exit(1)
+			}
+			titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+			fmt.Printf("\n%s Alert daemon started (%s)\n", titleColor("🔔"), alertServiceName)
+			fmt.Println("Logs: journalctl --user -u crypto-alert -f")
 			return
 		}
 		if len(alertManager.GetAlerts()) == 0 {
@@ -39,13 +67,24 @@ EXAMPLE:
 		}
 
 		logFile := filepath.Join(configDir, "alert.log")
-		logOut, err := os.OpenFile(logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+		// The daemon logs through its own rotating writer; stray output such
+		// as a crash goes to a file of its own so it never lands in the
+		// middle of a rotation
+		outFile := filepath.Join(configDir, "alert-output.log")
+		logOut, err := os.OpenFile(outFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
 		if err != nil {
 			fmt.Printf("Error creating log file: %v\n", err)
 			os.Exit(1)
 		}
 
-		child := exec.Command(executable, "alert", "watch")
+		childArgs := []string{"alert", "watch", "--daemon", "--log-file", logFile}
+		if stream, _ := cmd.Flags().GetBool("stream"); stream {
+			childArgs = append(childArgs, "--stream")
+		}
+		if addr, _ := cmd.Flags().GetString("metrics-addr"); addr != "" {
+			childArgs = append(childArgs, "--metrics-addr", addr)
+		}
+		child := exec.Command(executable, childArgs...)
 		child.Stdout = logOut
 		child.Stderr = logOut
 		child.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
@@ -72,6 +111,17 @@ var alertStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop background alert daemon",
 	Run: func(cmd *cobra.Command, args []string) {
+		daemon := findAlertDaemon()
+		if daemon.service != nil && daemon.running {
+			// Stopped until the next login or 'crypto alert start'
+			if _, err := systemctl("stop", alertServiceName); err != nil {
+				fmt.Printf("Error stopping daemon: %v\n", err)
+				os.Exit(1)
+			}
+			titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+			fmt.Printf("\n%s Alert daemon stopped (%s)\n", titleColor("🔔"), alertServiceName)
+			return
+		}
 		running, pid := daemonState.IsRunning()
 		if !running {
 			_ = daemonState.RemovePID()
@@ -105,28 +155,147 @@ var alertStopCmd = &cobra.Command{
 var alertStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show alert daemon status",
+	Long: `Show whether the alert daemon is running and, when it is, what it has
+been doing: last and next check, last error, requests made to each
+provider and recent triggers.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		running, pid := daemonState.IsRunning()
+		daemon := findAlertDaemon()
 		alerts := alertManager.GetAlerts()
 
 		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
 		fmt.Printf("\n%s Alert Status\n\n", titleColor("🔔"))
-		if running {
-			fmt.Printf("Daemon: running (PID %d)\n", pid)
+		if daemon.service != nil {
+			fmt.Printf("Service: %s (%s, %s)\n", alertServiceName, daemon.service.UnitFileState, daemon.service.SubState)
+		}
+		if daemon.running {
+			fmt.Printf("Daemon: running (PID %d)\n", daemon.pid)
+			response, err := service.SendControl(daemonState.SocketPath(), service.ControlRequest{Command: service.ControlStatus})
+			if err != nil {
+				fmt.Printf("Warning: could not query the daemon: %v\n", err)
+			} else if response.Status != nil {
+				printDaemonStatus(*response.Status, time.Now())
+			}
 		} else {
 			fmt.Println("Daemon: stopped")
 		}
 		fmt.Printf("Active alerts: %d\n", len(alerts))
-		if len(alerts) > 0 && !running {
+		if len(alerts) > 0 && !daemon.running {
 			fmt.Println("\nTip: run `crypto alert watch` or `crypto alert start` to monitor alerts")
 		}
 	},
 }
 
+// serviceInstallFlags returns the daemon flags passed to cmd, which the
+// systemd service takes from its unit instead, e.g. "--stream".
+func serviceInstallFlags(cmd *cobra.Command) string {
+	var flags []string
+	if stream, _ := cmd.Flags().GetBool("stream"); stream {
+		flags = append(flags, "--stream")
+	}
+	if addr, _ := cmd.Flags().GetString("metrics-addr"); addr != "" {
+		flags = append(flags, "--metrics-addr "+addr)
+	}
+	return strings.Join(flags, " ")
+}
+
+// printDaemonStatus prints the status reported by the daemon's control
+// socket.
+func printDaemonStatus(status service.DaemonStatus, now time.Time) {
+	fmt.Printf("Started: %s\n", formatStatusTime(status.StartedAt, now))
+	fmt.Printf("Check interval: %s\n", status.Interval)
+	if status.Stream != "" {
+		fmt.Printf("Price stream: %s\n", status.Stream)
+	}
+	fmt.Printf("Last check: %s\n", formatStatusTime(status.LastCheck, now))
+	switch {
+	case status.Running && status.NextCheck.IsZero():
+		fmt.Println("Next check: in progress")
+	case status.Running:
+		fmt.Printf("Next check: %s\n", formatStatusTime(status.NextCheck, now))
+	default:
+		fmt.Println("Next check: none (no active alerts)")
+	}
+	if status.LastError != "" {
+		fmt.Printf("Last error: %s (%s)\n", status.LastError, formatStatusTime(status.LastErrorAt, now))
+	}
+
+	providers := make([]string, 0, len(status.Requests))
+	for provider := range status.Requests {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	counts := make([]string, 0, len(providers))
+	for _, provider := range providers {
+		counts = append(counts, fmt.Sprintf("%s %d", provider, status.Requests[provider]))
+	}
+	if len(counts) == 0 {
+		counts = append(counts, "none")
+	}
+	fmt.Printf("Requests: %s\n", strings.Join(counts, ", "))
+
+	if len(status.RecentTriggers) > 0 {
+		fmt.Println("Recent triggers:")
+		for i := len(status.RecentTriggers) - 1; i >= 0; i-- {
+			trigger := status.RecentTriggers[i]
+			fmt.Printf("  %s  %s\n", trigger.TriggeredAt.Local().Format("2006-01-02 15:04"), trigger.Message)
+		}
+	}
+}
+
+// formatStatusTime formats t with how long ago or ahead of now it is.
+func formatStatusTime(t, now time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	d := now.Sub(t).Round(time.Second)
+	relative := fmt.Sprintf("%s ago", d)
+	if d < 0 {
+		relative = fmt.Sprintf("in %s", -d)
+	}
+	return fmt.Sprintf("%s (%s)", t.Local().Format("2006-01-02 15:04:05"), relative)
+}
+
+var alertReloadCmd = &cobra.Command{
+	Use:   "reload",
+	Short: "Make the alert daemon reload alerts and config",
+	Long: `Make the running alert daemon reload alerts.json and config.json now
+instead of within a few seconds, and report what changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sendDaemonCommand(service.ControlReload)
+	},
+}
+
+var alertCheckNowCmd = &cobra.Command{
+	Use:   "check-now",
+	Short: "Make the alert daemon check all alerts now",
+	Run: func(cmd *cobra.Command, args []string) {
+		sendDaemonCommand(service.ControlCheckNow)
+	},
+}
+
+// sendDaemonCommand sends a command to the running daemon and prints its
+// answer.
+func sendDaemonCommand(command string) {
+	if !findAlertDaemon().running {
+		fmt.Println("Alert daemon is not running")
+		return
+	}
+	response, err := service.SendControl(daemonState.SocketPath(), service.ControlRequest{Command: command})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(response.Message)
+}
+
 func init() {
+	alertStartCmd.Flags().Bool("stream", false, "Check price alerts on live exchange ticks")
+	alertStartCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
 	alertCmd.AddCommand(alertStartCmd)
 	alertCmd.AddCommand(alertStopCmd)
 	alertCmd.AddCommand(alertStatusCmd)
+	alertCmd.AddCommand(alertReloadCmd)
+	alertCmd.AddCommand(alertCheckNowCmd)
 }
 
 func minutesToDuration(minutes int) time.Duration {
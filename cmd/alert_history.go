@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var alertHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show triggered alerts",
+	Long: `Show alerts that have fired, oldest first.
+
+Every trigger is recorded with the alert definition, the price that
+triggered it, the time and whether 'alert watch' or the daemon fired it.
+
+FLAGS:
+  --coin     Only show alerts for a coin (use 'portfolio' for portfolio alerts)
+  --since    Only show triggers within a window (e.g. 7d) or since a date (YYYY-MM-DD)
+  --format   table or json
+
+EXAMPLES:
+  crypto alert history
+  crypto alert history --coin bitcoin --since 7d
+  crypto alert history --since 2026-01-01 --format json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		coinID, _ := cmd.Flags().GetString("coin")
+		sinceStr, _ := cmd.Flags().GetString("since")
+		format, _ := cmd.Flags().GetString("format")
+
+		filter := models.AlertHistoryFilter{CoinID: utils.NormalizeCoinID(coinID)}
+		if sinceStr != "" {
+			since, err := parseHistorySince(sinceStr, time.Now())
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			filter.Since = since
+		}
+
+		triggers, err := alertHistory.Query(filter)
+		if err != nil {
+			fmt.Printf("Error reading alert history: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch strings.ToLower(format) {
+		case "json":
+			if triggers == nil {
+				triggers = []models.AlertTrigger{}
+			}
+			data, err := json.MarshalIndent(triggers, "", "  ")
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(string(data))
+		case "table":
+			printAlertHistory(triggers)
+		default:
+			fmt.Printf("Error: unknown format %q (use table or json)\n", format)
+			os.Exit(1)
+		}
+	},
+}
+
+// parseHistorySince accepts a window back from now (e.g. 7d) or a date,
+// meaning the start of that day in local time.
+func parseHistorySince(s string, now time.Time) (time.Time, error) {
+	if window, err := models.ParseAlertWindow(s); err == nil {
+		return now.Add(-window), nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", s, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q (use a window like 7d or a date like 2026-01-01)", s)
+	}
+	return day, nil
+}
+
+func printAlertHistory(triggers []models.AlertTrigger) {
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	if len(triggers) == 0 {
+		fmt.Printf("\n%s No triggered alerts\n", titleColor("🔔"))
+		return
+	}
+	fmt.Printf("\n%s Alert History\n\n", titleColor("🔔"))
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Triggered At", "Coin", "Condition", "Price", "Currency", "Source"})
+	table.SetBorder(false)
+	table.SetHeaderColor(
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+		tablewriter.Colors{tablewriter.FgHiBlueColor},
+	)
+
+	for _, trigger := range triggers {
+		alert := trigger.Alert
+		currency := utils.NormalizeCurrency(alert.Currency)
+		currencySymbol := utils.CurrencySymbol(currency)
+		price := fmt.Sprintf("%s%.2f", currencySymbol, trigger.Price)
+		if alert.Condition == models.ConditionWeightAbove {
+			price = fmt.Sprintf("%.1f%%", trigger.Price)
+		}
+		table.Append([]string{
+			trigger.TriggeredAt.Local().Format("2006-01-02 15:04"),
+			strings.ToUpper(alert.CoinID),
+			alert.Describe(currencySymbol),
+			price,
+			strings.ToUpper(currency),
+			trigger.Source,
+		})
+	}
+	table.Render()
+}
+
+func init() {
+	alertHistoryCmd.Flags().String("coin", "", "Only show alerts for this coin")
+	alertHistoryCmd.Flags().String("since", "", "Only show triggers within a window (e.g. 7d) or since a date (YYYY-MM-DD)")
+	alertHistoryCmd.Flags().String("format", "table", "Output format: table or json")
+	alertCmd.AddCommand(alertHistoryCmd)
+}
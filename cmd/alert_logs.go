@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const logFollowInterval = 500 * time.Millisecond
+
+var alertLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Show the alert daemon log",
+	Long: `Show the end of the alert daemon log, ~/.crypto/alert.log. With -f,
+keep printing new lines as they are written, across log rotations, until
+Ctrl+C.
+
+When the daemon runs as the systemd user service, its log is in the
+journal and this runs journalctl.
+
+The log level, format (text or json) and rotation are set under "log" in
+config.json.
+
+EXAMPLE:
+  crypto alert logs
+  crypto alert logs -n 100
+  crypto alert logs -f`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lines, _ := cmd.Flags().GetInt("lines")
+		follow, _ := cmd.Flags().GetBool("follow")
+
+		if _, ok := alertServiceState(); ok {
+			journalArgs := []string{"--user", "--unit", alertServiceName, "--lines", strconv.Itoa(lines)}
+			if follow {
+				journalArgs = append(journalArgs, "--follow")
+			}
+			journal := exec.Command("journalctl", journalArgs...)
+			journal.Stdin, journal.Stdout, journal.Stderr = os.Stdin, os.Stdout, os.Stderr
+			if err := journal.Run(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+
+		path := filepath.Join(configDir, "alert.log")
+		offset, err := printLastLines(os.Stdout, path, lines)
+		if os.IsNotExist(err) {
+			if !follow {
+				fmt.Println("No alert daemon log yet")
+				return
+			}
+		} else if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if follow {
+			if err := followLog(os.Stdout, path, offset, nil); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+	},
+}
+
+// printLastLines writes the last n lines of the file at path and returns
+// the offset following them.
+func printLastLines(w io.Writer, path string, n int) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	// A final newline ends the last line rather than starting another
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	start := end
+	for i := 0; i < n && start >= 0; i++ {
+		start = bytes.LastIndexByte(data[:start], '\n')
+	}
+	// Skip the newline before the first line printed
+	start++
+	if n <= 0 {
+		start = len(data)
+	}
+	if _, err := w.Write(data[start:]); err != nil {
+		return 0, err
+	}
+	return int64(len(data)), nil
+}
+
+// followLog writes what is appended to the file at path after offset until
+// stop is closed. When the log is rotated it finishes the old file and
+// continues with the new one from the start.
+func followLog(w io.Writer, path string, offset int64, stop <-chan struct{}) error {
+	var file *os.File
+	var info os.FileInfo
+	defer func() {
+		if file != nil {
+			_ = file.Close()
+		}
+	}()
+
+	for {
+		if file == nil {
+			opened, err := os.Open(path)
+			if err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			if err == nil {
+				if info, err = opened.Stat(); err != nil {
+					_ = opened.Close()
+					return err
+				}
+				if offset > info.Size() {
+					offset = 0
+				}
+				if _, err := opened.Seek(offset, io.SeekStart); err != nil {
+					_ = opened.Close()
+					return err
+				}
+				file = opened
+			}
+		}
+
+		if file != nil {
+			if _, err := io.Copy(w, file); err != nil {
+				return err
+			}
+			current, err := os.Stat(path)
+			switch {
+			case err != nil && !os.IsNotExist(err):
+				return err
+			case err != nil || !os.SameFile(info, current):
+				// Rotated: print the rest of the old file, then start over
+				if _, err := io.Copy(w, file); err != nil {
+					return err
+				}
+				_ = file.Close()
+				file, offset = nil, 0
+				continue
+			default:
+				if position, err := file.Seek(0, io.SeekCurrent); err == nil && current.Size() < position {
+					// Truncated in place
+					if _, err := file.Seek(0, io.SeekStart); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(logFollowInterval):
+		}
+	}
+}
+
+func init() {
+	alertLogsCmd.Flags().IntP("lines", "n", 20, "Number of lines to show")
+	alertLogsCmd.Flags().BoolP("follow", "f", false, "Keep printing new lines")
+	alertCmd.AddCommand(alertLogsCmd)
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"log/slog"
+	"sort"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
+	"github.com/spf13/cobra"
+)
+
+// metricsAddress is where the watcher serves /metrics, from --metrics-addr
+// or the config; empty when the endpoint is off.
+func metricsAddress(cmd *cobra.Command) string {
+	if flag := cmd.Flags().Lookup("metrics-addr"); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return configStore.Config.Metrics.Listen
+}
+
+// loadTrackedCoins reads the portfolio and watchlist from disk, so the
+// metrics follow changes made by other crypto commands.
+func loadTrackedCoins() (*models.Portfolio, []string, error) {
+	current, err := loadPortfolio()
+	if err != nil {
+		return nil, nil, err
+	}
+	list := models.NewWatchlist(configDir)
+	if err := list.Load(); err != nil {
+		return nil, nil, err
+	}
+	return current, list.CoinIDs, nil
+}
+
+// updateMetricsServer starts, moves or stops the /metrics listener to
+// match addr. The watcher keeps running without it when it cannot listen.
+func (w *alertWatcher) updateMetricsServer(addr string) {
+	if w.metrics != nil && w.metricsAddr == addr {
+		return
+	}
+	w.stopMetricsServer()
+	w.metricsAddr = addr
+	if addr == "" {
+		return
+	}
+	server, err := service.ListenMetrics(addr, w.writeMetrics)
+	if err != nil {
+		slog.Warn("metrics endpoint disabled", "err", err)
+		return
+	}
+	w.metrics = server
+	slog.Info("serving metrics", "url", "http://"+server.Addr()+"/metrics")
+}
+
+func (w *alertWatcher) stopMetricsServer() {
+	if w.metrics == nil {
+		return
+	}
+	if err := w.metrics.Close(); err != nil {
+		slog.Warn("closing metrics endpoint", "err", err)
+	}
+	w.metrics = nil
+}
+
+// writeMetrics writes the /metrics page. It runs on the HTTP server's
+// goroutines and only reads state guarded by locks.
+func (w *alertWatcher) writeMetrics(m *service.MetricsWriter) {
+	m.Family("crypto_build_info", service.MetricGauge, "Version of the crypto binary serving the metrics.")
+	m.Sample("crypto_build_info", 1, "version", Version)
+	m.Family("process_start_time_seconds", service.MetricGauge, "Unix time the alert watcher started.")
+	m.Sample("process_start_time_seconds", float64(w.started.Unix()))
+
+	type alertKey struct{ condition, state string }
+	counts := make(map[alertKey]int)
+	for _, alert := range alertManager.GetAlerts() {
+		state := "armed"
+		if alert.Disarmed {
+			state = "disarmed"
+		}
+		counts[alertKey{condition: alert.Condition, state: state}]++
+	}
+	keys := make([]alertKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].condition != keys[j].condition {
+			return keys[i].condition < keys[j].condition
+		}
+		return keys[i].state < keys[j].state
+	})
+	m.Family("crypto_alerts", service.MetricGauge, "Active alerts by condition, and whether they can fire.")
+	for _, key := range keys {
+		m.Sample("crypto_alerts", float64(counts[key]), "condition", key.condition, "state", key.state)
+	}
+
+	alertChecker.WriteMetrics(m)
+	service.WriteRequestMetrics(m)
+}
@@ -0,0 +1,281 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// alertServiceName is the systemd user unit that runs the alert daemon.
+const alertServiceName = "crypto-alert.service"
+
+// systemctl runs 'systemctl --user' with args and returns its combined
+// output. Tests replace it.
+var systemctl = func(args ...string) (string, error) {
+	path, err := exec.LookPath("systemctl")
+	if err != nil {
+		return "", fmt.Errorf("systemd is not available: %w", err)
+	}
+	out, err := exec.Command(path, append([]string{"--user"}, args...)...).CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			return output, fmt.Errorf("systemctl --user %s: %s", strings.Join(args, " "), output)
+		}
+		return output, fmt.Errorf("systemctl --user %s: %w", strings.Join(args, " "), err)
+	}
+	return output, nil
+}
+
+// serviceState is the state of the alert daemon's systemd unit.
+type serviceState struct {
+	ActiveState   string
+	SubState      string
+	UnitFileState string
+	MainPID       int
+}
+
+func (s serviceState) active() bool {
+	return s.ActiveState == "active" || s.ActiveState == "reloading" || s.ActiveState == "activating"
+}
+
+// alertServiceState returns the state of the alert daemon's unit, and false
+// when the unit is not installed or systemd is not available.
+func alertServiceState() (serviceState, bool) {
+	out, err := systemctl("show", alertServiceName,
+		"--property=LoadState,ActiveState,SubState,UnitFileState,MainPID")
+	if err != nil {
+		return serviceState{}, false
+	}
+	var state serviceState
+	loaded := false
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "LoadState":
+			loaded = value == "loaded"
+		case "ActiveState":
+			state.ActiveState = value
+		case "SubState":
+			state.SubState = value
+		case "UnitFileState":
+			state.UnitFileState = value
+		case "MainPID":
+			state.MainPID, _ = strconv.Atoi(value)
+		}
+	}
+	return state, loaded
+}
+
+// alertDaemon describes the running alert daemon, if any.
+type alertDaemon struct {
+	running bool
+	pid     int
+	// service is set when the daemon is managed by systemd.
+	service *serviceState
+}
+
+// findAlertDaemon looks for the daemon as the running systemd user service,
+// then through the PID file of 'crypto alert start', which may have started
+// it before the service was installed, and last as the stopped service.
+func findAlertDaemon() alertDaemon {
+	state, installed := alertServiceState()
+	if installed && state.active() {
+		return alertDaemon{running: true, pid: state.MainPID, service: &state}
+	}
+	running, pid := daemonState.IsRunning()
+	if running || !installed {
+		return alertDaemon{running: running, pid: pid}
+	}
+	return alertDaemon{pid: state.MainPID, service: &state}
+}
+
+// alertServiceUnitPath is where the user unit is installed, honoring
+// XDG_CONFIG_HOME.
+func alertServiceUnitPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "systemd", "user", alertServiceName), nil
+}
+
+// alertServiceUnit returns the unit file running executable as the alert
+// daemon. The daemon reports readiness with sd_notify and logs plain lines
+// to stdout, which systemd passes to the journal.
+func alertServiceUnit(executable string, stream bool, metricsAddr string) string {
+	execStart := systemdQuote(executable) + " alert watch --daemon"
+	if stream {
+		execStart += " --stream"
+	}
+	if metricsAddr != "" {
+		execStart += " --metrics-addr " + systemdQuote(metricsAddr)
+	}
+	return fmt.Sprintf(`[Unit]
+Description=crypto price alert daemon
+Documentation=https://github.com/mrcnserkan/crypto
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+ExecStart=%s
+Restart=on-failure
+RestartSec=10
+Environment=NO_COLOR=1
+SyslogIdentifier=crypto-alert
+
+[Install]
+WantedBy=default.target
+`, execStart)
+}
+
+// systemdQuote quotes a command line word for a unit file, where % starts
+// a specifier.
+func systemdQuote(word string) string {
+	word = strings.ReplaceAll(word, "%", "%%")
+	if !strings.ContainsAny(word, " \t\"'\\") {
+		return word
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(word) + `"`
+}
+
+var alertInstallServiceCmd = &cobra.Command{
+	Use:   "install-service",
+	Short: "Run the alert daemon as a systemd user service",
+	Long: `Install, enable and start a systemd user unit (crypto-alert.service)
+that runs the alert daemon, so it starts on login and after reboots and is
+restarted if it fails. Logs go to the journal:
+
+  journalctl --user -u crypto-alert -f
+
+Once installed, 'crypto alert start', 'stop' and 'status' manage the
+service. The service keeps running without alerts and picks up alerts as
+they are added. To keep it running while you are logged out, enable
+lingering with 'loginctl enable-linger'.
+
+--stream and --metrics-addr are written to the unit, as 'crypto alert
+start' cannot pass them to the service; run install-service again to
+change them. The metrics address can also be set in config.json.
+
+EXAMPLE:
+  crypto alert install-service
+  crypto alert install-service --stream
+  crypto alert install-service --metrics-addr 127.0.0.1:9464
+  crypto alert install-service --no-start`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if running, pid := daemonState.IsRunning(); running {
+			fmt.Printf("Error: the alert daemon is already running (PID %d); stop it with 'crypto alert stop' first\n", pid)
+			os.Exit(1)
+		}
+
+		executable, err := os.Executable()
+		if err == nil {
+			executable, err = filepath.EvalSymlinks(executable)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		unitPath, err := alertServiceUnitPath()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		stream, _ := cmd.Flags().GetBool("stream")
+		metricsAddr, _ := cmd.Flags().GetString("metrics-addr")
+		if err := os.MkdirAll(filepath.Dir(unitPath), 0o755); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.WriteFile(unitPath, []byte(alertServiceUnit(executable, stream, metricsAddr)), 0o644); err != nil {
+			fmt.Printf("Error writing unit file: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := systemctl("daemon-reload"); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if _, err := systemctl("enable", alertServiceName); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		noStart, _ := cmd.Flags().GetBool("no-start")
+		if !noStart {
+			// Restart so an already running service picks up the new unit
+			if _, err := systemctl("restart", alertServiceName); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+		fmt.Printf("\n%s Installed %s\n", titleColor("🔔"), alertServiceName)
+		fmt.Printf("Unit: %s\n", unitPath)
+		if noStart {
+			fmt.Println("The service is enabled and starts on next login, or with 'crypto alert start'.")
+		} else {
+			fmt.Println("The service is enabled and running.")
+		}
+		fmt.Println("Logs: journalctl --user -u crypto-alert -f")
+	},
+}
+
+var alertUninstallServiceCmd = &cobra.Command{
+	Use:   "uninstall-service",
+	Short: "Stop and remove the alert daemon's systemd user service",
+	Run: func(cmd *cobra.Command, args []string) {
+		unitPath, err := alertServiceUnitPath()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, installed := alertServiceState(); !installed {
+			if _, err := os.Stat(unitPath); err != nil {
+				fmt.Println("Alert service is not installed")
+				return
+			}
+		}
+
+		if _, err := systemctl("disable", "--now", alertServiceName); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error removing unit file: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := systemctl("daemon-reload"); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+
+		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+		fmt.Printf("\n%s Removed %s\n", titleColor("🔔"), alertServiceName)
+	},
+}
+
+func init() {
+	alertInstallServiceCmd.Flags().Bool("stream", false, "Check price alerts on live exchange ticks")
+	alertInstallServiceCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+	alertInstallServiceCmd.Flags().Bool("no-start", false, "Enable the service without starting it now")
+	alertCmd.AddCommand(alertInstallServiceCmd)
+	alertCmd.AddCommand(alertUninstallServiceCmd)
+}
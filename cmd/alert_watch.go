@@ -2,11 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
 	"github.com/spf13/cobra"
 )
 
@@ -16,37 +20,307 @@ var alertWatchCmd = &cobra.Command{
 	Long: `Continuously monitor active price alerts in the foreground.
 
 Checks alert conditions at the configured interval (default: 5 minutes)
-and prints terminal notifications when targets are hit.
+and prints terminal notifications when targets are hit. When the Telegram
+bot has commands enabled in the config, it answers /price, /portfolio and
+/alerts while watching.
 
 Press Ctrl+C to stop.
 
+With --stream (or "alert_stream": {"enabled": true} in config.json),
+above, below and range alerts are also checked on every tick of the
+Binance ticker stream, so short spikes are not missed between checks.
+Polling continues for the other alerts and whenever the stream is down.
+
+Changes to alerts.json and config.json, such as alerts added with
+'crypto alert add', are picked up within a few seconds.
+
+When run as the daemon, it answers 'crypto alert status', 'alert reload'
+and 'alert check-now' on the control socket ~/.crypto/alert.sock.
+
+With --metrics-addr (or "metrics": {"listen": "..."} in config.json), it
+serves Prometheus metrics on http://<addr>/metrics: prices of the alert,
+portfolio and watchlist coins, portfolio value and P&L, alert and trigger
+counts, and API request, error, retry and rate-limit counters. It then
+also prices the portfolio and watchlist on every check.
+
 EXAMPLE:
   crypto alert watch
+  crypto alert watch --stream
+  crypto alert watch --metrics-addr 127.0.0.1:9464
   crypto alert watch --currency eur`,
 	Run: func(cmd *cobra.Command, args []string) {
 		alerts := alertManager.GetAlerts()
-		if len(alerts) == 0 {
+		daemon, _ := cmd.Flags().GetBool("daemon")
+		logFile, _ := cmd.Flags().GetString("log-file")
+		// The daemon waits for alerts to be added, so a service started at
+		// login does not exit
+		if len(alerts) == 0 && !daemon {
 			fmt.Println("No active alerts to watch")
 			return
 		}
 
-		intervalMin := configStore.AlertIntervalOrDefault(5)
-		alertChecker.SetInterval(minutesToDuration(intervalMin))
+		source := models.TriggerSourceWatch
+		if daemon {
+			source = models.TriggerSourceDaemon
+		}
+		closeLog := setupLogging(daemon, logFile)
+		defer closeLog()
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+		// The watcher closes the control socket, metrics and log itself
+		handleSignalsInCommand()
+		alertChecker.SetHistory(alertHistory, source)
+		configureAlertChecker(cmd)
 
-		titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
-		fmt.Printf("\n%s Watching %d alert(s). Press Ctrl+C to stop.\n\n",
-			titleColor("🔔"), len(alerts))
+		if daemon {
+			slog.Info("alert daemon started", "alerts", len(alerts), "pid", os.Getpid(), "version", Version)
+		} else {
+			titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+			fmt.Printf("\n%s Watching %d alert(s). Press Ctrl+C to stop.\n\n",
+				titleColor("🔔"), len(alerts))
+		}
 
 		alertChecker.Start()
+		watcher := &alertWatcher{cmd: cmd, started: time.Now()}
+		watcher.bot = startTelegramBot(getCurrencyFlag(cmd), 0)
 
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-		fmt.Println("\nStopping alert watcher...")
-		alertChecker.Stop()
+		controlCalls := make(chan controlCall)
+		stopping := make(chan struct{})
+		var control *service.ControlServer
+		if daemon {
+			control = startControlServer(controlCalls, stopping)
+		}
+		watcher.updateMetricsServer(metricsAddress(cmd))
+		notifyServiceManager("READY=1")
+
+		reload := time.NewTicker(storeReloadInterval)
+		defer reload.Stop()
+		for {
+			select {
+			case <-sigChan:
+				slog.Info("stopping alert watcher")
+				notifyServiceManager("STOPPING=1")
+				close(stopping)
+				if control != nil {
+					_ = control.Close()
+				}
+				watcher.stopMetricsServer()
+				alertChecker.Stop()
+				if watcher.bot != nil {
+					watcher.bot.Stop()
+				}
+				return
+			case <-reload.C:
+				if watcher.restart(reloadStores()) {
+					notifyServiceManager("")
+				}
+			case call := <-controlCalls:
+				response, after := watcher.handleControl(call.request)
+				call.reply <- response
+				if after != nil {
+					after()
+				}
+			}
+		}
 	},
 }
 
+// alertWatcher is the state of a running 'alert watch' that changes with
+// reloads.
+type alertWatcher struct {
+	cmd     *cobra.Command
+	bot     *service.TelegramBot
+	started time.Time
+	// metrics serves /metrics on metricsAddr when it is set.
+	metrics     *service.MetricsServer
+	metricsAddr string
+}
+
+// restart applies reloaded alerts and config, and reports whether anything
+// changed. The changed alerts are checked right away.
+func (w *alertWatcher) restart(alertsChanged, configChanged bool) bool {
+	if !alertsChanged && !configChanged {
+		return false
+	}
+	if !configChanged {
+		// Each check reads the current alerts, so the loop keeps running
+		checkAlertsNow()
+		return true
+	}
+	alertChecker.Stop()
+	configureAlertChecker(w.cmd)
+	var offset int64
+	if w.bot != nil {
+		w.bot.Stop()
+		// The new bot continues after the commands already answered
+		offset = w.bot.Offset()
+	}
+	w.bot = startTelegramBot(getCurrencyFlag(w.cmd), offset)
+	w.updateMetricsServer(metricsAddress(w.cmd))
+	alertChecker.Start()
+	return true
+}
+
+// notifyServiceManager reports state to systemd when the watcher runs as a
+// Type=notify service, adding a STATUS line with the alert count.
+func notifyServiceManager(state string) {
+	status := fmt.Sprintf("STATUS=Watching %d alert(s)", len(alertManager.GetAlerts()))
+	if state != "" {
+		status = state + "\n" + status
+	}
+	if _, err := service.SdNotify(status); err != nil {
+		slog.Warn("could not notify systemd", "err", err)
+	}
+}
+
+// checkAlertsNow has the check loop check all alerts right away, starting
+// it again when it ended because the alerts ran out.
+func checkAlertsNow() {
+	if !alertChecker.CheckNow() {
+		alertChecker.Start()
+	}
+}
+
+// handleControl answers a control socket request. It runs on the watch loop
+// so it never races with reloads. Work that can outlast the client's
+// deadline, like restarting the checker, is returned as after, to run once
+// the response has been sent.
+func (w *alertWatcher) handleControl(request service.ControlRequest) (response service.ControlResponse, after func()) {
+	switch request.Command {
+	case service.ControlStatus:
+		return service.ControlResponse{OK: true, Status: &service.DaemonStatus{
+			PID:           os.Getpid(),
+			StartedAt:     w.started,
+			Alerts:        len(alertManager.GetAlerts()),
+			CheckerStatus: alertChecker.Status(),
+			Requests:      service.RequestCounts(),
+		}}, nil
+
+	case service.ControlReload:
+		alertsChanged, configChanged := reloadStores()
+		message := "alerts.json and config.json are unchanged"
+		switch {
+		case alertsChanged && configChanged:
+			message = "Reloaded alerts.json and config.json"
+		case alertsChanged:
+			message = "Reloaded alerts.json"
+		case configChanged:
+			message = "Reloaded config.json"
+		}
+		return service.ControlResponse{OK: true, Message: message}, func() {
+			if w.restart(alertsChanged, configChanged) {
+				notifyServiceManager("")
+			}
+		}
+
+	case service.ControlCheckNow:
+		alerts := len(alertManager.GetAlerts())
+		if alerts == 0 {
+			return service.ControlResponse{Error: "no active alerts to check"}, nil
+		}
+		checkAlertsNow()
+		return service.ControlResponse{OK: true, Message: fmt.Sprintf("Checking %d alert(s) now", alerts)}, nil
+	}
+	return service.ControlResponse{Error: fmt.Sprintf("unknown command %q", request.Command)}, nil
+}
+
+// controlCall passes a control request to the watch loop.
+type controlCall struct {
+	request service.ControlRequest
+	reply   chan service.ControlResponse
+}
+
+// startControlServer listens on the daemon's control socket and forwards
+// requests to calls until stopping is closed. The daemon keeps running
+// without the socket when it cannot listen.
+func startControlServer(calls chan<- controlCall, stopping <-chan struct{}) *service.ControlServer {
+	server, err := service.ListenControl(daemonState.SocketPath(), func(request service.ControlRequest) service.ControlResponse {
+		call := controlCall{request: request, reply: make(chan service.ControlResponse, 1)}
+		select {
+		case calls <- call:
+			return <-call.reply
+		case <-stopping:
+			return service.ControlResponse{Error: "the alert daemon is stopping"}
+		}
+	})
+	if err != nil {
+		slog.Warn("control socket disabled", "err", err)
+		return nil
+	}
+	return server
+}
+
+// storeReloadInterval is how often the watcher looks for changes to
+// alerts.json and config.json made by other crypto commands or by hand.
+const storeReloadInterval = 2 * time.Second
+
+// configureAlertChecker applies the config and flags to the alert checker.
+func configureAlertChecker(cmd *cobra.Command) {
+	applyLogLevel()
+	intervalMin := configStore.AlertIntervalOrDefault(5)
+	alertChecker.SetInterval(minutesToDuration(intervalMin))
+	notifiers, err := service.NewNotifiers(configStore.Config.Notifications)
+	if err != nil {
+		slog.Warn("some notifiers are misconfigured and will be skipped", "err", err)
+	}
+	alertChecker.SetNotifiers(notifiers)
+	streamCfg := configStore.Config.AlertStream
+	if stream, _ := cmd.Flags().GetBool("stream"); stream || streamCfg.Enabled {
+		alertChecker.SetStream(service.NewPriceStream(streamCfg.URL))
+	} else {
+		alertChecker.SetStream(nil)
+	}
+	if metricsAddress(cmd) != "" {
+		alertChecker.TrackPrices(getCurrencyFlag(cmd), loadTrackedCoins)
+	} else {
+		alertChecker.TrackPrices("", nil)
+	}
+}
+
+// reloadStores reloads alerts.json and config.json when they were changed
+// by another process, and reports which ones were reloaded.
+func reloadStores() (alertsChanged, configChanged bool) {
+	alertsChanged, err := alertManager.ReloadIfChanged()
+	if err != nil {
+		slog.Warn("keeping current alerts, could not reload alerts.json", "err", err)
+	} else if alertsChanged {
+		slog.Info("reloaded alerts.json", "alerts", len(alertManager.GetAlerts()))
+	}
+
+	configChanged, err = configStore.ReloadIfChanged()
+	if err != nil {
+		slog.Warn("keeping current settings, could not reload config.json", "err", err)
+	} else if configChanged {
+		slog.Info("reloaded config.json")
+	}
+	return alertsChanged, configChanged
+}
+
+// startTelegramBot starts the Telegram command bot when it is enabled in
+// the config, asking for updates from offset, and returns nil otherwise.
+func startTelegramBot(currency string, offset int64) *service.TelegramBot {
+	telegram := configStore.Config.Notifications.Telegram
+	if telegram == nil || !telegram.Commands {
+		return nil
+	}
+	bot, err := service.NewTelegramBot(*telegram, alertManager, loadPortfolio, currency)
+	if err != nil {
+		slog.Warn("telegram bot disabled", "err", err)
+		return nil
+	}
+	bot.SetOffset(offset)
+	bot.Start()
+	return bot
+}
+
 func init() {
+	alertWatchCmd.Flags().Bool("stream", false, "Check price alerts on live exchange ticks")
+	alertWatchCmd.Flags().String("metrics-addr", "", "Serve Prometheus metrics on this address, e.g. 127.0.0.1:9464")
+	// Set by 'crypto alert start' and the systemd unit so history shows
+	// which process fired
+	alertWatchCmd.Flags().Bool("daemon", false, "Run as the background daemon")
+	alertWatchCmd.Flags().String("log-file", "", "Write the daemon log to this file, rotated by size")
+	_ = alertWatchCmd.Flags().MarkHidden("daemon")
+	_ = alertWatchCmd.Flags().MarkHidden("log-file")
 	alertCmd.AddCommand(alertWatchCmd)
 }
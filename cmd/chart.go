@@ -0,0 +1,522 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+// chartRange is the interval and optional date bounds selected by chart flags.
+type chartRange struct {
+	interval    string
+	apiInterval service.Interval
+	from        *time.Time
+	to          *time.Time
+}
+
+// resolveChartRange reads --interval, --from and --to, exiting on invalid input.
+func resolveChartRange() chartRange {
+	interval, _ := rootCmd.Flags().GetString("interval")
+	fromStr, _ := rootCmd.Flags().GetString("from")
+	toStr, _ := rootCmd.Flags().GetString("to")
+
+	var fromDate, toDate *time.Time
+	if fromStr != "" {
+		t, err := utils.ParseChartDate(fromStr)
+		if err != nil {
+			fmt.Printf("Error: invalid --from date: %v\n", err)
+			os.Exit(1)
+		}
+		fromDate = &t
+	}
+	if toStr != "" {
+		t, err := utils.ParseChartDate(toStr)
+		if err != nil {
+			fmt.Printf("Error: invalid --to date: %v\n", err)
+			os.Exit(1)
+		}
+		// Include the full end day when only a date is given
+		if !strings.Contains(toStr, ":") {
+			endOfDay := t.Add(24*time.Hour - time.Second)
+			toDate = &endOfDay
+		} else {
+			toDate = &t
+		}
+	}
+
+	// When custom range is set, pick API interval that covers the span
+	apiInterval := service.SelectInterval(interval)
+	if fromDate != nil || toDate != nil {
+		rangeFrom := time.Now().AddDate(0, 0, -7)
+		rangeTo := time.Now()
+		if fromDate != nil {
+			rangeFrom = *fromDate
+		}
+		if toDate != nil {
+			rangeTo = *toDate
+		}
+		apiInterval = service.SelectIntervalForRange(rangeFrom, rangeTo)
+		interval = apiInterval.Name
+	}
+
+	return chartRange{interval: interval, apiInterval: apiInterval, from: fromDate, to: toDate}
+}
+
+// chartConfigFromFlags builds a ChartConfig from chart flags and config.json,
+// exiting on invalid input.
+func chartConfigFromFlags(currencySymbol string) utils.ChartConfig {
+	chartWidth, _ := rootCmd.Flags().GetInt("width")
+	chartHeight, _ := rootCmd.Flags().GetInt("height")
+	normalize, _ := rootCmd.Flags().GetBool("normalize")
+	logScale, _ := rootCmd.Flags().GetBool("log-scale")
+	styleStr, _ := rootCmd.Flags().GetString("chart-style")
+	indicatorsStr, _ := rootCmd.Flags().GetString("indicators")
+	indicators, err := utils.ParseIndicators(indicatorsStr)
+	if err != nil {
+		fmt.Printf("Error: invalid --indicators: %v\n", err)
+		os.Exit(1)
+	}
+	if configStore != nil {
+		if chartWidth == 80 {
+			chartWidth = configStore.ChartWidthOrDefault(80)
+		}
+		if chartHeight == 20 {
+			chartHeight = configStore.ChartHeightOrDefault(20)
+		}
+		if !rootCmd.Flags().Changed("chart-style") {
+			styleStr = configStore.ChartStyleOrDefault(styleStr)
+		}
+	}
+	style, err := utils.ParseChartStyle(styleStr)
+	if err != nil {
+		fmt.Printf("Error: invalid --chart-style: %v\n", err)
+		os.Exit(1)
+	}
+
+	return utils.ChartConfig{
+		Width:          chartWidth,
+		Height:         chartHeight,
+		CurrencySymbol: currencySymbol,
+		YTickCount:     5,
+		XTickCount:     5,
+		Normalize:      normalize,
+		Indicators:     indicators,
+		Style:          style,
+		LogScale:       logScale,
+	}
+}
+
+// Chart metrics selectable with --metric.
+const (
+	metricPrice     = "price"
+	metricMarketCap = "market_cap"
+)
+
+// resolveChartMetric validates --metric, exiting on unknown values.
+func resolveChartMetric() string {
+	metric, _ := rootCmd.Flags().GetString("metric")
+	metric = strings.ToLower(strings.TrimSpace(metric))
+	switch metric {
+	case "", metricPrice:
+		return metricPrice
+	case metricMarketCap, "marketcap", "mcap":
+		return metricMarketCap
+	default:
+		fmt.Printf("Error: invalid --metric %q (use price or market_cap)\n", metric)
+		os.Exit(1)
+		return ""
+	}
+}
+
+func metricLabel(metric string) string {
+	if metric == metricMarketCap {
+		return "Market Cap"
+	}
+	return "Price"
+}
+
+// fetchMarketSeries loads market_chart data for a coin and returns the series
+// for metric plus the 24h volume series, both filtered to the chart range.
+func fetchMarketSeries(coinID, currency string, r chartRange, metric string) ([]utils.SeriesPoint, []utils.SeriesPoint, error) {
+	chart, err := coinGecko.GetCoinMarketChart(coinID, currency, r.apiInterval.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	pairs := chart.Prices
+	if metric == metricMarketCap {
+		pairs = chart.MarketCaps
+	}
+	series := utils.FilterSeriesByDateRange(utils.PairsToSeries(pairs), r.from, r.to)
+	volume := utils.FilterSeriesByDateRange(utils.PairsToSeries(chart.TotalVolumes), r.from, r.to)
+	return series, volume, nil
+}
+
+// resolveTimeframe validates --timeframe, exiting on invalid input. It returns
+// 0 when candles should keep the API's native granularity.
+func resolveTimeframe() time.Duration {
+	timeframeStr, _ := rootCmd.Flags().GetString("timeframe")
+	timeframe, err := utils.ParseTimeframe(timeframeStr)
+	if err != nil {
+		fmt.Printf("Error: invalid --timeframe: %v\n", err)
+		os.Exit(1)
+	}
+	return timeframe
+}
+
+// fetchCandles loads candles for the chart range. With a timeframe set it
+// resamples the OHLC endpoint when its candles are fine enough, otherwise it
+// builds candles from market_chart price points.
+func fetchCandles(coinID, currency string, r chartRange, timeframe time.Duration) ([]models.OHLC, error) {
+	data, err := coinGecko.GetCoinOHLC(coinID, currency, r.apiInterval.Name)
+	if err != nil {
+		return nil, err
+	}
+	data = utils.FilterOHLCByDateRange(data, r.from, r.to)
+	if timeframe == 0 {
+		return data, nil
+	}
+	if step := utils.OHLCStep(data); step > 0 && step <= timeframe {
+		return utils.ResampleOHLC(data, timeframe), nil
+	}
+
+	chart, err := coinGecko.GetCoinMarketChartAuto(coinID, currency, r.apiInterval.Name)
+	if err != nil {
+		return nil, err
+	}
+	points := utils.FilterSeriesByDateRange(utils.PairsToSeries(chart.Prices), r.from, r.to)
+	if step := utils.SeriesStep(points); step > 0 && step <= timeframe {
+		return utils.CandlesFromSeries(points, timeframe), nil
+	}
+
+	fmt.Printf("Warning: No data finer than %s for the %s interval; candles may be coarser than requested\n",
+		utils.FormatTimeframe(timeframe), r.interval)
+	return utils.ResampleOHLC(data, timeframe), nil
+}
+
+// parseCoinIDList splits a comma-separated coin argument into unique normalized IDs.
+func parseCoinIDList(arg string) []string {
+	var coinIDs []string
+	seen := make(map[string]struct{})
+	for _, part := range strings.Split(arg, ",") {
+		id := utils.NormalizeCoinID(part)
+		if id == "" {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		coinIDs = append(coinIDs, id)
+	}
+	if len(coinIDs) == 0 {
+		coinIDs = []string{utils.NormalizeCoinID(arg)}
+	}
+	return coinIDs
+}
+
+func displayMultiPriceGraph(coinIDs []string, currency string) {
+	currency = utils.NormalizeCurrency(currency)
+	currencySymbol := utils.CurrencySymbol(currency)
+
+	if showCandles, _ := rootCmd.Flags().GetBool("candles"); showCandles {
+		fmt.Println("Error: Candlestick charts support a single coin; drop --candles or pass one coin ID")
+		os.Exit(1)
+	}
+
+	chartRange := resolveChartRange()
+	metric := resolveChartMetric()
+	chartCfg := chartConfigFromFlags(currencySymbol)
+	if len(chartCfg.Indicators) > 0 {
+		fmt.Println("Warning: --indicators applies to single-coin charts and is ignored here")
+	}
+	if showVolume, _ := rootCmd.Flags().GetBool("volume"); showVolume {
+		fmt.Println("Warning: --volume applies to single-coin charts and is ignored here")
+	}
+	if exportPath, _ := rootCmd.Flags().GetString("export"); exportPath != "" {
+		fmt.Println("Warning: --export applies to single-coin charts and is ignored here")
+	}
+	if live, _ := rootCmd.Flags().GetBool("live"); live {
+		fmt.Println("Warning: --live applies to single-coin charts and is ignored here")
+	}
+	if interactive, _ := rootCmd.Flags().GetBool("interactive"); interactive {
+		fmt.Println("Warning: --interactive applies to single-coin charts and is ignored here")
+	}
+
+	series := make([]utils.ChartSeries, 0, len(coinIDs))
+	for _, coinID := range coinIDs {
+		points, _, err := fetchMarketSeries(coinID, currency, chartRange, metric)
+		if err != nil {
+			fmt.Printf("Error fetching market chart for %s: %v\n", coinID, err)
+			os.Exit(1)
+		}
+		if len(points) == 0 {
+			fmt.Printf("Warning: No price data for %s in the selected range\n", coinID)
+			continue
+		}
+		series = append(series, utils.ChartSeries{
+			Name:   strings.ToUpper(coinID),
+			Points: points,
+			Color:  utils.SeriesPalette[len(series)%len(utils.SeriesPalette)],
+		})
+	}
+	if len(series) == 0 {
+		fmt.Println("Error: No price data available for the selected interval or date range")
+		os.Exit(1)
+	}
+
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	captionColor := color.New(color.FgHiBlue).SprintFunc()
+	labelColor := color.New(color.FgHiBlue).SprintFunc()
+
+	names := make([]string, len(series))
+	for i, s := range series {
+		names[i] = s.Name
+	}
+	mode := metricLabel(metric)
+	if chartCfg.Normalize {
+		mode = "Relative Performance"
+	}
+	fmt.Printf("\n%s %s\n\n", titleColor("📈"), titleColor(fmt.Sprintf("%s %s Chart (%s)",
+		strings.Join(names, " vs "), mode, chartRange.interval)))
+
+	for _, s := range series {
+		stats := utils.ComputePeriodStats(s.Points)
+		seriesColor := color.New(s.Color).SprintFunc()
+		fmt.Printf("%s %s\n", labelColor(fmt.Sprintf("%-10s", s.Name+":")),
+			seriesColor(utils.FormatPeriodStatsLine(stats, currencySymbol)))
+	}
+	fmt.Println()
+
+	fmt.Print(utils.RenderMultiLineChart(series, chartCfg))
+
+	legend := metricLabel(metric) + " on a shared axis"
+	if chartCfg.Normalize {
+		legend = "% change from the first point in range"
+	}
+	fmt.Println(captionColor(fmt.Sprintf("%s | Data source: coingecko.com at %s", legend, utils.GetCurrentTime())))
+}
+
+// priceGraph is a single-coin chart: the options read from flags and the
+// loaded data. Live mode keeps one and appends prices between full reloads.
+type priceGraph struct {
+	coinID         string
+	currency       string
+	currencySymbol string
+	showCandles    bool
+	showVolume     bool
+	chartRange     chartRange
+	metric         string
+	timeframe      time.Duration
+	chartCfg       utils.ChartConfig
+	exportPath     string
+
+	series []utils.SeriesPoint
+	volume []utils.SeriesPoint
+	ohlc   []models.OHLC
+}
+
+// newPriceGraph reads and validates chart flags, exiting on invalid input.
+func newPriceGraph(coinID, currency string) *priceGraph {
+	currency = utils.NormalizeCurrency(currency)
+	currencySymbol := utils.CurrencySymbol(currency)
+
+	g := &priceGraph{coinID: coinID, currency: currency, currencySymbol: currencySymbol}
+	g.showCandles, _ = rootCmd.Flags().GetBool("candles")
+	g.showVolume, _ = rootCmd.Flags().GetBool("volume")
+	g.chartRange = resolveChartRange()
+	g.metric = resolveChartMetric()
+	g.chartCfg = chartConfigFromFlags(currencySymbol)
+
+	if g.showCandles && g.metric != metricPrice {
+		fmt.Println("Error: Candlestick charts are only available for --metric price")
+		os.Exit(1)
+	}
+	g.timeframe = resolveTimeframe()
+	g.exportPath, _ = rootCmd.Flags().GetString("export")
+	if g.exportPath != "" {
+		if _, err := utils.ExportFormat(g.exportPath); err != nil {
+			fmt.Printf("Error: invalid --export: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if g.timeframe > 0 && !g.showCandles {
+		fmt.Println("Warning: --timeframe applies to candlestick charts and is ignored here")
+	}
+	return g
+}
+
+// load fetches the full series, candles and volume for the chart range.
+func (g *priceGraph) load() error {
+	var series, volume []utils.SeriesPoint
+	var ohlcData []models.OHLC
+
+	if g.showCandles {
+		data, err := fetchCandles(g.coinID, g.currency, g.chartRange, g.timeframe)
+		if err != nil {
+			return fmt.Errorf("fetching OHLC data: %v", err)
+		}
+		ohlcData = data
+		series = candleCloses(ohlcData)
+		if g.showVolume {
+			// The OHLC endpoint has no volume, so take it from market_chart
+			_, volume, err = fetchMarketSeries(g.coinID, g.currency, g.chartRange, metricPrice)
+			if err != nil {
+				return fmt.Errorf("fetching volume data: %v", err)
+			}
+		}
+	} else {
+		var err error
+		series, volume, err = fetchMarketSeries(g.coinID, g.currency, g.chartRange, g.metric)
+		if err != nil {
+			return fmt.Errorf("fetching market chart: %v", err)
+		}
+	}
+
+	if len(series) == 0 {
+		return fmt.Errorf("no price data available for the selected interval or date range")
+	}
+	g.series, g.volume, g.ohlc = series, volume, ohlcData
+	if g.showVolume {
+		g.chartCfg.Volume = volume
+	}
+	return nil
+}
+
+func candleCloses(data []models.OHLC) []utils.SeriesPoint {
+	series := make([]utils.SeriesPoint, 0, len(data))
+	for _, candle := range data {
+		series = append(series, utils.SeriesPoint{
+			Time:  time.Unix(candle.Time/1000, 0),
+			Value: candle.Close,
+		})
+	}
+	return series
+}
+
+// appendPrice adds a live price. Line charts gain a point; candle charts
+// update the newest candle or open a new one once its period has passed.
+func (g *priceGraph) appendPrice(at time.Time, price float64) {
+	if !g.showCandles {
+		g.series = append(g.series, utils.SeriesPoint{Time: at, Value: price})
+		return
+	}
+
+	step := g.timeframe
+	if step == 0 {
+		step = utils.OHLCStep(g.ohlc)
+	}
+	atMs := at.UnixMilli()
+	last := &g.ohlc[len(g.ohlc)-1]
+	if step <= 0 || atMs-last.Time < step.Milliseconds() {
+		last.High = math.Max(last.High, price)
+		last.Low = math.Min(last.Low, price)
+		last.Close = price
+	} else {
+		periods := (atMs - last.Time) / step.Milliseconds()
+		g.ohlc = append(g.ohlc, models.OHLC{
+			Time: last.Time + periods*step.Milliseconds(),
+			Open: price, High: price, Low: price, Close: price,
+		})
+	}
+	g.series = candleCloses(g.ohlc)
+}
+
+func (g *priceGraph) chartType() string {
+	chartType := "Line"
+	if g.showCandles {
+		chartType = "Candlestick"
+	}
+	if g.metric != metricPrice {
+		chartType = metricLabel(g.metric) + " " + chartType
+	}
+	return chartType
+}
+
+func (g *priceGraph) title() string {
+	title := g.chartRange.interval
+	if g.showCandles && g.timeframe > 0 {
+		title += ", " + utils.FormatTimeframe(g.timeframe) + " candles"
+	}
+	return fmt.Sprintf("%s %s Chart (%s)", strings.ToUpper(g.coinID), g.chartType(), title)
+}
+
+// render returns the chart with its title, stats and caption.
+func (g *priceGraph) render() string {
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	captionColor := color.New(color.FgHiBlue).SprintFunc()
+	labelColor := color.New(color.FgHiBlue).SprintFunc()
+	valueColor := color.New(color.FgHiWhite).SprintFunc()
+	statsColor := color.New(color.FgHiYellow).SprintFunc()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "\n%s %s\n\n", titleColor("📈"), titleColor(g.title()))
+
+	stats := utils.ComputePeriodStats(g.series)
+	fmt.Fprintln(&sb, statsColor(utils.FormatPeriodStatsLine(stats, g.currencySymbol)))
+	if summary := utils.FormatIndicatorSummary(g.series, g.chartCfg.Indicators); summary != "" {
+		fmt.Fprintln(&sb, statsColor(summary))
+	}
+
+	minPrice, maxPrice := g.series[0].Value, g.series[0].Value
+	for _, p := range g.series[1:] {
+		if p.Value < minPrice {
+			minPrice = p.Value
+		}
+		if p.Value > maxPrice {
+			maxPrice = p.Value
+		}
+	}
+	priceRange := maxPrice - minPrice
+	fmt.Fprintf(&sb, "%s %s%s - %s%s (Δ %s%s)\n",
+		labelColor(metricLabel(g.metric)+" Range:"),
+		g.currencySymbol, valueColor(utils.FormatCurrency(minPrice)),
+		g.currencySymbol, valueColor(utils.FormatCurrency(maxPrice)),
+		g.currencySymbol, valueColor(utils.FormatCurrency(priceRange)))
+
+	startTime := g.series[0].Time
+	endTime := g.series[len(g.series)-1].Time
+	fmt.Fprintf(&sb, "%s %s - %s\n\n",
+		labelColor("Time Range:"),
+		valueColor(startTime.Format("2006-01-02 15:04")),
+		valueColor(endTime.Format("2006-01-02 15:04")))
+
+	if g.showCandles {
+		sb.WriteString(utils.RenderCandleChart(g.ohlc, g.chartCfg))
+	} else {
+		sb.WriteString(utils.RenderLineChart(g.series, g.chartCfg))
+	}
+
+	legend := "█/░ = Bullish/Bearish candles"
+	if !g.showCandles {
+		legend = "● = " + strings.ToLower(metricLabel(g.metric)) + " point"
+		if g.chartCfg.Style != utils.ChartStyleASCII {
+			legend = strings.ToLower(metricLabel(g.metric)) + " line (" + g.chartCfg.Style + ")"
+		}
+	}
+	if g.chartCfg.LogScale {
+		legend += " | log scale"
+	}
+	fmt.Fprintln(&sb, captionColor(fmt.Sprintf("%s | Data source: coingecko.com at %s", legend, utils.GetCurrentTime())))
+	return sb.String()
+}
+
+// export writes the chart to exportPath.
+func (g *priceGraph) export() error {
+	export := utils.ChartExport{
+		Title:  g.title(),
+		Label:  metricLabel(g.metric),
+		Points: g.series,
+		Config: g.chartCfg,
+	}
+	if g.showCandles {
+		export.Candles = g.ohlc
+	}
+	return utils.ExportChart(g.exportPath, export)
+}
@@ -0,0 +1,458 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
+	"github.com/mrcnserkan/crypto/utils"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	// chartMinVisiblePoints bounds zooming in on the loaded data.
+	chartMinVisiblePoints = 12
+	// chartCursorJump is how far H and L move the crosshair.
+	chartCursorJump = 10
+)
+
+// chartTimeframes are the candle periods interactive charts choose from.
+var chartTimeframes = []time.Duration{
+	5 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 4 * time.Hour, 6 * time.Hour, 12 * time.Hour,
+	24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour,
+}
+
+// chartLoader fetches the metric and volume series for an interval.
+type chartLoader func(interval service.Interval) ([]utils.SeriesPoint, []utils.SeriesPoint, error)
+
+// marketChartLoader loads market_chart data at CoinGecko's automatic
+// granularity, which is finest for short intervals.
+func marketChartLoader(g *priceGraph) chartLoader {
+	return func(interval service.Interval) ([]utils.SeriesPoint, []utils.SeriesPoint, error) {
+		chart, err := coinGecko.GetCoinMarketChartAuto(g.coinID, g.currency, interval.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		pairs := chart.Prices
+		if g.metric == metricMarketCap {
+			pairs = chart.MarketCaps
+		}
+		return utils.PairsToSeries(pairs), utils.PairsToSeries(chart.TotalVolumes), nil
+	}
+}
+
+// chartView is an interactive chart. The visible window moves independently
+// of the loaded data; when it reaches past the loaded range (or zooms below
+// its resolution) a wider or finer interval is queued for loading.
+type chartView struct {
+	graph  *priceGraph
+	loader chartLoader
+
+	loaded service.Interval
+	series []utils.SeriesPoint
+	volume []utils.SeriesPoint
+
+	from, to  time.Time
+	cursor    time.Time
+	candles   bool
+	plotWidth int
+
+	pending *service.Interval
+	status  string
+}
+
+func newChartView(graph *priceGraph, loader chartLoader) *chartView {
+	return &chartView{graph: graph, loader: loader, candles: graph.showCandles, plotWidth: graph.chartCfg.Width}
+}
+
+// load replaces the loaded data with interval, keeping the visible window.
+func (v *chartView) load(interval service.Interval) error {
+	series, volume, err := v.loader(interval)
+	if err != nil {
+		return fmt.Errorf("fetching market chart: %v", err)
+	}
+	if len(series) == 0 {
+		return fmt.Errorf("no price data available for the %s interval", interval.Name)
+	}
+	v.loaded, v.series, v.volume = interval, series, volume
+	return nil
+}
+
+// loadPending loads a queued interval, reporting failures in the status line.
+func (v *chartView) loadPending() {
+	if v.pending == nil {
+		return
+	}
+	interval := *v.pending
+	v.pending = nil
+	if err := v.load(interval); err != nil {
+		v.status = "Error: " + err.Error()
+	} else {
+		v.status = ""
+	}
+	v.clampWindow()
+	v.snapCursor()
+}
+
+// reset shows the range selected by flags, or all loaded data.
+func (v *chartView) reset() {
+	v.from, v.to = v.series[0].Time, v.series[len(v.series)-1].Time
+	if r := v.graph.chartRange; r.from != nil && r.from.After(v.from) {
+		v.from = *r.from
+	}
+	if r := v.graph.chartRange; r.to != nil && r.to.Before(v.to) {
+		v.to = *r.to
+	}
+	v.cursor = v.to
+	v.clampWindow()
+	v.snapCursor()
+}
+
+func (v *chartView) span() time.Duration {
+	return v.to.Sub(v.from)
+}
+
+// minSpan is the narrowest window that still shows chartMinVisiblePoints.
+func (v *chartView) minSpan() time.Duration {
+	return utils.SeriesStep(v.series) * chartMinVisiblePoints
+}
+
+// intervalDays orders intervals by length, treating max as the longest.
+func intervalDays(interval service.Interval) int {
+	if interval.Days <= 0 {
+		return math.MaxInt32
+	}
+	return interval.Days
+}
+
+// zoom scales the window by factor around the crosshair.
+func (v *chartView) zoom(factor float64) {
+	span := time.Duration(float64(v.span()) * factor)
+	if factor < 1 && span < v.minSpan() {
+		finer := service.SelectIntervalForRange(v.cursor.Add(-span), time.Now())
+		if intervalDays(finer) >= intervalDays(v.loaded) {
+			span = v.minSpan()
+			v.status = "Maximum zoom for the loaded data"
+		} else {
+			v.pending = &finer
+		}
+	}
+	ratio := 0.5
+	if v.span() > 0 {
+		ratio = float64(v.cursor.Sub(v.from)) / float64(v.span())
+	}
+	v.from = v.cursor.Add(-time.Duration(ratio * float64(span)))
+	v.to = v.from.Add(span)
+	v.extendIfNeeded()
+}
+
+// pan shifts the window by a quarter of its width; dir is -1 or 1.
+func (v *chartView) pan(dir int) {
+	shift := v.span() / 4 * time.Duration(dir)
+	v.from, v.to = v.from.Add(shift), v.to.Add(shift)
+	v.cursor = v.cursor.Add(shift)
+	if dir > 0 && v.to.After(v.series[len(v.series)-1].Time) {
+		v.status = "Showing the latest data"
+	}
+	v.extendIfNeeded()
+}
+
+// extendIfNeeded queues a longer interval when the window starts before the
+// loaded data, then clamps the window to what can be shown.
+func (v *chartView) extendIfNeeded() {
+	if v.from.Before(v.series[0].Time) && v.pending == nil {
+		wider := service.SelectIntervalForRange(v.from, time.Now())
+		if intervalDays(wider) > intervalDays(v.loaded) {
+			v.pending = &wider
+		} else {
+			v.status = "Reached the start of the available history"
+		}
+	}
+	if v.pending != nil {
+		v.status = fmt.Sprintf("Loading %s data...", v.pending.Name)
+		return
+	}
+	v.clampWindow()
+	v.snapCursor()
+}
+
+// clampWindow keeps the window inside the loaded data without changing its
+// width unless the data is narrower.
+func (v *chartView) clampWindow() {
+	first, last := v.series[0].Time, v.series[len(v.series)-1].Time
+	span := v.span()
+	if span > last.Sub(first) {
+		v.from, v.to = first, last
+		return
+	}
+	if v.to.After(last) {
+		v.from, v.to = last.Add(-span), last
+	}
+	if v.from.Before(first) {
+		v.from, v.to = first, first.Add(span)
+	}
+}
+
+// visible returns the loaded points inside the window.
+func (v *chartView) visible() []utils.SeriesPoint {
+	from, to := v.from, v.to
+	return utils.FilterSeriesByDateRange(v.series, &from, &to)
+}
+
+// timeframe picks the shortest candle period that fits the plot width.
+func (v *chartView) timeframe(points []utils.SeriesPoint) time.Duration {
+	step := utils.SeriesStep(points)
+	maxCandles := intMax(v.plotWidth/4, 1)
+	for _, frame := range chartTimeframes {
+		if frame >= step && v.span()/frame < time.Duration(maxCandles) {
+			return frame
+		}
+	}
+	return chartTimeframes[len(chartTimeframes)-1]
+}
+
+func (v *chartView) visibleCandles() ([]models.OHLC, time.Duration) {
+	points := v.visible()
+	frame := v.timeframe(points)
+	return utils.CandlesFromSeries(points, frame), frame
+}
+
+// cursorTimes lists the times the crosshair can rest on.
+func (v *chartView) cursorTimes() []utils.SeriesPoint {
+	if !v.candles {
+		return v.visible()
+	}
+	candles, _ := v.visibleCandles()
+	times := make([]utils.SeriesPoint, len(candles))
+	for i, c := range candles {
+		times[i] = utils.SeriesPoint{Time: time.UnixMilli(c.Time)}
+	}
+	return times
+}
+
+// moveCursor moves the crosshair by steps points or candles.
+func (v *chartView) moveCursor(steps int) {
+	times := v.cursorTimes()
+	if len(times) == 0 {
+		return
+	}
+	i := clampInt(utils.NearestPointIndex(times, v.cursor)+steps, 0, len(times)-1)
+	v.cursor = times[i].Time
+}
+
+// snapCursor moves the crosshair onto the nearest visible point or candle.
+func (v *chartView) snapCursor() {
+	v.moveCursor(0)
+}
+
+func (v *chartView) toggleCandles() {
+	if v.graph.metric != metricPrice {
+		v.status = "Candles are only available for --metric price"
+		return
+	}
+	v.candles = !v.candles
+	v.snapCursor()
+}
+
+// handleKey applies a key press and reports whether to quit.
+func (v *chartView) handleKey(ev keyEvent) bool {
+	v.status = ""
+	switch {
+	case ev.code == keyCtrlC || ev.code == keyEscape || ev.code == keyRune && (ev.r == 'q' || ev.r == 'Q'):
+		return true
+	case ev.code == keyRune && (ev.r == '+' || ev.r == '='):
+		v.zoom(0.5)
+	case ev.code == keyRune && (ev.r == '-' || ev.r == '_'):
+		v.zoom(2)
+	case ev.code == keyLeft:
+		v.pan(-1)
+	case ev.code == keyRight:
+		v.pan(1)
+	case ev.code == keyRune && (ev.r == 'h' || ev.r == ','):
+		v.moveCursor(-1)
+	case ev.code == keyRune && (ev.r == 'l' || ev.r == '.'):
+		v.moveCursor(1)
+	case ev.code == keyRune && ev.r == 'H':
+		v.moveCursor(-chartCursorJump)
+	case ev.code == keyRune && ev.r == 'L':
+		v.moveCursor(chartCursorJump)
+	case ev.code == keyRune && (ev.r == 'c' || ev.r == 'C'):
+		v.toggleCandles()
+	case ev.code == keyRune && ev.r == '0':
+		v.reset()
+	}
+	return false
+}
+
+func clampInt(v, minV, maxV int) int {
+	return intMax(minV, intMin(v, maxV))
+}
+
+// formatExactPrice prints a value with grouping and enough decimals for
+// sub-unit prices.
+func formatExactPrice(symbol string, value float64) string {
+	decimals := 2
+	if math.Abs(value) < 1 {
+		decimals = 6
+	}
+	return symbol + message.NewPrinter(language.English).Sprintf("%.*f", decimals, value)
+}
+
+// crosshairLine describes the point or candle under the crosshair.
+func (v *chartView) crosshairLine() string {
+	labelColor := color.New(color.FgHiBlue).SprintFunc()
+	valueColor := color.New(color.FgHiWhite).SprintFunc()
+	symbol := v.graph.currencySymbol
+
+	if v.candles {
+		candles, frame := v.visibleCandles()
+		i := utils.CandleIndexAt(candles, v.cursor)
+		if i < 0 {
+			return ""
+		}
+		c := candles[i]
+		change := 0.0
+		if c.Open != 0 {
+			change = (c.Close - c.Open) / c.Open * 100
+		}
+		return fmt.Sprintf(" %s %s  %s %s  %s %s  %s %s  %s %s  %s",
+			valueColor(time.UnixMilli(c.Time).Format("2006-01-02 15:04")), labelColor("("+utils.FormatTimeframe(frame)+")"),
+			labelColor("O"), valueColor(formatExactPrice(symbol, c.Open)),
+			labelColor("H"), valueColor(formatExactPrice(symbol, c.High)),
+			labelColor("L"), valueColor(formatExactPrice(symbol, c.Low)),
+			labelColor("C"), valueColor(formatExactPrice(symbol, c.Close)),
+			coloredChange(change))
+	}
+
+	points := v.visible()
+	i := utils.NearestPointIndex(points, v.cursor)
+	if i < 0 {
+		return ""
+	}
+	p := points[i]
+	change := 0.0
+	if points[0].Value != 0 {
+		change = (p.Value - points[0].Value) / points[0].Value * 100
+	}
+	return fmt.Sprintf(" %s  %s %s  %s",
+		valueColor(p.Time.Format("2006-01-02 15:04")),
+		labelColor(metricLabel(v.graph.metric)), valueColor(formatExactPrice(symbol, p.Value)),
+		coloredChange(change))
+}
+
+// renderChart draws the visible window at the given plot size.
+func (v *chartView) renderChart(cfg utils.ChartConfig) string {
+	cfg.Crosshair = v.cursor
+	if v.graph.showVolume {
+		from, to := v.from, v.to
+		cfg.Volume = utils.FilterSeriesByDateRange(v.volume, &from, &to)
+	}
+	if v.candles {
+		candles, _ := v.visibleCandles()
+		return utils.RenderCandleChart(candles, cfg)
+	}
+	return utils.RenderLineChart(v.visible(), cfg)
+}
+
+// render returns exactly height lines of width cells: title, crosshair
+// details, the chart sized to fit, status and key help.
+func (v *chartView) render(width, height int) string {
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	chartType := "Line"
+	if v.candles {
+		chartType = "Candlestick"
+	}
+	title := fmt.Sprintf(" %s %s %s | %s - %s", strings.ToUpper(v.graph.coinID), metricLabel(v.graph.metric), chartType,
+		v.from.Format("2006-01-02 15:04"), v.to.Format("2006-01-02 15:04"))
+
+	// Fit the plot to the screen, then correct for the axis labels and
+	// sub-panels once their size is known
+	cfg := v.graph.chartCfg
+	cfg.Width = width - 14
+	cfg.Height = height - 6
+	var chart []string
+	for attempt := 0; attempt < 3; attempt++ {
+		v.plotWidth = cfg.Width
+		chart = strings.Split(strings.TrimRight(v.renderChart(cfg), "\n"), "\n")
+		widest := 0
+		for _, line := range chart {
+			widest = intMax(widest, visibleWidth(line))
+		}
+		extraRows := len(chart) - (height - 4)
+		if widest == width && extraRows <= 0 {
+			break
+		}
+		cfg.Width += width - widest
+		if extraRows > 0 {
+			cfg.Height -= extraRows
+		}
+	}
+
+	status := v.status
+	if status == "" {
+		status = fmt.Sprintf("Loaded %s (%d points)", v.loaded.Name, len(v.series))
+	}
+	lines := []string{titleColor(title), v.crosshairLine()}
+	lines = append(lines, chart...)
+	for len(lines) < height-2 {
+		lines = append(lines, "")
+	}
+	lines = append(lines[:height-2], " "+status,
+		" ←→ pan  +/- zoom  h/l crosshair (H/L x10)  c line/candles  0 reset  q quit")
+	for i, line := range lines {
+		lines[i] = fitLine(line, width)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runInteractiveChart loads the chart and takes over the terminal until the
+// user quits.
+func runInteractiveChart(graph *priceGraph) error {
+	view := newChartView(graph, marketChartLoader(graph))
+	if err := view.load(graph.chartRange.apiInterval); err != nil {
+		return err
+	}
+	view.reset()
+
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.restore()
+
+	keys := make(chan keyEvent, 16)
+	go term.readKeys(keys)
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	draw := func() {
+		width, height := term.size()
+		fmt.Print(ansiCursorHome + view.render(width, height))
+	}
+	draw()
+	for {
+		select {
+		case ev, ok := <-keys:
+			if !ok || view.handleKey(ev) {
+				return nil
+			}
+		case <-resize:
+			fmt.Print(ansiClearScreen)
+		}
+		draw()
+		if view.pending != nil {
+			view.loadPending()
+			draw()
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/service"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+// fakeChartLoader returns hourly points covering the interval up to now and
+// records which intervals were requested.
+func fakeChartLoader(requested *[]string) chartLoader {
+	return func(interval service.Interval) ([]utils.SeriesPoint, []utils.SeriesPoint, error) {
+		*requested = append(*requested, interval.Name)
+		days := interval.Days
+		if days <= 0 {
+			days = 1000
+		}
+		step := time.Hour
+		if days == 1 {
+			step = 5 * time.Minute
+		}
+		end := time.Now().Truncate(step)
+		n := int(time.Duration(days) * 24 * time.Hour / step)
+		points := make([]utils.SeriesPoint, n)
+		for i := range points {
+			points[i] = utils.SeriesPoint{Time: end.Add(-time.Duration(n-1-i) * step), Value: 100 + float64(i%50)}
+		}
+		return points, nil, nil
+	}
+}
+
+func newTestChartView(t *testing.T) (*chartView, *[]string) {
+	var requested []string
+	graph := &priceGraph{
+		coinID:         "bitcoin",
+		currencySymbol: "$",
+		metric:         metricPrice,
+		chartRange:     chartRange{interval: "7d", apiInterval: service.SelectInterval("7d")},
+		chartCfg:       utils.ChartConfig{Width: 80, Height: 20, CurrencySymbol: "$", YTickCount: 5, XTickCount: 5},
+	}
+	view := newChartView(graph, fakeChartLoader(&requested))
+	if err := view.load(graph.chartRange.apiInterval); err != nil {
+		t.Fatal(err)
+	}
+	view.reset()
+	return view, &requested
+}
+
+func TestChartViewZoomAndPan(t *testing.T) {
+	view, _ := newTestChartView(t)
+	full := view.span()
+
+	view.handleKey(keyEvent{code: keyRune, r: '+'})
+	if view.span() != full/2 {
+		t.Fatalf("zoom in span = %s, want %s", view.span(), full/2)
+	}
+	if !view.to.Equal(view.series[len(view.series)-1].Time) {
+		t.Fatal("zooming around the newest point should keep the window end")
+	}
+
+	to := view.to
+	view.handleKey(keyEvent{code: keyLeft})
+	if got := to.Sub(view.to); got != view.span()/4 {
+		t.Fatalf("pan moved %s, want %s", got, view.span()/4)
+	}
+	view.handleKey(keyEvent{code: keyRight})
+	view.handleKey(keyEvent{code: keyRight})
+	if !view.to.Equal(to) {
+		t.Fatal("panning right should stop at the newest data")
+	}
+}
+
+func TestChartViewRefetchesBeyondLoadedData(t *testing.T) {
+	view, requested := newTestChartView(t)
+
+	view.handleKey(keyEvent{code: keyRune, r: '-'})
+	if view.pending == nil || view.pending.Name != "14d" {
+		t.Fatalf("zoom out should queue a wider interval, got %+v", view.pending)
+	}
+	view.loadPending()
+	if got := strings.Join(*requested, ","); got != "7d,14d" {
+		t.Fatalf("requested %s", got)
+	}
+	if view.from.Before(view.series[0].Time) {
+		t.Fatal("window starts before loaded data")
+	}
+
+	// Zooming in far enough asks for finer data
+	for i := 0; i < 6 && view.pending == nil; i++ {
+		view.handleKey(keyEvent{code: keyRune, r: '+'})
+	}
+	if view.pending == nil || view.pending.Name != "1d" {
+		t.Fatalf("deep zoom should queue the 1d interval, got %+v", view.pending)
+	}
+	view.loadPending()
+	if step := utils.SeriesStep(view.visible()); step != 5*time.Minute {
+		t.Fatalf("step after refetch = %s", step)
+	}
+}
+
+func TestChartViewCrosshairAndCandles(t *testing.T) {
+	view, _ := newTestChartView(t)
+	last := view.series[len(view.series)-1]
+	if !view.cursor.Equal(last.Time) {
+		t.Fatal("crosshair should start on the newest point")
+	}
+	view.handleKey(keyEvent{code: keyRune, r: 'h'})
+	if want := view.series[len(view.series)-2].Time; !view.cursor.Equal(want) {
+		t.Fatalf("cursor = %s, want %s", view.cursor, want)
+	}
+	if line := stripANSI(view.crosshairLine()); !strings.Contains(line, view.cursor.Format("2006-01-02 15:04")) || !strings.Contains(line, "Price $") {
+		t.Fatalf("crosshair line = %q", line)
+	}
+
+	view.handleKey(keyEvent{code: keyRune, r: 'c'})
+	if !view.candles {
+		t.Fatal("c should switch to candles")
+	}
+	screen := stripANSI(view.render(120, 40))
+	if lines := strings.Split(screen, "\n"); len(lines) != 40 {
+		t.Fatalf("render returned %d lines", len(lines))
+	}
+	for _, want := range []string{"Candlestick", " O $", " C $", "┊"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("screen missing %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestFormatExactPrice(t *testing.T) {
+	if got := formatExactPrice("$", 64123.456); got != "$64,123.46" {
+		t.Fatalf("got %q", got)
+	}
+	if got := formatExactPrice("$", 0.00012345); got != "$0.000123" {
+		t.Fatalf("got %q", got)
+	}
+}
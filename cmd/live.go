@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/service"
+)
+
+const (
+	defaultLiveRefresh = 30 * time.Second
+	// liveFullReloadEvery is the number of live refreshes between full chart
+	// reloads; refreshes in between only fetch the current price.
+	liveFullReloadEvery = 10
+)
+
+// ANSI sequences used to redraw live views in place.
+const (
+	ansiClearScreen = "\033[2J"
+	ansiCursorHome  = "\033[H"
+	ansiClearLine   = "\033[K"
+	ansiClearBelow  = "\033[J"
+)
+
+// liveOptions holds the --live and --refresh flags.
+type liveOptions struct {
+	enabled bool
+	refresh time.Duration
+}
+
+// resolveLiveOptions validates --live and --refresh, exiting on invalid input.
+// The refresh interval is raised to the API rate limit when set below it.
+func resolveLiveOptions() liveOptions {
+	enabled, _ := rootCmd.Flags().GetBool("live")
+	if !enabled {
+		return liveOptions{}
+	}
+	if toStr, _ := rootCmd.Flags().GetString("to"); toStr != "" {
+		fmt.Println("Error: --live cannot be combined with --to")
+		os.Exit(1)
+	}
+	refresh, _ := rootCmd.Flags().GetDuration("refresh")
+	if refresh <= 0 {
+		fmt.Println("Error: --refresh must be positive")
+		os.Exit(1)
+	}
+	if minRefresh := service.RateLimitInterval(); refresh < minRefresh {
+		fmt.Printf("Warning: --refresh raised to %s to respect the API rate limit\n", minRefresh)
+		refresh = minRefresh
+	}
+	return liveOptions{enabled: true, refresh: refresh}
+}
+
+// liveScreen redraws frames over the previous one instead of scrolling.
+type liveScreen struct {
+	out     io.Writer
+	started bool
+}
+
+// draw moves the cursor home, clears each line as it is rewritten and erases
+// whatever the previous, possibly taller, frame left below.
+func (s *liveScreen) draw(frame string) {
+	var sb strings.Builder
+	if !s.started {
+		sb.WriteString(ansiClearScreen)
+		s.started = true
+	}
+	sb.WriteString(ansiCursorHome)
+	sb.WriteString(strings.ReplaceAll(frame, "\n", ansiClearLine+"\n"))
+	sb.WriteString(ansiClearBelow)
+	_, _ = io.WriteString(s.out, sb.String())
+}
+
+// runLive draws render every refresh until the process is interrupted. update
+// runs before each redraw after the first; its error is shown in the status
+// line and the previous data stays on screen.
+func runLive(opts liveOptions, update func(tick int) error, render func() string) {
+	screen := &liveScreen{out: os.Stdout}
+	ticker := time.NewTicker(opts.refresh)
+	defer ticker.Stop()
+
+	var lastErr error
+	for tick := 0; ; tick++ {
+		if tick > 0 {
+			<-ticker.C
+			lastErr = update(tick)
+		}
+		screen.draw(render() + liveStatusLine(opts, lastErr))
+	}
+}
+
+func liveStatusLine(opts liveOptions, err error) string {
+	liveColor := color.New(color.FgHiGreen, color.Bold).SprintFunc()
+	captionColor := color.New(color.FgHiBlue).SprintFunc()
+	status := fmt.Sprintf("%s %s\n", liveColor("● LIVE"),
+		captionColor(fmt.Sprintf("refreshing every %s | updated %s | Ctrl+C to exit",
+			opts.refresh, time.Now().Format("15:04:05"))))
+	if err != nil {
+		status += color.New(color.FgHiRed).Sprintf("Last refresh failed: %v\n", err)
+	}
+	return status
+}
+
+// runLiveGraph keeps a loaded chart on screen, appending the current price on
+// each refresh and reloading the full series every liveFullReloadEvery ticks.
+// Market cap charts have no cheap incremental source, so they always reload.
+func runLiveGraph(graph *priceGraph, opts liveOptions) {
+	update := func(tick int) error {
+		if tick%liveFullReloadEvery == 0 || graph.metric != metricPrice {
+			return graph.load()
+		}
+		prices, err := coinGecko.GetSimplePrices([]string{graph.coinID}, graph.currency)
+		if err != nil {
+			return err
+		}
+		price, ok := prices[graph.coinID]
+		if !ok {
+			return fmt.Errorf("no price returned for %s", graph.coinID)
+		}
+		graph.appendPrice(time.Now(), price)
+		return nil
+	}
+	runLive(opts, update, graph.render)
+}
+
+// runLiveMarketList refreshes the market table in place.
+func runLiveMarketList(currency, currencySymbol string, perPage, page int, opts liveOptions) {
+	coins, err := coinGecko.GetMarkets(currency, perPage, page)
+	if err != nil {
+		fmt.Printf("Error fetching market data: %v\n", err)
+		os.Exit(1)
+	}
+
+	update := func(int) error {
+		latest, err := coinGecko.GetMarkets(currency, perPage, page)
+		if err != nil {
+			return err
+		}
+		coins = latest
+		return nil
+	}
+	runLive(opts, update, func() string { return renderMarketList(coins, currencySymbol) })
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+func TestLiveScreenRedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	screen := &liveScreen{out: &out}
+
+	screen.draw("a\nb\n")
+	first := out.String()
+	if !strings.HasPrefix(first, ansiClearScreen+ansiCursorHome) {
+		t.Fatalf("first frame should clear the screen: %q", first)
+	}
+	if !strings.Contains(first, "a"+ansiClearLine+"\n") || !strings.HasSuffix(first, ansiClearBelow) {
+		t.Fatalf("unexpected frame: %q", first)
+	}
+
+	out.Reset()
+	screen.draw("c\n")
+	if strings.Contains(out.String(), ansiClearScreen) || !strings.HasPrefix(out.String(), ansiCursorHome) {
+		t.Fatalf("later frames should only move the cursor home: %q", out.String())
+	}
+}
+
+func TestPriceGraphAppendPriceLine(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	graph := &priceGraph{series: []utils.SeriesPoint{{Time: base, Value: 100}}}
+	graph.appendPrice(base.Add(time.Minute), 101)
+	if len(graph.series) != 2 || graph.series[1].Value != 101 {
+		t.Fatalf("series = %+v", graph.series)
+	}
+}
+
+func TestPriceGraphAppendPriceCandles(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	graph := &priceGraph{
+		showCandles: true,
+		timeframe:   time.Hour,
+		ohlc:        []models.OHLC{{Time: base.UnixMilli(), Open: 100, High: 105, Low: 95, Close: 102}},
+	}
+
+	graph.appendPrice(base.Add(30*time.Minute), 110)
+	if len(graph.ohlc) != 1 || graph.ohlc[0].High != 110 || graph.ohlc[0].Close != 110 {
+		t.Fatalf("in-period price should update the candle: %+v", graph.ohlc)
+	}
+
+	graph.appendPrice(base.Add(150*time.Minute), 90)
+	if len(graph.ohlc) != 2 {
+		t.Fatalf("expected a new candle: %+v", graph.ohlc)
+	}
+	if got := time.UnixMilli(graph.ohlc[1].Time).UTC(); !got.Equal(base.Add(2 * time.Hour)) {
+		t.Fatalf("new candle starts %v, want %v", got, base.Add(2*time.Hour))
+	}
+	if last := graph.series[len(graph.series)-1]; last.Value != 90 {
+		t.Fatalf("close series not updated: %+v", graph.series)
+	}
+}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// logLevel is the level of the default logger. Config reloads change it
+// without replacing the handler.
+var logLevel = new(slog.LevelVar)
+
+func parseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %q (use debug, info, warn or error)", level)
+}
+
+// applyLogLevel sets the log level from the config.
+func applyLogLevel() {
+	level, err := parseLogLevel(configStore.Config.Log.Level)
+	logLevel.Set(level)
+	if err != nil {
+		slog.Warn("using log level info", "err", err)
+	}
+}
+
+// setupLogging installs the default logger for 'alert watch'. The
+// foreground watcher logs readable lines to the terminal. The daemon logs
+// text or JSON records to logFile, rotated by size, or to stdout when it
+// has no log file, as under systemd. The returned function closes the log.
+func setupLogging(daemon bool, logFile string) func() {
+	cfg := configStore.Config.Log
+	closeLog := func() {}
+	var handler slog.Handler
+	var formatErr error
+	if !daemon {
+		handler = newConsoleHandler(os.Stdout, logLevel)
+	} else {
+		var out io.Writer = os.Stdout
+		if logFile != "" {
+			file, err := openRotatingFile(logFile, cfg.MaxSizeOrDefault(), cfg.MaxFilesOrDefault())
+			if err != nil {
+				fmt.Printf("Warning: logging to stdout, could not open %s: %v\n", logFile, err)
+			} else {
+				out = file
+				closeLog = func() { _ = file.Close() }
+			}
+		}
+
+		opts := &slog.HandlerOptions{Level: logLevel}
+		if os.Getenv("JOURNAL_STREAM") != "" {
+			// The journal timestamps each line itself
+			opts.ReplaceAttr = func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && a.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return a
+			}
+		}
+		switch cfg.FormatOrDefault() {
+		case models.LogFormatJSON:
+			handler = slog.NewJSONHandler(out, opts)
+		case models.LogFormatText:
+			handler = slog.NewTextHandler(out, opts)
+		default:
+			handler = slog.NewTextHandler(out, opts)
+			formatErr = fmt.Errorf("invalid log format %q (use text or json)", cfg.Format)
+		}
+	}
+
+	slog.SetDefault(slog.New(handler))
+	applyLogLevel()
+	if formatErr != nil {
+		slog.Warn("using log format text", "err", formatErr)
+	}
+	return closeLog
+}
+
+// consoleHandler formats records as plain lines for the terminal, like
+// "Error: fetching prices for alerts: API error: 429 currency=usd".
+type consoleHandler struct {
+	mu    *sync.Mutex
+	out   io.Writer
+	level slog.Leveler
+	attrs []slog.Attr
+	group string
+}
+
+func newConsoleHandler(out io.Writer, level slog.Leveler) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, out: out, level: level}
+}
+
+func (h *consoleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	switch {
+	case r.Level >= slog.LevelError:
+		b.WriteString("Error: ")
+	case r.Level >= slog.LevelWarn:
+		b.WriteString("Warning: ")
+	case r.Level < slog.LevelInfo:
+		b.WriteString("Debug: ")
+	}
+	b.WriteString(r.Message)
+
+	var rest []string
+	add := func(a slog.Attr) bool {
+		key := a.Key
+		if h.group != "" {
+			key = h.group + "." + key
+		}
+		value := a.Value.Resolve().String()
+		if key == "err" {
+			b.WriteString(": " + value)
+			return true
+		}
+		if strings.ContainsAny(value, " =\"") || value == "" {
+			value = strconv.Quote(value)
+		}
+		rest = append(rest, key+"="+value)
+		return true
+	}
+	for _, a := range h.attrs {
+		add(a)
+	}
+	r.Attrs(add)
+	if len(rest) > 0 {
+		b.WriteString(" " + strings.Join(rest, " "))
+	}
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	if clone.group != "" {
+		name = clone.group + "." + name
+	}
+	clone.group = name
+	return &clone
+}
+
+// rotatingFile appends to a log file and rotates it once it would grow past
+// maxSize: path becomes path.1, path.1 becomes path.2 and so on, keeping
+// maxFiles old logs.
+type rotatingFile struct {
+	mu       sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+	// moved is set when file was rotated away but a new file could not be
+	// opened at path; writes go to the old file until one can.
+	moved bool
+}
+
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open switches to the file at path, closing the current one only once the
+// new one is open.
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if r.file != nil {
+		_ = r.file.Close()
+	}
+	r.file = file
+	r.size = info.Size()
+	r.moved = false
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.moved {
+		// Retry opening the file the last rotation could not
+		_ = r.open()
+	} else if r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			// Keep logging to the current file rather than losing records
+			fmt.Fprintf(os.Stderr, "Warning: could not rotate %s: %v\n", r.path, err)
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate renames the logs and opens a new file at path. The current file
+// stays open through the renames, so a failure leaves it to write to.
+func (r *rotatingFile) rotate() error {
+	_ = os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.%d", r.path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.%d", r.path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	r.moved = true
+	return r.open()
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// useTestLogger sends the default logger to w through the console handler
+// for the rest of the test.
+func useTestLogger(t *testing.T, w io.Writer) {
+	t.Helper()
+	original := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(original) })
+	slog.SetDefault(slog.New(newConsoleHandler(w, slog.LevelDebug)))
+}
+
+func TestConsoleHandler(t *testing.T) {
+	var buf bytes.Buffer
+	level := new(slog.LevelVar)
+	logger := slog.New(newConsoleHandler(&buf, level))
+
+	logger.Info("reloaded alerts.json", "alerts", 2)
+	logger.Error("checking alert", "coin", "bitcoin", "err", errors.New("API error: 429"), "window", "24 h")
+	logger.With("notifier", "telegram").Warn("slow delivery")
+	logger.WithGroup("stream").Info("connected", "pairs", 3)
+	logger.Debug("hidden at info")
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown at debug")
+
+	want := "reloaded alerts.json alerts=2\n" +
+		"Error: checking alert: API error: 429 coin=bitcoin window=\"24 h\"\n" +
+		"Warning: slow delivery notifier=telegram\n" +
+		"connected stream.pairs=3\n" +
+		"Debug: shown at debug\n"
+	if buf.String() != want {
+		t.Fatalf("console output =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	for input, want := range map[string]slog.Level{"": slog.LevelInfo, "DEBUG": slog.LevelDebug, "warning": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := parseLogLevel(input); err != nil || got != want {
+			t.Fatalf("parseLogLevel(%q) = %v, %v", input, got, err)
+		}
+	}
+	if _, err := parseLogLevel("verbose"); err == nil {
+		t.Fatal("expected an invalid level to fail")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.log")
+	file, err := openRotatingFile(path, 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"first line\n", "second line\n", "third line\n", "fourth line\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = file.Close()
+
+	// Each line pushes the previous one out; only two old logs are kept
+	for name, want := range map[string]string{"": "fourth line\n", ".1": "third line\n", ".2": "second line\n"} {
+		data, err := os.ReadFile(path + name)
+		if err != nil || string(data) != want {
+			t.Fatalf("alert.log%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected no third rotated log, got %v", err)
+	}
+
+	// Reopening continues with the existing size
+	file, err = openRotatingFile(path, 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	_, _ = file.Write([]byte("fifth line\n"))
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "fourth line\n" {
+		t.Fatalf("alert.log.1 after reopening = %q", data)
+	}
+}
+
+func TestRotatingFileKeepsWritingWhenReopenFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.log")
+	file, err := openRotatingFile(path, 1<<20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	_, _ = file.Write([]byte("first line\n"))
+
+	// A rotation that moved the file away but could not open a new one
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	file.moved = true
+	if _, err := file.Write([]byte("second line\n")); err != nil {
+		t.Fatalf("writing while the log cannot be opened: %v", err)
+	}
+	if data, _ := os.ReadFile(path + ".1"); string(data) != "first line\nsecond line\n" {
+		t.Fatalf("alert.log.1 = %q", data)
+	}
+
+	// The next write opens the log once it can
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.Write([]byte("third line\n"))
+	if data, _ := os.ReadFile(path); string(data) != "third line\n" {
+		t.Fatalf("alert.log = %q", data)
+	}
+}
+
+func TestSetupLoggingDaemonJSON(t *testing.T) {
+	setupTestEnv(t)
+	original := slog.Default()
+	t.Cleanup(func() { slog.SetDefault(original) })
+	t.Setenv("JOURNAL_STREAM", "")
+	configStore.Config.Log.Format = "json"
+	configStore.Config.Log.Level = "warn"
+
+	path := filepath.Join(configDir, "alert.log")
+	closeLog := setupLogging(true, path)
+	slog.Info("not logged at warn")
+	slog.Warn("price stream unavailable", "err", errors.New("dial tcp: timeout"))
+	closeLog()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(data), "\n") != 1 ||
+		!strings.Contains(string(data), `"level":"WARN","msg":"price stream unavailable","err":"dial tcp: timeout"`) {
+		t.Fatalf("alert.log = %s", data)
+	}
+}
+
+func TestPrintLastLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.log")
+	_ = os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o600)
+
+	for n, want := range map[int]string{0: "", 2: "two\nthree\n", 5: "one\ntwo\nthree\n"} {
+		var buf bytes.Buffer
+		offset, err := printLastLines(&buf, path, n)
+		if err != nil || buf.String() != want || offset != 14 {
+			t.Fatalf("printLastLines(%d) = %q, %d, %v", n, buf.String(), offset, err)
+		}
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for a writer and a reader goroutine.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestFollowLogAcrossRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.log")
+	_ = os.WriteFile(path, []byte("old\n"), 0o600)
+	log, err := openRotatingFile(path, 30, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+
+	var out syncBuffer
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() { done <- followLog(&out, path, 4, stop) }()
+
+	waitFor := func(want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for out.String() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("followed %q, want %q", out.String(), want)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	}
+	_, _ = log.Write([]byte("appended\n"))
+	waitFor("appended\n")
+	// Rotates: the line goes to a new alert.log
+	_, _ = log.Write([]byte("after rotation, a long line\n"))
+	waitFor("appended\nafter rotation, a long line\n")
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
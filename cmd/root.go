@@ -6,13 +6,14 @@ Copyright © 2022 Serkan MERCAN <serkanmercan@email.com>
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"strings"
+	"sync"
 	"syscall"
-	"time"
 
 	"github.com/fatih/color"
 	"github.com/mrcnserkan/crypto/models"
@@ -27,6 +28,7 @@ import (
 var (
 	portfolio    *models.Portfolio
 	alertManager *models.AlertManager
+	alertHistory *models.AlertHistory
 	alertChecker *service.AlertChecker
 	coinGecko    *service.CoinGecko
 	configStore  *models.ConfigStore
@@ -54,6 +56,7 @@ MAIN FEATURES:
   • Portfolio management with transaction history
   • Price alerts with notifications
   • Interactive price charts
+  • Full-screen terminal dashboard (crypto tui)
   • Multi-currency support (USD, EUR, TRY, etc.)
 
 EXAMPLES:
@@ -61,6 +64,7 @@ EXAMPLES:
      crypto                          # Default: page 1, 10 results
      crypto --page 2                 # View next page
      crypto --per-page 20           # Show 20 results per page
+     crypto --live                   # Refresh the list in place
      crypto --currency eur          # Show prices in EUR
 
   2. Get detailed coin information:
@@ -71,9 +75,18 @@ EXAMPLES:
   3. View price charts:
      crypto bitcoin --graph                    # Line chart (7 days)
      crypto bitcoin --graph --candles          # Candlestick chart
+     crypto bitcoin --graph --interactive      # Zoom, pan and crosshair with the keyboard
+     crypto bitcoin --graph --candles --interval 30d --timeframe 1d
      crypto bitcoin --graph --interval 30d   # 30-day chart
      crypto bitcoin --graph --from 2026-06-01 --to 2026-06-30
      crypto bitcoin --graph --width 100 --height 24
+     crypto bitcoin,ethereum,solana --graph --normalize
+     crypto bitcoin --graph --indicators sma:20,bb:20,rsi:14,macd
+     crypto bitcoin --graph --volume --metric market_cap
+     crypto bitcoin --graph --chart-style braille
+     crypto bitcoin --graph --interval max --log-scale
+     crypto bitcoin --graph --indicators rsi --export chart.png
+     crypto bitcoin --graph --live --refresh 20s
 
   4. Manage portfolio:
      crypto portfolio add bitcoin 0.5 50000 buy   # Add transaction
@@ -101,7 +114,13 @@ Use "crypto [command] --help" for more information about a command.`,
 				showGraph, _ := cmd.Flags().GetBool("graph")
 
 				if showGraph {
-					displayPriceGraph(utils.NormalizeCoinID(args[0]), currency)
+					coinIDs := parseCoinIDList(args[0])
+					normalize, _ := cmd.Flags().GetBool("normalize")
+					if len(coinIDs) > 1 || normalize {
+						displayMultiPriceGraph(coinIDs, currency)
+					} else {
+						displayPriceGraph(coinIDs[0], currency)
+					}
 				} else {
 					coinDetail, err := coinGecko.GetCoinDetail(utils.NormalizeCoinID(args[0]))
 					if err != nil || coinDetail.ID == "" {
@@ -132,6 +151,17 @@ Use "crypto [command] --help" for more information about a command.`,
 	rootCmd.PersistentFlags().Bool("graph", false, "Display price chart for the specified coin")
 	rootCmd.PersistentFlags().String("interval", "7d", "Chart time interval (1d, 7d, 14d, 30d, 90d, 180d, 1y, max)")
 	rootCmd.PersistentFlags().Bool("candles", false, "Display candlestick chart instead of line chart")
+	rootCmd.PersistentFlags().String("timeframe", "", "Candle timeframe, e.g. 1h, 4h, 1d or 1w (default: API granularity)")
+	rootCmd.PersistentFlags().Bool("normalize", false, "Plot % change from start instead of price (useful with several coins)")
+	rootCmd.PersistentFlags().String("indicators", "", "Chart indicators, e.g. sma:20,ema:50,bb:20,rsi:14,macd")
+	rootCmd.PersistentFlags().String("chart-style", utils.ChartStyleASCII, "Line chart rendering: braille, block or ascii")
+	rootCmd.PersistentFlags().Bool("log-scale", false, "Use a logarithmic price axis for line and candle charts")
+	rootCmd.PersistentFlags().Bool("volume", false, "Show a 24h volume histogram under the chart")
+	rootCmd.PersistentFlags().String("metric", metricPrice, "Chart metric: price or market_cap")
+	rootCmd.PersistentFlags().String("export", "", "Also write the chart to an image file (.svg or .png)")
+	rootCmd.PersistentFlags().Bool("live", false, "Keep the chart or market list open and refresh it in place")
+	rootCmd.PersistentFlags().Duration("refresh", defaultLiveRefresh, "Refresh interval for --live")
+	rootCmd.PersistentFlags().Bool("interactive", false, "Open the chart full-screen with keyboard zoom, pan and crosshair")
 	rootCmd.PersistentFlags().String("from", "", "Chart start date (YYYY-MM-DD or YYYY-MM-DD HH:MM)")
 	rootCmd.PersistentFlags().String("to", "", "Chart end date (YYYY-MM-DD or YYYY-MM-DD HH:MM)")
 	rootCmd.PersistentFlags().Int("width", 80, "Chart width in characters")
@@ -161,7 +191,9 @@ Use "crypto [command] --help" for more information about a command.`,
 	configStore = models.NewConfigStore(configDir)
 	watchlist = models.NewWatchlist(configDir)
 	daemonState = models.NewDaemonState(configDir)
+	alertHistory = models.NewAlertHistory(configDir)
 	alertChecker = service.NewAlertChecker(alertManager)
+	alertChecker.SetPortfolio(loadPortfolio)
 	coinGecko = service.NewCoinGecko()
 
 	if err := configStore.Load(); err != nil {
@@ -180,13 +212,68 @@ Use "crypto [command] --help" for more information about a command.`,
 	}
 }
 
+// shutdownHooks run when a signal stops the process, before it exits.
+var shutdownHooks = struct {
+	sync.Mutex
+	next  int
+	hooks map[int]func()
+}{hooks: make(map[int]func())}
+
+// onShutdown registers hook to run if a signal stops the process, and
+// returns a function that unregisters it.
+func onShutdown(hook func()) func() {
+	shutdownHooks.Lock()
+	defer shutdownHooks.Unlock()
+	id := shutdownHooks.next
+	shutdownHooks.next++
+	shutdownHooks.hooks[id] = hook
+	return func() {
+		shutdownHooks.Lock()
+		delete(shutdownHooks.hooks, id)
+		shutdownHooks.Unlock()
+	}
+}
+
+func runShutdownHooks() {
+	shutdownHooks.Lock()
+	hooks := make([]func(), 0, len(shutdownHooks.hooks))
+	for _, hook := range shutdownHooks.hooks {
+		hooks = append(hooks, hook)
+	}
+	shutdownHooks.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// loadPortfolio reads the portfolio from disk, so long-running commands
+// see changes made by other crypto commands. A missing file is empty.
+func loadPortfolio() (*models.Portfolio, error) {
+	current := models.NewPortfolio(filepath.Join(configDir, "portfolio.json"))
+	if err := current.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return current, nil
+}
+
+// rootSignals receives the signals that stop the process, unless the
+// running command handles them itself.
+var rootSignals = make(chan os.Signal, 1)
+
+// handleSignalsInCommand stops the root signal handler, for commands that
+// shut down on their own. The command must be notified of the signals
+// before calling it.
+func handleSignalsInCommand() {
+	signal.Stop(rootSignals)
+}
+
 func Execute() {
 	disableColorsIfNeeded()
 
+	signal.Notify(rootSignals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
+		<-rootSignals
+		runShutdownHooks()
 		fmt.Println("\nShutting down...")
 		alertChecker.Stop()
 		os.Exit(0)
@@ -199,158 +286,44 @@ func Execute() {
 }
 
 func displayPriceGraph(coinID, currency string) {
-	currency = utils.NormalizeCurrency(currency)
-	currencySymbol := utils.CurrencySymbol(currency)
-
-	interval, _ := rootCmd.Flags().GetString("interval")
-	showCandles, _ := rootCmd.Flags().GetBool("candles")
-	fromStr, _ := rootCmd.Flags().GetString("from")
-	toStr, _ := rootCmd.Flags().GetString("to")
-	chartWidth, _ := rootCmd.Flags().GetInt("width")
-	chartHeight, _ := rootCmd.Flags().GetInt("height")
-	if configStore != nil {
-		if chartWidth == 80 {
-			chartWidth = configStore.ChartWidthOrDefault(80)
-		}
-		if chartHeight == 20 {
-			chartHeight = configStore.ChartHeightOrDefault(20)
-		}
-	}
-
-	var fromDate, toDate *time.Time
-	if fromStr != "" {
-		t, err := utils.ParseChartDate(fromStr)
-		if err != nil {
-			fmt.Printf("Error: invalid --from date: %v\n", err)
-			os.Exit(1)
-		}
-		fromDate = &t
-	}
-	if toStr != "" {
-		t, err := utils.ParseChartDate(toStr)
-		if err != nil {
-			fmt.Printf("Error: invalid --to date: %v\n", err)
-			os.Exit(1)
-		}
-		// Include the full end day when only a date is given
-		if !strings.Contains(toStr, ":") {
-			endOfDay := t.Add(24*time.Hour - time.Second)
-			toDate = &endOfDay
-		} else {
-			toDate = &t
-		}
-	}
-
-	// When custom range is set, pick API interval that covers the span
-	apiInterval := service.SelectInterval(interval)
-	if fromDate != nil || toDate != nil {
-		rangeFrom := time.Now().AddDate(0, 0, -7)
-		rangeTo := time.Now()
-		if fromDate != nil {
-			rangeFrom = *fromDate
-		}
-		if toDate != nil {
-			rangeTo = *toDate
-		}
-		apiInterval = service.SelectIntervalForRange(rangeFrom, rangeTo)
-		interval = apiInterval.Name
-	}
-
-	chartCfg := utils.ChartConfig{
-		Width:          chartWidth,
-		Height:         chartHeight,
-		CurrencySymbol: currencySymbol,
-		YTickCount:     5,
-		XTickCount:     5,
-	}
-
-	var series []utils.SeriesPoint
-	var ohlcData []models.OHLC
-
-	if showCandles {
-		data, err := coinGecko.GetCoinOHLC(coinID, currency, apiInterval.Name)
-		if err != nil {
-			fmt.Printf("Error fetching OHLC data: %v\n", err)
+	graph := newPriceGraph(coinID, currency)
+	live := resolveLiveOptions()
+	if live.enabled && graph.exportPath != "" {
+		fmt.Println("Warning: --export is ignored with --live")
+		graph.exportPath = ""
+	}
+	if interactive, _ := rootCmd.Flags().GetBool("interactive"); interactive {
+		if live.enabled {
+			fmt.Println("Error: --interactive cannot be combined with --live")
 			os.Exit(1)
 		}
-		ohlcData = utils.FilterOHLCByDateRange(data, fromDate, toDate)
-		for _, candle := range ohlcData {
-			series = append(series, utils.SeriesPoint{
-				Time:  time.Unix(candle.Time/1000, 0),
-				Value: candle.Close,
-			})
+		if graph.exportPath != "" {
+			fmt.Println("Warning: --export is ignored with --interactive")
 		}
-	} else {
-		prices, err := coinGecko.GetCoinPriceHistory(coinID, currency, apiInterval.Name)
-		if err != nil {
-			fmt.Printf("Error fetching price history: %v\n", err)
+		if err := runInteractiveChart(graph); err != nil {
+			fmt.Printf("Error: %v\n", err)
 			os.Exit(1)
 		}
-		for _, price := range prices {
-			series = append(series, utils.SeriesPoint{
-				Time:  time.Unix(int64(price[0])/1000, 0),
-				Value: price[1],
-			})
-		}
-		series = utils.FilterSeriesByDateRange(series, fromDate, toDate)
+		return
 	}
 
-	if len(series) == 0 {
-		fmt.Println("Error: No price data available for the selected interval or date range")
+	if err := graph.load(); err != nil {
+		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
-
-	chartType := "Line"
-	if showCandles {
-		chartType = "Candlestick"
+	if live.enabled {
+		runLiveGraph(graph, live)
+		return
 	}
 
-	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
-	captionColor := color.New(color.FgHiBlue).SprintFunc()
-	labelColor := color.New(color.FgHiBlue).SprintFunc()
-	valueColor := color.New(color.FgHiWhite).SprintFunc()
-	statsColor := color.New(color.FgHiYellow).SprintFunc()
-
-	fmt.Printf("\n%s %s\n\n", titleColor("📈"), titleColor(fmt.Sprintf("%s %s Chart (%s)",
-		strings.ToUpper(coinID), chartType, interval)))
-
-	stats := utils.ComputePeriodStats(series)
-	fmt.Println(statsColor(utils.FormatPeriodStatsLine(stats, currencySymbol)))
-
-	minPrice, maxPrice := series[0].Value, series[0].Value
-	for _, p := range series[1:] {
-		if p.Value < minPrice {
-			minPrice = p.Value
-		}
-		if p.Value > maxPrice {
-			maxPrice = p.Value
+	fmt.Print(graph.render())
+	if graph.exportPath != "" {
+		if err := graph.export(); err != nil {
+			fmt.Printf("Error exporting chart: %v\n", err)
+			os.Exit(1)
 		}
+		fmt.Printf("Chart exported to %s\n", graph.exportPath)
 	}
-	priceRange := maxPrice - minPrice
-	fmt.Printf("%s %s%s - %s%s (Δ %s%s)\n",
-		labelColor("Price Range:"),
-		currencySymbol, valueColor(utils.FormatCurrency(minPrice)),
-		currencySymbol, valueColor(utils.FormatCurrency(maxPrice)),
-		currencySymbol, valueColor(utils.FormatCurrency(priceRange)))
-
-	startTime := series[0].Time
-	endTime := series[len(series)-1].Time
-	fmt.Printf("%s %s - %s\n\n",
-		labelColor("Time Range:"),
-		valueColor(startTime.Format("2006-01-02 15:04")),
-		valueColor(endTime.Format("2006-01-02 15:04")))
-
-	if showCandles {
-		fmt.Print(utils.RenderCandleChart(ohlcData, chartCfg))
-	} else {
-		fmt.Print(utils.RenderLineChart(series, chartCfg))
-	}
-
-	legend := "█/░ = Bullish/Bearish candles"
-	if !showCandles {
-		legend = "● = price point"
-	}
-	fmt.Println(captionColor(fmt.Sprintf("%s | Data source: coingecko.com at %s", legend, utils.GetCurrentTime())))
 }
 
 func PrintList(page string, perPage string, currency string) {
@@ -368,13 +341,29 @@ func PrintList(page string, perPage string, currency string) {
 	currency = utils.NormalizeCurrency(currency)
 	currencySymbol := utils.CurrencySymbol(currency)
 
+	if live := resolveLiveOptions(); live.enabled {
+		runLiveMarketList(currency, currencySymbol, perPageNum, pageNum, live)
+		return
+	}
+
+	coins, err := coinGecko.GetMarkets(currency, perPageNum, pageNum)
+	if err != nil {
+		fmt.Printf("Error fetching market data: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Print(renderMarketList(coins, currencySymbol))
+}
+
+// renderMarketList returns the market table with its title and caption.
+func renderMarketList(coins []models.Coin, currencySymbol string) string {
+	var buf bytes.Buffer
 	printer := message.NewPrinter(language.English)
-	table := tablewriter.NewWriter(os.Stdout)
+	table := tablewriter.NewWriter(&buf)
 
 	// Color definitions
 	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
 
-	fmt.Printf("\n%s %s\n\n", titleColor("🏆"), titleColor("Top Cryptocurrencies by Market Cap"))
+	fmt.Fprintf(&buf, "\n%s %s\n\n", titleColor("🏆"), titleColor("Top Cryptocurrencies by Market Cap"))
 
 	table.SetHeader([]string{"#", "Coin", "", "Price", "24h", "7d", "Market Cap", "ATH"})
 	table.SetBorder(false)
@@ -390,12 +379,6 @@ func PrintList(page string, perPage string, currency string) {
 		tablewriter.Colors{tablewriter.FgHiBlueColor},
 	)
 
-	coins, err := coinGecko.GetMarkets(currency, perPageNum, pageNum)
-	if err != nil {
-		fmt.Printf("Error fetching market data: %v\n", err)
-		os.Exit(1)
-	}
-
 	for _, coin := range coins {
 		table.Rich([]string{
 			fmt.Sprintf("%d", coin.MarketCapRank),
@@ -420,6 +403,7 @@ func PrintList(page string, perPage string, currency string) {
 
 	table.SetCaption(true, utils.GetCaption())
 	table.Render()
+	return buf.String()
 }
 
 func PrintCoinDetail(coinDetail models.CoinDetail, currency string) {
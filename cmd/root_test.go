@@ -2,12 +2,22 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func setupTestEnv(t *testing.T) {
@@ -19,6 +29,32 @@ func setupTestEnv(t *testing.T) {
 	configStore = models.NewConfigStore(dir)
 	watchlist = models.NewWatchlist(dir)
 	daemonState = models.NewDaemonState(dir)
+	alertHistory = models.NewAlertHistory(dir)
+
+	// Keep tests away from the real systemd user instance
+	originalSystemctl := systemctl
+	t.Cleanup(func() { systemctl = originalSystemctl })
+	systemctl = func(args ...string) (string, error) {
+		return "", errors.New("systemd is not available")
+	}
+}
+
+// startFakeDaemon starts a process whose command line reads as 'crypto
+// alert watch --daemon' and writes its PID file.
+func startFakeDaemon(t *testing.T) int {
+	t.Helper()
+	daemon := exec.Command("/bin/sh", "-c", "sleep 30; true", "alert", "watch", "--daemon")
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = daemon.Process.Kill()
+		_ = daemon.Wait()
+	})
+	if err := daemonState.WritePID(daemon.Process.Pid); err != nil {
+		t.Fatal(err)
+	}
+	return daemon.Process.Pid
 }
 
 func TestGetCurrencyFlagUsesConfigDefault(t *testing.T) {
@@ -72,3 +108,450 @@ func TestPortfolioHasAnyDataModel(t *testing.T) {
 		t.Fatal("expected history-only data")
 	}
 }
+
+func TestParseCoinIDList(t *testing.T) {
+	got := parseCoinIDList("Bitcoin, ethereum,,bitcoin,solana")
+	want := []string{"bitcoin", "ethereum", "solana"}
+	if len(got) != len(want) {
+		t.Fatalf("parseCoinIDList() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("parseCoinIDList() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestParseAlertCondition(t *testing.T) {
+	cases := []struct {
+		args []string
+		want models.Alert
+	}{
+		{[]string{"50000", "Above"}, models.Alert{Condition: models.ConditionAbove, Price: 50000}},
+		{[]string{"change", "+10%"}, models.Alert{Condition: models.ConditionChange, Percent: 10, Window: "24h"}},
+		{[]string{"change", "-5%", "1h"}, models.Alert{Condition: models.ConditionChange, Percent: -5, Window: "1h"}},
+		{[]string{"cross-below", "200d"}, models.Alert{Condition: models.ConditionCrossBelow, MAPeriod: 200}},
+		{[]string{"volume", "3x"}, models.Alert{Condition: models.ConditionVolumeSpike, Multiplier: 3, Window: "7d"}},
+		{[]string{"rank"}, models.Alert{Condition: models.ConditionRankChange, RankMove: 1}},
+		{[]string{"range", "60000", "70000"}, models.Alert{Condition: models.ConditionRangeExit, Low: 60000, High: 70000}},
+		{[]string{"value", "below", "5000"}, models.Alert{Condition: models.ConditionValueBelow, Price: 5000}},
+		{[]string{"daily", "5%"}, models.Alert{Condition: models.ConditionDailyPnL, Percent: 5}},
+		{[]string{"weight", "40%"}, models.Alert{Condition: models.ConditionWeightAbove, Percent: 40}},
+	}
+	for _, tc := range cases {
+		got, err := parseAlertCondition(tc.args)
+		if err != nil || got != tc.want {
+			t.Fatalf("parseAlertCondition(%v) = %+v, %v; want %+v", tc.args, got, err, tc.want)
+		}
+	}
+
+	for _, args := range [][]string{{"change", "0%"}, {"cross-above", "1"}, {"range", "70000", "60000"}, {"rank", "0"}, {"sideways", "1"}, {"50000", "near"}, {"value", "near", "1"}, {"weight", "150%"}} {
+		if _, err := parseAlertCondition(args); err == nil {
+			t.Fatalf("parseAlertCondition(%v) should fail", args)
+		}
+	}
+}
+
+func TestCheckAlertTarget(t *testing.T) {
+	ok := []models.Alert{
+		{CoinID: "portfolio", Condition: models.ConditionDailyPnL},
+		{CoinID: "bitcoin", Condition: models.ConditionWeightAbove},
+		{CoinID: "bitcoin", Condition: models.ConditionAbove},
+	}
+	for _, alert := range ok {
+		if err := checkAlertTarget(alert); err != nil {
+			t.Fatalf("checkAlertTarget(%+v) error = %v", alert, err)
+		}
+	}
+	bad := []models.Alert{
+		{CoinID: "bitcoin", Condition: models.ConditionValueAbove},
+		{CoinID: "portfolio", Condition: models.ConditionWeightAbove},
+		{CoinID: "portfolio", Condition: models.ConditionAbove},
+	}
+	for _, alert := range bad {
+		if err := checkAlertTarget(alert); err == nil {
+			t.Fatalf("checkAlertTarget(%+v) should fail", alert)
+		}
+	}
+}
+
+func TestApplyRepeatFlags(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.Local)
+	newCmd := func(args ...string) *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.Flags().AddFlagSet(alertAddCmd.Flags())
+		cmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false; _ = f.Value.Set(f.DefValue) })
+		if err := cmd.Flags().Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return cmd
+	}
+
+	alert := models.Alert{Condition: models.ConditionAbove, Price: 100}
+	if err := applyRepeatFlags(newCmd("--repeat", "recurring", "--expires", "7d"), &alert, now); err != nil {
+		t.Fatal(err)
+	}
+	if alert.Repeat != models.RepeatRecurring || alert.Cooldown != models.DefaultCooldown || !alert.ExpiresAt.Equal(now.AddDate(0, 0, 7)) {
+		t.Fatalf("unexpected alert %+v", alert)
+	}
+
+	alert = models.Alert{Condition: models.ConditionAbove, Price: 100}
+	if err := applyRepeatFlags(newCmd("--repeat", "rearm", "--expires", "2026-06-30"), &alert, now); err != nil {
+		t.Fatal(err)
+	}
+	if alert.Hysteresis != models.DefaultHysteresis || !alert.ExpiresAt.Equal(time.Date(2026, 7, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("unexpected alert %+v", alert)
+	}
+
+	for _, args := range [][]string{{"--cooldown", "1h"}, {"--repeat", "sometimes"}, {"--expires", "soon"}} {
+		alert = models.Alert{Condition: models.ConditionAbove, Price: 100}
+		if err := applyRepeatFlags(newCmd(args...), &alert, now); err == nil {
+			t.Fatalf("applyRepeatFlags(%v) should fail", args)
+		}
+	}
+}
+
+func TestAlertAddRankWithRepeatFlags(t *testing.T) {
+	setupTestEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":"solana","symbol":"sol","market_cap_rank":5}`))
+	}))
+	defer server.Close()
+	originalBaseURL := service.BaseURL
+	t.Cleanup(func() { service.BaseURL = originalBaseURL })
+	service.BaseURL = server.URL
+	coinGecko = service.NewCoinGecko()
+
+	resetFlags := func() {
+		alertAddCmd.Flags().VisitAll(func(f *pflag.Flag) { f.Changed = false; _ = f.Value.Set(f.DefValue) })
+	}
+	resetFlags()
+	t.Cleanup(resetFlags)
+	_ = alertAddCmd.Flags().Set("repeat", "recurring")
+	_ = alertAddCmd.Flags().Set("cooldown", "6h")
+
+	old := os.Stdout
+	_, w, _ := os.Pipe()
+	os.Stdout = w
+	alertAddCmd.Run(alertAddCmd, []string{"solana", "rank", "2"})
+	_ = w.Close()
+	os.Stdout = old
+
+	alerts := alertManager.GetAlerts()
+	if len(alerts) != 1 || alerts[0].Rank != 5 || alerts[0].RankMove != 2 || alerts[0].Repeat != models.RepeatRecurring || alerts[0].Cooldown != "6h" {
+		t.Fatalf("alerts = %+v", alerts)
+	}
+}
+
+func TestParseHistorySince(t *testing.T) {
+	now := time.Date(2026, 6, 10, 15, 0, 0, 0, time.Local)
+	if got, err := parseHistorySince("7d", now); err != nil || !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Fatalf("parseHistorySince(7d) = %v, %v", got, err)
+	}
+	if got, err := parseHistorySince("2026-06-01", now); err != nil || !got.Equal(time.Date(2026, 6, 1, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("parseHistorySince(date) = %v, %v", got, err)
+	}
+	if _, err := parseHistorySince("yesterday", now); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestAlertHistoryJSON(t *testing.T) {
+	setupTestEnv(t)
+	_ = alertHistory.Append(models.AlertTrigger{
+		Alert:       models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 100, Currency: "usd"},
+		Price:       101,
+		Source:      models.TriggerSourceWatch,
+		TriggeredAt: time.Now(),
+	})
+	_ = alertHistoryCmd.Flags().Set("format", "json")
+	_ = alertHistoryCmd.Flags().Set("coin", "bitcoin")
+	t.Cleanup(func() {
+		_ = alertHistoryCmd.Flags().Set("format", "table")
+		_ = alertHistoryCmd.Flags().Set("coin", "")
+	})
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	alertHistoryCmd.Run(alertHistoryCmd, []string{})
+
+	_ = w.Close()
+	os.Stdout = old
+
+	var triggers []models.AlertTrigger
+	if err := json.NewDecoder(r).Decode(&triggers); err != nil {
+		t.Fatalf("decode history JSON: %v", err)
+	}
+	if len(triggers) != 1 || triggers[0].Price != 101 || triggers[0].Alert.CoinID != "bitcoin" {
+		t.Fatalf("unexpected history %+v", triggers)
+	}
+}
+
+func TestReloadStoresPicksUpOtherProcessWrites(t *testing.T) {
+	setupTestEnv(t)
+	var buf bytes.Buffer
+	useTestLogger(t, &buf)
+
+	unchangedAlerts, unchangedConfig := reloadStores()
+
+	// 'crypto alert add' and a config edit from another process
+	cli := models.NewAlertManager(configDir)
+	_ = cli.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 50000})
+	otherConfig := models.NewConfigStore(configDir)
+	otherConfig.Config.AlertCheckIntervalM = 1
+	_ = otherConfig.Save()
+	alertsChanged, configChanged := reloadStores()
+
+	if unchangedAlerts || unchangedConfig {
+		t.Fatalf("reloadStores() without changes = %v, %v", unchangedAlerts, unchangedConfig)
+	}
+	if !alertsChanged || !configChanged || len(alertManager.GetAlerts()) != 1 || configStore.AlertIntervalOrDefault(5) != 1 {
+		t.Fatalf("reloadStores() = %v, %v; alerts %+v, config %+v", alertsChanged, configChanged, alertManager.GetAlerts(), configStore.Config)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("reloaded alerts.json alerts=1\n")) {
+		t.Fatalf("output = %q", buf.String())
+	}
+}
+
+func TestAlertStatusQueriesDaemon(t *testing.T) {
+	setupTestEnv(t)
+	startFakeDaemon(t)
+	lastCheck := time.Now().Add(-30 * time.Second)
+	server, err := service.ListenControl(daemonState.SocketPath(), func(request service.ControlRequest) service.ControlResponse {
+		return service.ControlResponse{OK: true, Status: &service.DaemonStatus{
+			PID: os.Getpid(),
+			CheckerStatus: service.CheckerStatus{
+				Interval:    "5m0s",
+				Running:     true,
+				LastCheck:   lastCheck,
+				NextCheck:   lastCheck.Add(5 * time.Minute),
+				LastError:   "fetching prices for alerts: API error: 429 Too Many Requests",
+				LastErrorAt: lastCheck,
+				RecentTriggers: []models.AlertTrigger{
+					{Message: "Price Alert: BITCOIN is above $70000.00 (Target: $69000.00 USD)", TriggeredAt: lastCheck},
+				},
+			},
+			Requests: map[string]int64{service.ProviderTelegram: 1, service.ProviderCoinGecko: 12},
+		}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	alertStatusCmd.Run(alertStatusCmd, []string{})
+	_ = w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+
+	for _, want := range []string{
+		"Daemon: running",
+		"(30s ago)",
+		"(in 4m30s)",
+		"Last error: fetching prices for alerts: API error: 429 Too Many Requests",
+		"Requests: coingecko 12, telegram 1",
+		"Price Alert: BITCOIN is above $70000.00",
+	} {
+		if !bytes.Contains(buf.Bytes(), []byte(want)) {
+			t.Fatalf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestAlertWatcherHandleControl(t *testing.T) {
+	setupTestEnv(t)
+	watcher := &alertWatcher{cmd: alertWatchCmd, started: time.Now()}
+
+	response, after := watcher.handleControl(service.ControlRequest{Command: service.ControlReload})
+	if !response.OK || response.Message != "alerts.json and config.json are unchanged" {
+		t.Fatalf("reload = %+v", response)
+	}
+	after()
+	response, _ = watcher.handleControl(service.ControlRequest{Command: service.ControlCheckNow})
+	if response.OK || response.Error != "no active alerts to check" {
+		t.Fatalf("check-now without alerts = %+v", response)
+	}
+	response, _ = watcher.handleControl(service.ControlRequest{Command: service.ControlStatus})
+	if !response.OK || response.Status == nil || response.Status.PID != os.Getpid() {
+		t.Fatalf("status = %+v", response)
+	}
+	if response, _ = watcher.handleControl(service.ControlRequest{Command: "restart"}); response.OK {
+		t.Fatalf("unknown command = %+v", response)
+	}
+}
+
+func TestAlertWatcherMetrics(t *testing.T) {
+	setupTestEnv(t)
+	_ = alertManager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 70000, Currency: "usd"})
+	_ = alertManager.AddAlert(models.Alert{CoinID: "ethereum", Condition: models.ConditionAbove, Price: 4000, Currency: "usd", Disarmed: true})
+	watcher := &alertWatcher{cmd: alertWatchCmd, started: time.Now()}
+	defer watcher.stopMetricsServer()
+
+	configStore.Config.Metrics.Listen = "127.0.0.1:0"
+	if metricsAddress(alertWatchCmd) != "127.0.0.1:0" {
+		t.Fatalf("metricsAddress() = %q", metricsAddress(alertWatchCmd))
+	}
+	watcher.updateMetricsServer(metricsAddress(alertWatchCmd))
+	if watcher.metrics == nil {
+		t.Fatal("metrics server did not start")
+	}
+	resp, err := http.Get("http://" + watcher.metrics.Addr() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	for _, want := range []string{
+		`crypto_alerts{condition="above",state="armed"} 1` + "\n",
+		`crypto_alerts{condition="above",state="disarmed"} 1` + "\n",
+		`crypto_build_info{version="` + Version + `"} 1` + "\n",
+		"# TYPE crypto_api_requests_total counter\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Fatalf("metrics are missing %q:\n%s", want, body)
+		}
+	}
+
+	// Clearing the address in the config stops the endpoint
+	watcher.updateMetricsServer("")
+	if watcher.metrics != nil {
+		t.Fatal("metrics server kept running without an address")
+	}
+}
+
+func TestAlertServiceUnit(t *testing.T) {
+	unit := alertServiceUnit("/home/me/My Tools/crypto", true, "127.0.0.1:9464")
+	for _, want := range []string{
+		"Type=notify\n",
+		`ExecStart="/home/me/My Tools/crypto" alert watch --daemon --stream --metrics-addr 127.0.0.1:9464` + "\n",
+		"WantedBy=default.target\n",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Fatalf("unit missing %q:\n%s", want, unit)
+		}
+	}
+	if got := systemdQuote("/opt/100%/crypto"); got != "/opt/100%%/crypto" {
+		t.Fatalf("systemdQuote() = %q", got)
+	}
+}
+
+func TestServiceInstallFlags(t *testing.T) {
+	if got := serviceInstallFlags(alertStartCmd); got != "" {
+		t.Fatalf("serviceInstallFlags() without flags = %q", got)
+	}
+	_ = alertStartCmd.Flags().Set("stream", "true")
+	_ = alertStartCmd.Flags().Set("metrics-addr", "127.0.0.1:9464")
+	t.Cleanup(func() {
+		_ = alertStartCmd.Flags().Set("stream", "false")
+		_ = alertStartCmd.Flags().Set("metrics-addr", "")
+	})
+	if got := serviceInstallFlags(alertStartCmd); got != "--stream --metrics-addr 127.0.0.1:9464" {
+		t.Fatalf("serviceInstallFlags() = %q", got)
+	}
+}
+
+func TestFindAlertDaemon(t *testing.T) {
+	setupTestEnv(t)
+	systemctl = func(args ...string) (string, error) {
+		return "LoadState=loaded\nActiveState=active\nSubState=running\nUnitFileState=enabled\nMainPID=4242", nil
+	}
+	daemon := findAlertDaemon()
+	if !daemon.running || daemon.pid != 4242 || daemon.service == nil || daemon.service.UnitFileState != "enabled" {
+		t.Fatalf("findAlertDaemon() = %+v", daemon)
+	}
+
+	// A stopped unit leaves room for a daemon started with 'crypto alert
+	// start' before it was installed
+	systemctl = func(args ...string) (string, error) {
+		return "LoadState=loaded\nActiveState=inactive\nSubState=dead\nUnitFileState=enabled\nMainPID=0", nil
+	}
+	daemon = findAlertDaemon()
+	if daemon.running || daemon.service == nil {
+		t.Fatalf("findAlertDaemon() with the unit stopped = %+v", daemon)
+	}
+	pid := startFakeDaemon(t)
+	daemon = findAlertDaemon()
+	if !daemon.running || daemon.pid != pid || daemon.service != nil {
+		t.Fatalf("findAlertDaemon() with a PID file daemon = %+v", daemon)
+	}
+
+	// Without the unit the PID file is used, if it still names the daemon
+	systemctl = func(args ...string) (string, error) {
+		return "LoadState=not-found\nActiveState=inactive\nMainPID=0", nil
+	}
+	daemon = findAlertDaemon()
+	if !daemon.running || daemon.pid != pid || daemon.service != nil {
+		t.Fatalf("findAlertDaemon() without the unit = %+v", daemon)
+	}
+	_ = daemonState.WritePID(os.Getpid())
+	if daemon = findAlertDaemon(); daemon.running {
+		t.Fatalf("findAlertDaemon() with a reused PID = %+v", daemon)
+	}
+}
+
+func TestAlertInstallAndUninstallService(t *testing.T) {
+	setupTestEnv(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	var calls []string
+	installed := false
+	systemctl = func(args ...string) (string, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if args[0] == "show" {
+			if !installed {
+				return "LoadState=not-found", nil
+			}
+			return "LoadState=loaded\nActiveState=active", nil
+		}
+		return "", nil
+	}
+
+	old := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	alertInstallServiceCmd.Run(alertInstallServiceCmd, []string{})
+	unitPath, _ := alertServiceUnitPath()
+	unit, readErr := os.ReadFile(unitPath)
+	installed = true
+	alertUninstallServiceCmd.Run(alertUninstallServiceCmd, []string{})
+	_ = w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	_, _ = buf.ReadFrom(r)
+
+	if readErr != nil || !strings.Contains(string(unit), " alert watch --daemon\n") {
+		t.Fatalf("unit file = %q, %v\n%s", unit, readErr, buf.String())
+	}
+	want := []string{
+		"daemon-reload",
+		"enable crypto-alert.service",
+		"restart crypto-alert.service",
+		"show crypto-alert.service --property=LoadState,ActiveState,SubState,UnitFileState,MainPID",
+		"disable --now crypto-alert.service",
+		"daemon-reload",
+	}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Fatalf("systemctl calls = %q", calls)
+	}
+	if _, err := os.Stat(unitPath); !os.IsNotExist(err) {
+		t.Fatalf("unit file still present after uninstall: %v", err)
+	}
+}
+
+func TestShutdownHooks(t *testing.T) {
+	var ran []string
+	removeFirst := onShutdown(func() { ran = append(ran, "first") })
+	removeSecond := onShutdown(func() { ran = append(ran, "second") })
+	defer removeSecond()
+	removeFirst()
+
+	runShutdownHooks()
+	if len(ran) != 1 || ran[0] != "second" {
+		t.Fatalf("ran %v, want only the registered hook", ran)
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"golang.org/x/sys/unix"
+)
+
+// ANSI sequences for full-screen views.
+const (
+	ansiAltScreenOn  = "\033[?1049h"
+	ansiAltScreenOff = "\033[?1049l"
+	ansiHideCursor   = "\033[?25l"
+	ansiShowCursor   = "\033[?25h"
+)
+
+// terminal holds a raw-mode stdin and an alternate screen buffer for
+// full-screen views. restore must be called before exiting; a signal that
+// stops the process restores it too.
+type terminal struct {
+	fd     int
+	saved  unix.Termios
+	once   sync.Once
+	unhook func()
+}
+
+// openTerminal switches stdin to raw mode (no echo, no line buffering, no
+// signal keys) and enters the alternate screen.
+func openTerminal() (*terminal, error) {
+	fd := int(os.Stdin.Fd())
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, fmt.Errorf("an interactive terminal is required: %v", err)
+	}
+
+	raw := *saved
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("failed to enable raw mode: %v", err)
+	}
+
+	fmt.Print(ansiAltScreenOn + ansiHideCursor)
+	t := &terminal{fd: fd, saved: *saved}
+	// Raw mode turns Ctrl+C into a key, but SIGTERM and SIGHUP still end
+	// the process through the root signal handler
+	t.unhook = onShutdown(t.reset)
+	return t, nil
+}
+
+func (t *terminal) restore() {
+	t.unhook()
+	t.reset()
+}
+
+// reset leaves the alternate screen and restores the saved terminal
+// settings, once.
+func (t *terminal) reset() {
+	t.once.Do(func() {
+		fmt.Print(ansiShowCursor + ansiAltScreenOff)
+		_ = unix.IoctlSetTermios(t.fd, ioctlSetTermios, &t.saved)
+	})
+}
+
+// size returns the terminal width and height, falling back to 120x40.
+func (t *terminal) size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 120, 40
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// readKeys sends key presses from stdin until it is closed.
+func (t *terminal) readKeys(keys chan<- keyEvent) {
+	buf := make([]byte, 64)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			close(keys)
+			return
+		}
+		for _, ev := range parseKeys(buf[:n]) {
+			keys <- ev
+		}
+	}
+}
+
+// keyCode identifies non-printable keys; printable keys use keyRune.
+type keyCode int
+
+const (
+	keyRune keyCode = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyTab
+	keyBackTab
+	keyEnter
+	keyEscape
+	keyCtrlC
+)
+
+type keyEvent struct {
+	code keyCode
+	r    rune
+}
+
+// escapeKeys maps CSI sequences (after "\x1b[") to keys.
+var escapeKeys = map[byte]keyCode{
+	'A': keyUp,
+	'B': keyDown,
+	'C': keyRight,
+	'D': keyLeft,
+	'Z': keyBackTab,
+}
+
+// parseKeys decodes a raw-mode read into key events. Unknown escape
+// sequences are dropped.
+func parseKeys(buf []byte) []keyEvent {
+	var events []keyEvent
+	for len(buf) > 0 {
+		switch b := buf[0]; {
+		case b == 0x1b && len(buf) >= 3 && (buf[1] == '[' || buf[1] == 'O'):
+			if code, ok := escapeKeys[buf[2]]; ok {
+				events = append(events, keyEvent{code: code})
+			}
+			buf = buf[3:]
+		case b == 0x1b:
+			events = append(events, keyEvent{code: keyEscape})
+			buf = buf[1:]
+		case b == '\t':
+			events = append(events, keyEvent{code: keyTab})
+			buf = buf[1:]
+		case b == '\r' || b == '\n':
+			events = append(events, keyEvent{code: keyEnter})
+			buf = buf[1:]
+		case b == 0x03:
+			events = append(events, keyEvent{code: keyCtrlC})
+			buf = buf[1:]
+		default:
+			r, size := utf8.DecodeRune(buf)
+			events = append(events, keyEvent{code: keyRune, r: r})
+			buf = buf[size:]
+		}
+	}
+	return events
+}
@@ -0,0 +1,409 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/service"
+	"github.com/mrcnserkan/crypto/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	tuiMarketRows     = 50
+	tuiDefaultRefresh = 60 * time.Second
+	tuiDefaultCoin    = "bitcoin"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Full-screen dashboard with markets, watchlist, portfolio, alerts and a chart",
+	Long: `Open a full-screen terminal dashboard.
+
+Panes show the market list, your watchlist, portfolio P&L, active alerts and
+a chart of the selected coin. Data refreshes in place every --refresh
+interval (default 60s), respecting the API rate limit.
+
+KEYS:
+  Tab / Shift+Tab   Move focus between panes
+  ↑ ↓ / k j         Move the selection in the focused pane
+  Enter             Chart the selected coin
+  ← → / h l         Switch chart interval
+  r                 Refresh now
+  q / Ctrl+C        Quit
+
+EXAMPLES:
+  crypto tui
+  crypto tui --currency eur --refresh 2m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		currency := utils.NormalizeCurrency(getCurrencyFlag(cmd))
+		refresh := tuiDefaultRefresh
+		if cmd.Flags().Changed("refresh") {
+			refresh, _ = cmd.Flags().GetDuration("refresh")
+		}
+		if minRefresh := service.RateLimitInterval(); refresh < minRefresh {
+			refresh = minRefresh
+		}
+		if err := runTUI(currency, refresh); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tuiCmd)
+}
+
+// tuiPane identifies a focusable dashboard pane.
+type tuiPane int
+
+const (
+	paneMarkets tuiPane = iota
+	paneWatchlist
+	panePortfolio
+	paneAlerts
+	paneCount
+)
+
+var tuiPaneTitles = [paneCount]string{"Markets", "Watchlist", "Portfolio", "Alerts"}
+
+// tuiRequest asks the fetcher for fresh data. Full requests reload the
+// market list and tracked coins; a non-empty chartCoin reloads the chart.
+type tuiRequest struct {
+	full      bool
+	chartCoin string
+	interval  string
+}
+
+// tuiResult carries one completed fetch back to the UI loop.
+type tuiResult struct {
+	markets       []models.Coin
+	tracked       []models.Coin
+	chart         []utils.SeriesPoint
+	chartCoin     string
+	chartInterval string
+	kind          string
+	err           error
+}
+
+const (
+	resultMarkets = "markets"
+	resultTracked = "tracked"
+	resultChart   = "chart"
+)
+
+// dashboard is the TUI state. It is only touched by the UI loop.
+type dashboard struct {
+	currency       string
+	currencySymbol string
+	chartStyle     string
+	intervalIdx    int
+	focus          tuiPane
+	selected       [paneCount]int
+
+	markets []models.Coin
+	tracked map[string]models.Coin
+
+	chartCoin string
+	chart     []utils.SeriesPoint
+	chartErr  error
+
+	pending    int
+	lastUpdate time.Time
+	err        error
+	requests   chan<- tuiRequest
+}
+
+func newDashboard(currency string, requests chan<- tuiRequest) *dashboard {
+	d := &dashboard{
+		currency:       currency,
+		currencySymbol: utils.CurrencySymbol(currency),
+		chartStyle:     utils.ChartStyleASCII,
+		tracked:        make(map[string]models.Coin),
+		chartCoin:      tuiDefaultCoin,
+		requests:       requests,
+	}
+	if configStore != nil {
+		if style, err := utils.ParseChartStyle(configStore.ChartStyleOrDefault(utils.ChartStyleASCII)); err == nil {
+			d.chartStyle = style
+		}
+	}
+	for i, interval := range service.Intervals {
+		if interval.Name == "7d" {
+			d.intervalIdx = i
+		}
+	}
+	if len(watchlist.CoinIDs) > 0 {
+		d.chartCoin = watchlist.CoinIDs[0]
+	}
+	return d
+}
+
+func (d *dashboard) interval() string {
+	return service.Intervals[d.intervalIdx].Name
+}
+
+// request queues a fetch without blocking the UI; it is dropped when the
+// queue is full since a later refresh will cover it.
+func (d *dashboard) request(req tuiRequest) {
+	select {
+	case d.requests <- req:
+		d.pending++
+	default:
+	}
+}
+
+func (d *dashboard) refreshAll() {
+	d.request(tuiRequest{full: true, chartCoin: d.chartCoin, interval: d.interval()})
+}
+
+// apply stores a fetch result.
+func (d *dashboard) apply(res tuiResult) {
+	switch res.kind {
+	case resultMarkets:
+		if res.err == nil {
+			d.markets = res.markets
+		}
+	case resultTracked:
+		if res.err == nil {
+			d.tracked = make(map[string]models.Coin, len(res.tracked))
+			for _, coin := range res.tracked {
+				d.tracked[coin.ID] = coin
+			}
+		}
+	case resultChart:
+		// Ignore charts for a selection the user has already moved away from
+		if res.chartCoin != d.chartCoin || res.chartInterval != d.interval() {
+			return
+		}
+		d.chart, d.chartErr = res.chart, res.err
+	default:
+		// The fetcher finished a request
+		if d.pending > 0 {
+			d.pending--
+		}
+		if d.pending == 0 && d.err == nil {
+			d.lastUpdate = time.Now()
+		}
+		return
+	}
+	d.err = res.err
+	d.clampSelections()
+}
+
+// handleKey updates the dashboard for a key press and reports whether to quit.
+func (d *dashboard) handleKey(ev keyEvent) bool {
+	switch {
+	case ev.code == keyCtrlC || ev.code == keyRune && (ev.r == 'q' || ev.r == 'Q'):
+		return true
+	case ev.code == keyTab:
+		d.focus = (d.focus + 1) % paneCount
+	case ev.code == keyBackTab:
+		d.focus = (d.focus + paneCount - 1) % paneCount
+	case ev.code == keyDown || ev.code == keyRune && ev.r == 'j':
+		d.selected[d.focus]++
+		d.clampSelections()
+	case ev.code == keyUp || ev.code == keyRune && ev.r == 'k':
+		d.selected[d.focus]--
+		d.clampSelections()
+	case ev.code == keyEnter:
+		if coinID := d.coinAt(d.focus, d.selected[d.focus]); coinID != "" && coinID != d.chartCoin {
+			d.chartCoin = coinID
+			d.chart, d.chartErr = nil, nil
+			d.request(tuiRequest{chartCoin: d.chartCoin, interval: d.interval()})
+		}
+	case ev.code == keyRight || ev.code == keyRune && ev.r == 'l':
+		d.switchInterval(1)
+	case ev.code == keyLeft || ev.code == keyRune && ev.r == 'h':
+		d.switchInterval(-1)
+	case ev.code == keyRune && (ev.r == 'r' || ev.r == 'R'):
+		d.refreshAll()
+	}
+	return false
+}
+
+func (d *dashboard) switchInterval(delta int) {
+	d.intervalIdx = (d.intervalIdx + delta + len(service.Intervals)) % len(service.Intervals)
+	d.chart, d.chartErr = nil, nil
+	d.request(tuiRequest{chartCoin: d.chartCoin, interval: d.interval()})
+}
+
+func (d *dashboard) clampSelections() {
+	for pane := tuiPane(0); pane < paneCount; pane++ {
+		d.selected[pane] = clampIndex(d.selected[pane], d.rowCount(pane))
+	}
+}
+
+func clampIndex(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func (d *dashboard) rowCount(pane tuiPane) int {
+	switch pane {
+	case paneMarkets:
+		return len(d.markets)
+	case paneWatchlist:
+		return len(watchlist.CoinIDs)
+	case panePortfolio:
+		return len(d.portfolioPnL().Coins)
+	case paneAlerts:
+		return len(alertManager.GetAlerts())
+	}
+	return 0
+}
+
+// coinAt returns the coin ID shown on row i of pane.
+func (d *dashboard) coinAt(pane tuiPane, i int) string {
+	if i < 0 || i >= d.rowCount(pane) {
+		return ""
+	}
+	switch pane {
+	case paneMarkets:
+		return d.markets[i].ID
+	case paneWatchlist:
+		return watchlist.CoinIDs[i]
+	case panePortfolio:
+		return d.portfolioPnL().Coins[i].CoinID
+	case paneAlerts:
+		return alertManager.GetAlerts()[i].CoinID
+	}
+	return ""
+}
+
+// portfolioPnL computes P&L from tracked prices, largest holdings first.
+func (d *dashboard) portfolioPnL() models.PortfolioPnL {
+	prices := make(map[string]float64, len(d.tracked))
+	for id, coin := range d.tracked {
+		prices[id] = coin.CurrentPrice
+	}
+	pnl := models.ComputePortfolioPnL(portfolio, prices, d.currency)
+	sort.Slice(pnl.Coins, func(i, j int) bool {
+		if pnl.Coins[i].CurrentValue != pnl.Coins[j].CurrentValue {
+			return pnl.Coins[i].CurrentValue > pnl.Coins[j].CurrentValue
+		}
+		return pnl.Coins[i].CoinID < pnl.Coins[j].CoinID
+	})
+	return pnl
+}
+
+// trackedCoinIDs lists the watchlist, portfolio and alert coins.
+func trackedCoinIDs() []string {
+	seen := make(map[string]struct{})
+	var ids []string
+	add := func(id string) {
+		id = utils.NormalizeCoinID(id)
+		if _, ok := seen[id]; ok || id == "" {
+			return
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+	for _, id := range watchlist.CoinIDs {
+		add(id)
+	}
+	for id := range portfolio.Holdings {
+		add(id)
+	}
+	for _, alert := range alertManager.GetAlerts() {
+		add(alert.CoinID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// tuiFetcher serves requests one at a time, coalescing queued ones so a burst
+// of key presses costs a single chart fetch. Every request ends with a
+// result of empty kind.
+func tuiFetcher(currency string, requests <-chan tuiRequest, results chan<- tuiResult) {
+	for req := range requests {
+		completed := 1
+	drain:
+		for {
+			select {
+			case next := <-requests:
+				completed++
+				req.full = req.full || next.full
+				if next.chartCoin != "" {
+					req.chartCoin, req.interval = next.chartCoin, next.interval
+				}
+			default:
+				break drain
+			}
+		}
+
+		if req.full {
+			markets, err := coinGecko.GetMarkets(currency, tuiMarketRows, 1)
+			results <- tuiResult{kind: resultMarkets, markets: markets, err: err}
+			if ids := trackedCoinIDs(); len(ids) > 0 {
+				tracked, err := coinGecko.GetMarketsByIDs(currency, ids)
+				results <- tuiResult{kind: resultTracked, tracked: tracked, err: err}
+			}
+		}
+		if req.chartCoin != "" {
+			r := chartRange{interval: req.interval, apiInterval: service.SelectInterval(req.interval)}
+			points, _, err := fetchMarketSeries(req.chartCoin, currency, r, metricPrice)
+			results <- tuiResult{kind: resultChart, chart: points, chartCoin: req.chartCoin, chartInterval: req.interval, err: err}
+		}
+		for i := 0; i < completed; i++ {
+			results <- tuiResult{}
+		}
+	}
+}
+
+// runTUI owns the terminal until the user quits.
+func runTUI(currency string, refresh time.Duration) error {
+	term, err := openTerminal()
+	if err != nil {
+		return err
+	}
+	defer term.restore()
+
+	keys := make(chan keyEvent, 16)
+	go term.readKeys(keys)
+	resize := make(chan os.Signal, 1)
+	signal.Notify(resize, syscall.SIGWINCH)
+	defer signal.Stop(resize)
+
+	requests := make(chan tuiRequest, 16)
+	results := make(chan tuiResult, 16)
+	go tuiFetcher(currency, requests, results)
+
+	d := newDashboard(currency, requests)
+	d.refreshAll()
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	draw := func() {
+		width, height := term.size()
+		fmt.Print(ansiCursorHome + strings.Join(d.render(width, height), "\n"))
+	}
+	draw()
+	for {
+		select {
+		case ev, ok := <-keys:
+			if !ok || d.handleKey(ev) {
+				return nil
+			}
+		case res := <-results:
+			d.apply(res)
+		case <-ticker.C:
+			d.refreshAll()
+		case <-resize:
+			fmt.Print(ansiClearScreen)
+		}
+		draw()
+	}
+}
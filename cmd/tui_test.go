@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1b[Z\t\rq\x03\x1b"))
+	want := []keyEvent{
+		{code: keyRune, r: 'j'},
+		{code: keyUp},
+		{code: keyBackTab},
+		{code: keyTab},
+		{code: keyEnter},
+		{code: keyRune, r: 'q'},
+		{code: keyCtrlC},
+		{code: keyEscape},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseKeys = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("event %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFitLine(t *testing.T) {
+	if got := fitLine("abc", 5); got != "abc  " {
+		t.Fatalf("pad = %q", got)
+	}
+	if got := fitLine("abcdef", 3); got != "abc" {
+		t.Fatalf("truncate = %q", got)
+	}
+	styled := "\033[32m+1.0%\033[0m"
+	if got := fitLine(styled, 8); visibleWidth(got) != 8 || !strings.HasPrefix(got, styled) {
+		t.Fatalf("styled pad = %q", got)
+	}
+	if got := fitLine(styled, 2); got != "\033[32m+1"+ansiReset {
+		t.Fatalf("styled truncate = %q", got)
+	}
+}
+
+func TestBoxDimensions(t *testing.T) {
+	lines := box("Title", []string{"one", "a line that is much too long for the box"}, 12, 4, false)
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines", len(lines))
+	}
+	for _, line := range lines {
+		if visibleWidth(line) != 12 {
+			t.Fatalf("line %q is %d wide", line, visibleWidth(line))
+		}
+	}
+	if !strings.HasPrefix(lines[0], "┌─ Title ─") {
+		t.Fatalf("top border = %q", lines[0])
+	}
+}
+
+func newTestDashboard(t *testing.T) (*dashboard, chan tuiRequest) {
+	setupTestEnv(t)
+	requests := make(chan tuiRequest, 16)
+	d := newDashboard("usd", requests)
+	d.markets = []models.Coin{
+		{ID: "bitcoin", Symbol: "btc", MarketCapRank: 1, CurrentPrice: 60000, PriceChangePercentage24h: 1.5},
+		{ID: "ethereum", Symbol: "eth", MarketCapRank: 2, CurrentPrice: 3000, PriceChangePercentage24h: -2},
+	}
+	return d, requests
+}
+
+func TestDashboardRenderFillsScreen(t *testing.T) {
+	d, _ := newTestDashboard(t)
+	_ = watchlist.Add("ethereum")
+	_ = alertManager.AddAlert(models.Alert{CoinID: "bitcoin", Price: 70000, Condition: "above", Currency: "usd"})
+	_ = portfolio.AddTransaction(models.Transaction{CoinID: "bitcoin", Symbol: "btc", Amount: 1, Price: 50000, Currency: "usd", Type: "buy"})
+	d.tracked = map[string]models.Coin{"bitcoin": d.markets[0], "ethereum": d.markets[1]}
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 50; i++ {
+		d.chart = append(d.chart, utils.SeriesPoint{Time: base.Add(time.Duration(i) * time.Hour), Value: 60000 + float64(i*10)})
+	}
+
+	for _, size := range [][2]int{{120, 40}, {80, 20}, {200, 60}} {
+		lines := d.render(size[0], size[1])
+		if len(lines) != size[1] {
+			t.Fatalf("%dx%d: got %d lines", size[0], size[1], len(lines))
+		}
+		for i, line := range lines {
+			if visibleWidth(line) != size[0] {
+				t.Fatalf("%dx%d: line %d is %d wide: %q", size[0], size[1], i, visibleWidth(line), stripANSI(line))
+			}
+		}
+	}
+
+	screen := stripANSI(strings.Join(d.render(120, 40), "\n"))
+	for _, want := range []string{"Markets", "Watchlist", "Portfolio", "Alerts", "Chart BTC 7d", "ETH", "above", "+20.0%"} {
+		if !strings.Contains(screen, want) {
+			t.Fatalf("screen missing %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestDashboardKeys(t *testing.T) {
+	d, requests := newTestDashboard(t)
+
+	d.handleKey(keyEvent{code: keyDown})
+	d.handleKey(keyEvent{code: keyDown})
+	if d.selected[paneMarkets] != 1 {
+		t.Fatalf("selection = %d, want clamped to 1", d.selected[paneMarkets])
+	}
+
+	d.handleKey(keyEvent{code: keyEnter})
+	if d.chartCoin != "ethereum" {
+		t.Fatalf("chartCoin = %q", d.chartCoin)
+	}
+	if req := <-requests; req.chartCoin != "ethereum" || req.full {
+		t.Fatalf("request = %+v", req)
+	}
+
+	d.handleKey(keyEvent{code: keyRight})
+	if req := <-requests; req.interval != "14d" {
+		t.Fatalf("interval request = %+v", req)
+	}
+
+	d.handleKey(keyEvent{code: keyBackTab})
+	if d.focus != paneAlerts {
+		t.Fatalf("focus = %d", d.focus)
+	}
+	if !d.handleKey(keyEvent{code: keyRune, r: 'q'}) {
+		t.Fatal("q should quit")
+	}
+}
+
+func TestDashboardIgnoresStaleChart(t *testing.T) {
+	d, _ := newTestDashboard(t)
+	d.chartCoin = "ethereum"
+	d.apply(tuiResult{kind: resultChart, chartCoin: "bitcoin", chartInterval: d.interval(), chart: []utils.SeriesPoint{{Value: 1}}})
+	if d.chart != nil {
+		t.Fatal("stale chart was applied")
+	}
+}
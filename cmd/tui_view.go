@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+const (
+	tuiMinWidth  = 80
+	tuiMinHeight = 20
+	ansiReset    = "\033[0m"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func stripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
+
+// visibleWidth counts the runes of s that occupy a terminal cell.
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(stripANSI(s))
+}
+
+// fitLine pads or truncates s to exactly width visible cells, keeping ANSI
+// sequences intact and resetting attributes after a cut.
+func fitLine(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	cells, styled := 0, false
+	for i := 0; i < len(s); {
+		if loc := ansiPattern.FindStringIndex(s[i:]); loc != nil && loc[0] == 0 {
+			sb.WriteString(s[i : i+loc[1]])
+			i += loc[1]
+			styled = true
+			continue
+		}
+		if cells == width {
+			if styled {
+				sb.WriteString(ansiReset)
+			}
+			return sb.String()
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		sb.WriteRune(r)
+		i += size
+		cells++
+	}
+	sb.WriteString(strings.Repeat(" ", width-cells))
+	return sb.String()
+}
+
+// box draws lines inside a width x height frame with title in the top border.
+// Focused boxes use a heavier, highlighted border.
+func box(title string, lines []string, width, height int, focused bool) []string {
+	if width < 4 || height < 2 {
+		return nil
+	}
+	h, v, tl, tr, bl, br := "─", "│", "┌", "┐", "└", "┘"
+	paint := fmt.Sprint
+	if focused {
+		h, v, tl, tr, bl, br = "━", "┃", "┏", "┓", "┗", "┛"
+		paint = color.New(color.FgHiCyan).SprintFunc()
+	}
+
+	inner := width - 2
+	top := h + " " + title + " "
+	if visibleWidth(top) > inner {
+		top = fitLine(top, inner)
+	}
+	top += strings.Repeat(h, inner-visibleWidth(top))
+
+	out := make([]string, 0, height)
+	out = append(out, paint(tl+top+tr))
+	for i := 0; i < height-2; i++ {
+		line := ""
+		if i < len(lines) {
+			line = lines[i]
+		}
+		out = append(out, paint(v)+fitLine(line, inner)+paint(v))
+	}
+	out = append(out, paint(bl+strings.Repeat(h, inner)+br))
+	return out
+}
+
+// joinColumns places blocks side by side; all blocks must have equal height.
+func joinColumns(blocks ...[]string) []string {
+	if len(blocks) == 0 {
+		return nil
+	}
+	out := make([]string, len(blocks[0]))
+	for _, block := range blocks {
+		for i := range out {
+			if i < len(block) {
+				out[i] += block[i]
+			}
+		}
+	}
+	return out
+}
+
+// render lays out the dashboard as exactly height lines of width cells:
+// markets and watchlist on the left, the chart above portfolio and alerts
+// on the right, with a header and a key help line.
+func (d *dashboard) render(width, height int) []string {
+	if width < tuiMinWidth || height < tuiMinHeight {
+		msg := fmt.Sprintf("Terminal too small: need %dx%d, have %dx%d", tuiMinWidth, tuiMinHeight, width, height)
+		lines := make([]string, intMax(height, 1))
+		for i := range lines {
+			lines[i] = fitLine("", width)
+		}
+		lines[0] = fitLine(msg, width)
+		return lines
+	}
+
+	bodyHeight := height - 2
+	leftWidth := intMax(34, width*2/5)
+	rightWidth := width - leftWidth
+	marketsHeight := (bodyHeight + 1) / 2
+	chartHeight := bodyHeight * 3 / 5
+	bottomHeight := bodyHeight - chartHeight
+	portfolioWidth := rightWidth / 2
+
+	left := append(
+		d.listBox(paneMarkets, d.marketLines(), leftWidth, marketsHeight),
+		d.listBox(paneWatchlist, d.watchlistLines(), leftWidth, bodyHeight-marketsHeight)...,
+	)
+	right := append(
+		d.chartBox(rightWidth, chartHeight),
+		joinColumns(
+			d.listBox(panePortfolio, d.portfolioLines(), portfolioWidth, bottomHeight),
+			d.listBox(paneAlerts, d.alertLines(), rightWidth-portfolioWidth, bottomHeight),
+		)...,
+	)
+
+	lines := make([]string, 0, height)
+	lines = append(lines, fitLine(d.headerLine(), width))
+	lines = append(lines, joinColumns(left, right)...)
+	lines = append(lines, fitLine(" Tab focus  ↑↓ select  Enter chart  ←→ interval  r refresh  q quit", width))
+	return lines
+}
+
+func (d *dashboard) headerLine() string {
+	titleColor := color.New(color.FgHiCyan, color.Bold).SprintFunc()
+	parts := []string{
+		titleColor(" crypto tui"),
+		strings.ToUpper(d.currency),
+		"interval " + d.interval(),
+	}
+	if !d.lastUpdate.IsZero() {
+		parts = append(parts, "updated "+d.lastUpdate.Format("15:04:05"))
+	}
+	if d.pending > 0 {
+		parts = append(parts, "refreshing...")
+	}
+	if d.err != nil {
+		parts = append(parts, color.New(color.FgRed).Sprintf("error: %v", d.err))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// listBox frames rows for a list pane, scrolling so the selection stays
+// visible and highlighting it when the pane has focus.
+func (d *dashboard) listBox(pane tuiPane, rows []string, width, height int) []string {
+	visible := intMax(height-2, 0)
+	selected := d.selected[pane]
+	offset := 0
+	if selected >= visible {
+		offset = selected - visible + 1
+	}
+
+	lines := make([]string, 0, visible)
+	for i := offset; i < len(rows) && len(lines) < visible; i++ {
+		row := rows[i]
+		if d.focus == pane && i == selected {
+			row = color.New(color.ReverseVideo).Sprint(fitLine(stripANSI(row), width-2))
+		}
+		lines = append(lines, row)
+	}
+	if len(rows) == 0 {
+		lines = append(lines, d.emptyText(pane))
+	}
+
+	title := tuiPaneTitles[pane]
+	if len(rows) > visible && visible > 0 {
+		title = fmt.Sprintf("%s %d/%d", title, selected+1, len(rows))
+	}
+	return box(title, lines, width, height, d.focus == pane)
+}
+
+func (d *dashboard) emptyText(pane tuiPane) string {
+	switch pane {
+	case paneWatchlist:
+		return " No coins. Use 'crypto watchlist add <coin>'."
+	case panePortfolio:
+		return " No holdings. Use 'crypto portfolio add'."
+	case paneAlerts:
+		return " No alerts. Use 'crypto alert add'."
+	}
+	if d.pending > 0 {
+		return " Loading..."
+	}
+	return " No data"
+}
+
+func (d *dashboard) formatPrice(value float64) string {
+	return d.currencySymbol + utils.FormatCurrency(value)
+}
+
+func coloredChange(change float64) string {
+	text := fmt.Sprintf("%+.1f%%", change)
+	if change >= 0 {
+		return color.New(color.FgGreen).Sprint(text)
+	}
+	return color.New(color.FgRed).Sprint(text)
+}
+
+// coinSymbol returns the ticker for coinID when it has been fetched.
+func (d *dashboard) coinSymbol(coinID string) string {
+	if coin, ok := d.tracked[coinID]; ok && coin.Symbol != "" {
+		return strings.ToUpper(coin.Symbol)
+	}
+	for _, coin := range d.markets {
+		if coin.ID == coinID {
+			return strings.ToUpper(coin.Symbol)
+		}
+	}
+	return coinID
+}
+
+func (d *dashboard) marketLines() []string {
+	rows := make([]string, 0, len(d.markets))
+	for _, coin := range d.markets {
+		rows = append(rows, fmt.Sprintf(" %3d %-7s %12s %s",
+			coin.MarketCapRank, strings.ToUpper(coin.Symbol), d.formatPrice(coin.CurrentPrice), coloredChange(coin.PriceChangePercentage24h)))
+	}
+	return rows
+}
+
+func (d *dashboard) watchlistLines() []string {
+	rows := make([]string, 0, len(watchlist.CoinIDs))
+	for _, id := range watchlist.CoinIDs {
+		coin, ok := d.tracked[utils.NormalizeCoinID(id)]
+		if !ok {
+			rows = append(rows, fmt.Sprintf(" %-11s %12s", id, "-"))
+			continue
+		}
+		rows = append(rows, fmt.Sprintf(" %-11s %12s %s",
+			strings.ToUpper(coin.Symbol), d.formatPrice(coin.CurrentPrice), coloredChange(coin.PriceChangePercentage24h)))
+	}
+	return rows
+}
+
+func (d *dashboard) portfolioLines() []string {
+	pnl := d.portfolioPnL()
+	rows := make([]string, 0, len(pnl.Coins))
+	for _, coin := range pnl.Coins {
+		rows = append(rows, d.portfolioRow(coin))
+	}
+	return rows
+}
+
+func (d *dashboard) portfolioRow(coin models.CoinPnL) string {
+	if coin.CurrentPrice == 0 {
+		return fmt.Sprintf(" %-7s %10s", d.coinSymbol(coin.CoinID), "-")
+	}
+	return fmt.Sprintf(" %-7s %10s %s",
+		d.coinSymbol(coin.CoinID), d.formatPrice(coin.CurrentValue), coloredChange(coin.UnrealizedPnLPct))
+}
+
+func (d *dashboard) alertLines() []string {
+	alerts := alertManager.GetAlerts()
+	rows := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		currency := alert.Currency
+		if currency == "" {
+			currency = "usd"
+		}
+		if !alert.IsPriceTarget() {
+			rows = append(rows, fmt.Sprintf(" %-7s %s", d.coinSymbol(alert.CoinID), alert.Describe(utils.CurrencySymbol(currency))))
+			continue
+		}
+		row := fmt.Sprintf(" %-7s %-5s %s%s", d.coinSymbol(alert.CoinID), alert.Condition,
+			utils.CurrencySymbol(currency), utils.FormatCurrency(alert.Price))
+		if coin, ok := d.tracked[alert.CoinID]; ok && currency == d.currency {
+			away := (alert.Price - coin.CurrentPrice) / coin.CurrentPrice * 100
+			row += fmt.Sprintf(" (%+.1f%%)", away)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// chartBox renders the selected coin's price chart to fill the box.
+func (d *dashboard) chartBox(width, height int) []string {
+	title := fmt.Sprintf("Chart %s %s", d.coinSymbol(d.chartCoin), d.interval())
+	innerWidth, innerHeight := width-2, height-2
+
+	var lines []string
+	switch {
+	case d.chartErr != nil:
+		lines = []string{" " + color.New(color.FgRed).Sprintf("Error: %v", d.chartErr)}
+	case len(d.chart) == 0:
+		lines = []string{" Loading chart..."}
+	default:
+		first, last := d.chart[0].Value, d.chart[len(d.chart)-1].Value
+		title += " " + d.formatPrice(last)
+		if first != 0 {
+			title += " " + stripANSI(coloredChange((last-first)/first*100))
+		}
+		lines = d.chartLines(innerWidth, innerHeight)
+	}
+	return box(title, lines, width, height, false)
+}
+
+// chartLines renders the chart, resizing the plot once the width of the Y
+// axis labels is known.
+func (d *dashboard) chartLines(width, height int) []string {
+	cfg := utils.ChartConfig{
+		Width:          width - 14,
+		Height:         height - 2,
+		CurrencySymbol: d.currencySymbol,
+		YTickCount:     intMin(5, intMax(2, (height-2)/3)),
+		XTickCount:     intMin(5, intMax(2, width/20)),
+		Style:          d.chartStyle,
+	}
+	var lines []string
+	for attempt := 0; attempt < 2; attempt++ {
+		lines = strings.Split(strings.TrimRight(utils.RenderLineChart(d.chart, cfg), "\n"), "\n")
+		widest := 0
+		for _, line := range lines {
+			widest = intMax(widest, visibleWidth(line))
+		}
+		if widest == width {
+			break
+		}
+		cfg.Width += width - widest
+	}
+	return lines
+}
+
+func intMin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func intMax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -9,25 +9,305 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Alert conditions. Above and below compare the price with Price; the
+// others use the condition fields documented on Alert.
+const (
+	ConditionAbove       = "above"
+	ConditionBelow       = "below"
+	ConditionChange      = "change"
+	ConditionCrossAbove  = "cross_above"
+	ConditionCrossBelow  = "cross_below"
+	ConditionVolumeSpike = "volume_spike"
+	ConditionRankChange  = "rank_change"
+	ConditionRangeExit   = "range_exit"
+
+	// Portfolio conditions. Value alerts compare the total portfolio value
+	// with Price, daily P&L alerts wait for a 24h move of Percent in either
+	// direction, and weight alerts for CoinID to exceed Percent of the value.
+	ConditionValueAbove  = "value_above"
+	ConditionValueBelow  = "value_below"
+	ConditionDailyPnL    = "daily_pnl"
+	ConditionWeightAbove = "weight_above"
+)
+
+// Repeat modes. Once alerts are removed when they fire, recurring alerts
+// fire again after Cooldown, and rearm alerts fire again only after the
+// value has moved back past the threshold by Hysteresis percent.
+const (
+	RepeatOnce      = "once"
+	RepeatRecurring = "recurring"
+	RepeatRearm     = "rearm"
+
+	DefaultCooldown   = "1h"
+	DefaultHysteresis = 1.0
+)
+
+// PortfolioAlertID is the CoinID of alerts on the whole portfolio.
+const PortfolioAlertID = "portfolio"
+
+// Default lookbacks for change and volume spike alerts.
+const (
+	DefaultChangeWindow = "24h"
+	DefaultVolumeWindow = "7d"
+)
+
 type Alert struct {
-	CoinID    string    `json:"coin_id"`
-	Price     float64   `json:"price"`
-	Condition string    `json:"condition"` // "above" or "below"
-	Currency  string    `json:"currency,omitempty"`
+	CoinID    string  `json:"coin_id"`
+	Price     float64 `json:"price"`
+	Condition string  `json:"condition"`
+	Currency  string  `json:"currency,omitempty"`
+
+	// Percent is the move a change alert waits for: positive for a rise,
+	// negative for a fall.
+	Percent float64 `json:"percent,omitempty"`
+	// Window is the lookback, e.g. "24h" or "7d". Change alerts compare with
+	// the price this long ago; volume spikes with the average daily volume
+	// over it.
+	Window string `json:"window,omitempty"`
+	// MAPeriod is the length in days of the moving average cross alerts use.
+	MAPeriod int `json:"ma_period,omitempty"`
+	// Multiplier is how many times the average volume counts as a spike.
+	Multiplier float64 `json:"multiplier,omitempty"`
+	// Rank is the market-cap rank when a rank_change alert was created and
+	// RankMove the number of positions it must move from there.
+	Rank     int `json:"rank,omitempty"`
+	RankMove int `json:"rank_move,omitempty"`
+	// Low and High bound the price range of a range_exit alert.
+	Low  float64 `json:"low,omitempty"`
+	High float64 `json:"high,omitempty"`
+
+	// Repeat is the repeat mode; empty means once.
+	Repeat     string  `json:"repeat,omitempty"`
+	Cooldown   string  `json:"cooldown,omitempty"`
+	Hysteresis float64 `json:"hysteresis,omitempty"`
+	// ExpiresAt removes the alert once reached, fired or not.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// LastTriggeredAt is when a repeating alert last fired. Disarmed rearm
+	// alerts wait for the value to move back before they can fire again.
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	Disarmed        bool       `json:"disarmed,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// RepeatMode returns Repeat, defaulting to RepeatOnce.
+func (a Alert) RepeatMode() string {
+	if a.Repeat == "" {
+		return RepeatOnce
+	}
+	return a.Repeat
+}
+
+// Expired reports whether the alert's expiry has passed at now.
+func (a Alert) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
+
+// InCooldown reports whether a recurring alert fired too recently to fire
+// again at now.
+func (a Alert) InCooldown(now time.Time) bool {
+	if a.RepeatMode() != RepeatRecurring || a.LastTriggeredAt == nil {
+		return false
+	}
+	cooldown, err := ParseAlertWindow(a.Cooldown)
+	if err != nil {
+		return false
+	}
+	return now.Before(a.LastTriggeredAt.Add(cooldown))
+}
+
+// IsPriceTarget reports whether the alert is a plain above/below price alert.
+func (a Alert) IsPriceTarget() bool {
+	return a.Condition == ConditionAbove || a.Condition == ConditionBelow
+}
+
+// IsPortfolioAlert reports whether the alert is evaluated against the
+// portfolio rather than a single coin's market data.
+func (a Alert) IsPortfolioAlert() bool {
+	switch a.Condition {
+	case ConditionValueAbove, ConditionValueBelow, ConditionDailyPnL, ConditionWeightAbove:
+		return true
+	}
+	return false
+}
+
+// Validate checks that the fields a condition needs are set.
+func (a Alert) Validate() error {
+	switch a.Condition {
+	case ConditionAbove, ConditionBelow:
+		if a.Price <= 0 {
+			return fmt.Errorf("price must be greater than zero")
+		}
+	case ConditionChange:
+		if a.Percent == 0 {
+			return fmt.Errorf("percent change must not be zero")
+		}
+		if _, err := ParseAlertWindow(a.Window); err != nil {
+			return err
+		}
+	case ConditionCrossAbove, ConditionCrossBelow:
+		if a.MAPeriod < 2 {
+			return fmt.Errorf("moving average period must be at least 2 days")
+		}
+	case ConditionVolumeSpike:
+		if a.Multiplier <= 1 {
+			return fmt.Errorf("volume multiplier must be greater than 1")
+		}
+		if window, err := ParseAlertWindow(a.Window); err != nil {
+			return err
+		} else if window < 48*time.Hour {
+			return fmt.Errorf("volume window must be at least 2d")
+		}
+	case ConditionRankChange:
+		if a.Rank <= 0 || a.RankMove <= 0 {
+			return fmt.Errorf("rank alerts need a current rank and a move of at least one position")
+		}
+	case ConditionRangeExit:
+		if a.Low <= 0 || a.High <= a.Low {
+			return fmt.Errorf("range must have 0 < low < high")
+		}
+	case ConditionValueAbove, ConditionValueBelow:
+		if a.Price <= 0 {
+			return fmt.Errorf("portfolio value must be greater than zero")
+		}
+	case ConditionDailyPnL:
+		if a.Percent <= 0 {
+			return fmt.Errorf("daily P&L move must be greater than zero")
+		}
+	case ConditionWeightAbove:
+		if a.Percent <= 0 || a.Percent >= 100 {
+			return fmt.Errorf("weight must be between 0 and 100 percent")
+		}
+	default:
+		return fmt.Errorf("unknown condition %q", a.Condition)
+	}
+	return a.ValidateRepeat()
+}
+
+// ValidateRepeat checks the repeat mode, cooldown and hysteresis, which are
+// set the same way for every condition.
+func (a Alert) ValidateRepeat() error {
+	switch a.RepeatMode() {
+	case RepeatOnce:
+	case RepeatRecurring:
+		if _, err := ParseAlertWindow(a.Cooldown); err != nil {
+			return fmt.Errorf("cooldown: %w", err)
+		}
+	case RepeatRearm:
+		if a.Hysteresis < 0 || a.Hysteresis >= 100 {
+			return fmt.Errorf("hysteresis must be between 0 and 100 percent")
+		}
+	default:
+		return fmt.Errorf("unknown repeat mode %q (use once, recurring or rearm)", a.Repeat)
+	}
+	if a.Cooldown != "" && a.RepeatMode() != RepeatRecurring {
+		return fmt.Errorf("cooldown only applies to recurring alerts")
+	}
+	if a.Hysteresis != 0 && a.RepeatMode() != RepeatRearm {
+		return fmt.Errorf("hysteresis only applies to rearm alerts")
+	}
+	return nil
+}
+
+// ParseAlertWindow parses lookbacks such as "1h", "24h", "7d" or "2w".
+func ParseAlertWindow(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return 0, fmt.Errorf("invalid window %q (use e.g. 1h, 24h, 7d)", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid window %q (use e.g. 1h, 24h, 7d)", s)
+	}
+	switch s[len(s)-1] {
+	case 'h':
+		return time.Duration(n) * time.Hour, nil
+	case 'd':
+		return time.Duration(n) * 24 * time.Hour, nil
+	case 'w':
+		return time.Duration(n) * 7 * 24 * time.Hour, nil
+	}
+	return 0, fmt.Errorf("invalid window %q (use e.g. 1h, 24h, 7d)", s)
+}
+
+// Describe renders the condition for display, e.g. "above $50000.00" or
+// "+10% in 24h".
+func (a Alert) Describe(currencySymbol string) string {
+	switch a.Condition {
+	case ConditionAbove, ConditionBelow:
+		return fmt.Sprintf("%s %s%.2f", a.Condition, currencySymbol, a.Price)
+	case ConditionChange:
+		return fmt.Sprintf("%+g%% in %s", a.Percent, a.Window)
+	case ConditionCrossAbove:
+		return fmt.Sprintf("crosses above %dd MA", a.MAPeriod)
+	case ConditionCrossBelow:
+		return fmt.Sprintf("crosses below %dd MA", a.MAPeriod)
+	case ConditionVolumeSpike:
+		return fmt.Sprintf("volume %gx %s avg", a.Multiplier, a.Window)
+	case ConditionRankChange:
+		return fmt.Sprintf("rank moves %d from #%d", a.RankMove, a.Rank)
+	case ConditionRangeExit:
+		return fmt.Sprintf("leaves %s%.2f-%s%.2f", currencySymbol, a.Low, currencySymbol, a.High)
+	case ConditionValueAbove:
+		return fmt.Sprintf("value above %s%.2f", currencySymbol, a.Price)
+	case ConditionValueBelow:
+		return fmt.Sprintf("value below %s%.2f", currencySymbol, a.Price)
+	case ConditionDailyPnL:
+		return fmt.Sprintf("daily P&L beyond ±%g%%", a.Percent)
+	case ConditionWeightAbove:
+		return fmt.Sprintf("weight above %g%%", a.Percent)
+	}
+	return a.Condition
+}
+
+// sameCondition reports whether two alerts watch the same thing. The rank
+// baseline is ignored so a rank alert is not added twice on different days.
+func (a Alert) sameCondition(b Alert) bool {
+	return a.Key() == b.Key()
+}
+
+// AlertKey identifies an alert by what it watches, leaving out its state.
+type AlertKey struct {
+	CoinID, Condition, Currency, Window   string
+	Price, Percent, Multiplier, Low, High float64
+	MAPeriod, RankMove                    int
+}
+
+// Key returns the fields that identify the alert.
+func (a Alert) Key() AlertKey {
+	return AlertKey{
+		CoinID:     a.CoinID,
+		Condition:  a.Condition,
+		Currency:   a.Currency,
+		Window:     a.Window,
+		Price:      a.Price,
+		Percent:    a.Percent,
+		Multiplier: a.Multiplier,
+		Low:        a.Low,
+		High:       a.High,
+		MAPeriod:   a.MAPeriod,
+		RankMove:   a.RankMove,
+	}
+}
+
 type AlertData struct {
 	Alerts []Alert `json:"alerts"`
 }
 
 type AlertManager struct {
+	// mu guards alerts, which the alert checker, stream and bot share.
+	mu        sync.Mutex
 	alerts    []Alert
 	alertFile string
+	// version is the file as last read or written.
+	version fileVersion
 }
 
 func NewAlertManager(configDir string) *AlertManager {
@@ -40,6 +320,9 @@ func NewAlertManager(configDir string) *AlertManager {
 func normalizeAlert(alert *Alert) {
 	alert.CoinID = strings.ToLower(strings.TrimSpace(alert.CoinID))
 	alert.Condition = strings.ToLower(strings.TrimSpace(alert.Condition))
+	alert.Window = strings.ToLower(strings.TrimSpace(alert.Window))
+	alert.Repeat = strings.ToLower(strings.TrimSpace(alert.Repeat))
+	alert.Cooldown = strings.ToLower(strings.TrimSpace(alert.Cooldown))
 	if alert.Currency == "" {
 		alert.Currency = "usd"
 	} else {
@@ -48,28 +331,90 @@ func normalizeAlert(alert *Alert) {
 }
 
 func (am *AlertManager) AddAlert(alert Alert) error {
-	if alert.Price <= 0 {
-		return fmt.Errorf("price must be greater than zero")
+	normalizeAlert(&alert)
+	if err := alert.Validate(); err != nil {
+		return err
 	}
-	if alert.Condition != "above" && alert.Condition != "below" {
-		return fmt.Errorf("condition must be 'above' or 'below'")
+	if alert.Expired(time.Now()) {
+		return fmt.Errorf("expiry %s is in the past", alert.ExpiresAt.Format("2006-01-02 15:04"))
 	}
 
+	return am.update(func() error {
+		for _, existingAlert := range am.alerts {
+			if existingAlert.sameCondition(alert) {
+				return fmt.Errorf("alert already exists for %s: %s (%s)",
+					alert.CoinID, alert.Describe(""), strings.ToUpper(alert.Currency))
+			}
+		}
+		alert.CreatedAt = time.Now()
+		am.alerts = append(am.alerts, alert)
+		return nil
+	})
+}
+
+// MarkTriggered records that alert fired at the given time: once alerts are
+// removed, recurring alerts start their cooldown and rearm alerts are
+// disarmed.
+func (am *AlertManager) MarkTriggered(alert Alert, at time.Time) error {
 	normalizeAlert(&alert)
+	return am.update(func() error {
+		i := am.indexOf(alert)
+		if i < 0 {
+			return fmt.Errorf("triggered alert not found for coin: %s", alert.CoinID)
+		}
+		switch am.alerts[i].RepeatMode() {
+		case RepeatRecurring:
+			am.alerts[i].LastTriggeredAt = &at
+		case RepeatRearm:
+			am.alerts[i].LastTriggeredAt = &at
+			am.alerts[i].Disarmed = true
+		default:
+			am.alerts = append(am.alerts[:i], am.alerts[i+1:]...)
+		}
+		return nil
+	})
+}
 
-	for _, existingAlert := range am.alerts {
-		if existingAlert.CoinID == alert.CoinID &&
-			existingAlert.Price == alert.Price &&
-			existingAlert.Condition == alert.Condition &&
-			existingAlert.Currency == alert.Currency {
-			return fmt.Errorf("alert already exists for %s at %.2f %s (%s)",
-				alert.CoinID, alert.Price, alert.Condition, strings.ToUpper(alert.Currency))
+// RearmAlert lets a disarmed rearm alert fire again.
+func (am *AlertManager) RearmAlert(alert Alert) error {
+	normalizeAlert(&alert)
+	return am.update(func() error {
+		i := am.indexOf(alert)
+		if i < 0 {
+			return fmt.Errorf("alert not found for %s: %s", alert.CoinID, alert.Describe(""))
 		}
+		am.alerts[i].Disarmed = false
+		return nil
+	})
+}
+
+// RemoveExpiredAlerts removes alerts whose expiry has passed at now and
+// returns them.
+func (am *AlertManager) RemoveExpiredAlerts(now time.Time) ([]Alert, error) {
+	am.mu.Lock()
+	anyExpired := false
+	for _, alert := range am.alerts {
+		anyExpired = anyExpired || alert.Expired(now)
+	}
+	am.mu.Unlock()
+	if !anyExpired {
+		return nil, nil
 	}
 
-	alert.CreatedAt = time.Now()
-	am.alerts = append(am.alerts, alert)
-	return am.Save()
+	var expired []Alert
+	err := am.update(func() error {
+		var remaining []Alert
+		for _, alert := range am.alerts {
+			if alert.Expired(now) {
+				expired = append(expired, alert)
+				continue
+			}
+			remaining = append(remaining, alert)
+		}
+		am.alerts = remaining
+		return nil
+	})
+	return expired, err
 }
 
 func (am *AlertManager) RemoveAlert(coinID string) error {
@@ -80,36 +425,61 @@ func (am *AlertManager) RemoveAlert(coinID string) error {
 func (am *AlertManager) RemoveAlertsForCoin(coinID string) error {
 	coinID = strings.ToLower(strings.TrimSpace(coinID))
 
-	var remaining []Alert
-	removed := false
-	for _, alert := range am.alerts {
-		if alert.CoinID == coinID {
-			removed = true
-			continue
+	return am.update(func() error {
+		var remaining []Alert
+		removed := false
+		for _, alert := range am.alerts {
+			if alert.CoinID == coinID {
+				removed = true
+				continue
+			}
+			remaining = append(remaining, alert)
 		}
-		remaining = append(remaining, alert)
-	}
-	if !removed {
-		return fmt.Errorf("alert not found for coin: %s", coinID)
-	}
-
-	am.alerts = remaining
-	return am.Save()
+		if !removed {
+			return fmt.Errorf("alert not found for coin: %s", coinID)
+		}
+		am.alerts = remaining
+		return nil
+	})
 }
 
 func (am *AlertManager) RemoveTriggeredAlert(alert Alert) error {
 	normalizeAlert(&alert)
+	return am.update(func() error {
+		if !am.removeMatching(alert) {
+			return fmt.Errorf("triggered alert not found for coin: %s", alert.CoinID)
+		}
+		return nil
+	})
+}
 
+// RemoveMatchingAlert removes the alert watching the same condition as alert.
+func (am *AlertManager) RemoveMatchingAlert(alert Alert) error {
+	normalizeAlert(&alert)
+	return am.update(func() error {
+		if !am.removeMatching(alert) {
+			return fmt.Errorf("alert not found for %s: %s", alert.CoinID, alert.Describe(""))
+		}
+		return nil
+	})
+}
+
+func (am *AlertManager) removeMatching(alert Alert) bool {
+	i := am.indexOf(alert)
+	if i < 0 {
+		return false
+	}
+	am.alerts = append(am.alerts[:i], am.alerts[i+1:]...)
+	return true
+}
+
+func (am *AlertManager) indexOf(alert Alert) int {
 	for i, existingAlert := range am.alerts {
-		if existingAlert.CoinID == alert.CoinID &&
-			existingAlert.Price == alert.Price &&
-			existingAlert.Condition == alert.Condition &&
-			existingAlert.Currency == alert.Currency {
-			am.alerts = append(am.alerts[:i], am.alerts[i+1:]...)
-			return am.Save()
+		if existingAlert.sameCondition(alert) {
+			return i
 		}
 	}
-	return fmt.Errorf("triggered alert not found for coin: %s", alert.CoinID)
+	return -1
 }
 
 // RemoveAlertByTarget removes a specific alert by coin, price, and condition.
@@ -117,23 +487,27 @@ func (am *AlertManager) RemoveAlertByTarget(coinID string, price float64, condit
 	coinID = strings.ToLower(strings.TrimSpace(coinID))
 	condition = strings.ToLower(strings.TrimSpace(condition))
 
-	var remaining []Alert
-	removed := false
-	for _, alert := range am.alerts {
-		if alert.CoinID == coinID && alert.Price == price && alert.Condition == condition {
-			removed = true
-			continue
+	return am.update(func() error {
+		var remaining []Alert
+		removed := false
+		for _, alert := range am.alerts {
+			if alert.CoinID == coinID && alert.Price == price && alert.Condition == condition {
+				removed = true
+				continue
+			}
+			remaining = append(remaining, alert)
 		}
-		remaining = append(remaining, alert)
-	}
-	if !removed {
-		return fmt.Errorf("alert not found for %s at %.2f %s", coinID, price, condition)
-	}
-	am.alerts = remaining
-	return am.Save()
+		if !removed {
+			return fmt.Errorf("alert not found for %s at %.2f %s", coinID, price, condition)
+		}
+		am.alerts = remaining
+		return nil
+	})
 }
 
 func (am *AlertManager) GetAlerts() []Alert {
+	am.mu.Lock()
+	defer am.mu.Unlock()
 	if len(am.alerts) == 0 {
 		return nil
 	}
@@ -141,36 +515,100 @@ func (am *AlertManager) GetAlerts() []Alert {
 }
 
 func (am *AlertManager) Load() error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	unlock, err := lockFile(am.alertFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	migrate, err := am.read()
+	if err != nil || !migrate {
+		return err
+	}
+	return am.write()
+}
+
+// ReloadIfChanged reloads the alerts when another process has changed the
+// file since it was last read or written, and reports whether it did.
+func (am *AlertManager) ReloadIfChanged() (bool, error) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	current, err := statFileVersion(am.alertFile)
+	if err != nil || current.same(am.version) {
+		return false, err
+	}
+	unlock, err := lockFile(am.alertFile)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	if _, err := am.read(); err != nil {
+		// Keep the current alerts and report a broken file only once
+		am.version = current
+		return false, err
+	}
+	return true, nil
+}
+
+// update runs a read-modify-write cycle under the file lock: the alerts are
+// reloaded, change applied and the result saved, so writes by the CLI and
+// the daemon don't overwrite each other. Nothing is saved when change fails.
+func (am *AlertManager) update(change func() error) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	unlock, err := lockFile(am.alertFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := am.read(); err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return am.write()
+}
+
+// read loads the alerts file and reports whether it needs migrating to the
+// current format. A missing file means no alerts.
+func (am *AlertManager) read() (bool, error) {
+	version, err := statFileVersion(am.alertFile)
+	if err != nil {
+		return false, err
+	}
 	data, err := os.ReadFile(am.alertFile)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return nil
+			am.alerts = make([]Alert, 0)
+			am.version = fileVersion{}
+			return false, nil
 		}
-		return err
+		return false, err
 	}
 
 	// Try to load new format first
 	var alerts []Alert
-	err = json.Unmarshal(data, &alerts)
-	if err == nil {
-		am.alerts = alerts
-		am.normalizeLoadedAlerts()
-		if am.needsMigration(alerts) {
-			return am.Save()
+	migrate := false
+	if err := json.Unmarshal(data, &alerts); err != nil {
+		// Try to load old format
+		var alertData AlertData
+		if err := json.Unmarshal(data, &alertData); err != nil {
+			return false, err
 		}
-		return nil
-	}
-
-	// Try to load old format
-	var alertData AlertData
-	err = json.Unmarshal(data, &alertData)
-	if err != nil {
-		return err
+		alerts = alertData.Alerts
+		migrate = true
+	} else {
+		migrate = am.needsMigration(alerts)
 	}
 
-	am.alerts = alertData.Alerts
+	am.alerts = alerts
 	am.normalizeLoadedAlerts()
-	return am.Save()
+	am.version = version
+	return migrate, nil
 }
 
 func (am *AlertManager) needsMigration(alerts []Alert) bool {
@@ -189,9 +627,24 @@ func (am *AlertManager) normalizeLoadedAlerts() {
 }
 
 func (am *AlertManager) Save() error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	unlock, err := lockFile(am.alertFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return am.write()
+}
+
+func (am *AlertManager) write() error {
 	data, err := json.MarshalIndent(am.alerts, "", "  ")
 	if err != nil {
 		return err
 	}
-	return atomicWriteFile(am.alertFile, data)
+	if err := atomicWriteFile(am.alertFile, data); err != nil {
+		return err
+	}
+	am.version, err = statFileVersion(am.alertFile)
+	return err
 }
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const alertHistoryFileName = "alert_history.jsonl"
+
+// Trigger sources: the foreground watcher or the background daemon.
+const (
+	TriggerSourceWatch  = "watch"
+	TriggerSourceDaemon = "daemon"
+)
+
+// AlertTrigger records one firing of an alert.
+type AlertTrigger struct {
+	Alert Alert `json:"alert"`
+	// Price is the coin price that triggered the alert; for portfolio
+	// alerts it is the portfolio value or holding weight instead.
+	Price       float64   `json:"price"`
+	Message     string    `json:"message"`
+	Source      string    `json:"source"`
+	TriggeredAt time.Time `json:"triggered_at"`
+}
+
+// AlertHistoryFilter selects triggers by coin and time. Zero values match
+// everything.
+type AlertHistoryFilter struct {
+	CoinID string
+	Since  time.Time
+}
+
+// AlertHistory is an append-only log of alert triggers, one JSON object
+// per line.
+type AlertHistory struct {
+	historyFile string
+}
+
+func NewAlertHistory(configDir string) *AlertHistory {
+	return &AlertHistory{historyFile: filepath.Join(configDir, alertHistoryFileName)}
+}
+
+func (h *AlertHistory) Append(trigger AlertTrigger) error {
+	data, err := json.Marshal(trigger)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(h.historyFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, privateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Query returns the matching triggers, oldest first. Lines that cannot be
+// parsed, such as one cut short by a crash, are skipped.
+func (h *AlertHistory) Query(filter AlertHistoryFilter) ([]AlertTrigger, error) {
+	file, err := os.Open(h.historyFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	coinID := strings.ToLower(strings.TrimSpace(filter.CoinID))
+	var triggers []AlertTrigger
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var trigger AlertTrigger
+		if err := json.Unmarshal(scanner.Bytes(), &trigger); err != nil {
+			continue
+		}
+		if coinID != "" && trigger.Alert.CoinID != coinID {
+			continue
+		}
+		if trigger.TriggeredAt.Before(filter.Since) {
+			continue
+		}
+		triggers = append(triggers, trigger)
+	}
+	return triggers, scanner.Err()
+}
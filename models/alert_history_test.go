@@ -0,0 +1,61 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAlertHistory_AppendAndQuery(t *testing.T) {
+	dir := t.TempDir()
+	history := NewAlertHistory(dir)
+
+	if triggers, err := history.Query(AlertHistoryFilter{}); err != nil || triggers != nil {
+		t.Fatalf("Query() on missing file = %v, %v", triggers, err)
+	}
+
+	base := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []AlertTrigger{
+		{Alert: Alert{CoinID: "bitcoin", Condition: ConditionAbove, Price: 100}, Price: 101, Source: TriggerSourceWatch, TriggeredAt: base},
+		{Alert: Alert{CoinID: "ethereum", Condition: ConditionBelow, Price: 10}, Price: 9, Source: TriggerSourceDaemon, TriggeredAt: base.Add(time.Hour)},
+		{Alert: Alert{CoinID: "bitcoin", Condition: ConditionChange, Percent: 5, Window: "24h"}, Price: 110, Source: TriggerSourceDaemon, TriggeredAt: base.Add(2 * time.Hour)},
+	}
+	for _, entry := range entries {
+		if err := history.Append(entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	all, err := history.Query(AlertHistoryFilter{})
+	if err != nil || len(all) != 3 || all[2].Alert.Window != "24h" || all[1].Source != TriggerSourceDaemon {
+		t.Fatalf("Query() = %+v, %v", all, err)
+	}
+
+	filtered, err := history.Query(AlertHistoryFilter{CoinID: "Bitcoin", Since: base.Add(30 * time.Minute)})
+	if err != nil || len(filtered) != 1 || filtered[0].Price != 110 {
+		t.Fatalf("filtered Query() = %+v, %v", filtered, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, alertHistoryFileName))
+	if err != nil || info.Mode().Perm() != privateFileMode {
+		t.Fatalf("history file mode = %v, %v", info.Mode().Perm(), err)
+	}
+}
+
+func TestAlertHistory_SkipsTruncatedLines(t *testing.T) {
+	dir := t.TempDir()
+	history := NewAlertHistory(dir)
+	_ = history.Append(AlertTrigger{Alert: Alert{CoinID: "bitcoin"}, Price: 1})
+	file, err := os.OpenFile(filepath.Join(dir, alertHistoryFileName), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"alert":{"coin_id":"eth`)
+	_ = file.Close()
+
+	triggers, err := history.Query(AlertHistoryFilter{})
+	if err != nil || len(triggers) != 1 {
+		t.Fatalf("Query() = %+v, %v", triggers, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestAlertManager_AddAndRemoveAllForCoin(t *testing.T) {
@@ -126,3 +127,238 @@ func TestAlertManager_RemoveAlertByTarget(t *testing.T) {
 		t.Fatalf("expected 1 alert remaining, got %d", len(manager.GetAlerts()))
 	}
 }
+
+func TestAlert_ValidateConditions(t *testing.T) {
+	valid := []Alert{
+		{Condition: ConditionChange, Percent: -5, Window: "1h"},
+		{Condition: ConditionCrossAbove, MAPeriod: 50},
+		{Condition: ConditionVolumeSpike, Multiplier: 3, Window: "7d"},
+		{Condition: ConditionRankChange, Rank: 4, RankMove: 1},
+		{Condition: ConditionRangeExit, Low: 10, High: 20},
+		{Condition: ConditionValueBelow, Price: 5000},
+		{Condition: ConditionDailyPnL, Percent: 5},
+		{Condition: ConditionWeightAbove, Percent: 40},
+	}
+	for _, alert := range valid {
+		if err := alert.Validate(); err != nil {
+			t.Fatalf("Validate(%+v) error = %v", alert, err)
+		}
+	}
+
+	invalid := []Alert{
+		{Condition: ConditionChange, Percent: 0, Window: "24h"},
+		{Condition: ConditionChange, Percent: 5, Window: "soon"},
+		{Condition: ConditionCrossBelow, MAPeriod: 1},
+		{Condition: ConditionVolumeSpike, Multiplier: 0.5, Window: "7d"},
+		{Condition: ConditionVolumeSpike, Multiplier: 2, Window: "12h"},
+		{Condition: ConditionRankChange, RankMove: 1},
+		{Condition: ConditionRangeExit, Low: 20, High: 10},
+		{Condition: ConditionValueAbove},
+		{Condition: ConditionDailyPnL, Percent: -5},
+		{Condition: ConditionWeightAbove, Percent: 100},
+	}
+	for _, alert := range invalid {
+		if err := alert.Validate(); err == nil {
+			t.Fatalf("Validate(%+v) should fail", alert)
+		}
+	}
+}
+
+func TestParseAlertWindow(t *testing.T) {
+	cases := map[string]time.Duration{"1h": time.Hour, "24H": 24 * time.Hour, "7d": 7 * 24 * time.Hour, "2w": 14 * 24 * time.Hour}
+	for in, want := range cases {
+		if got, err := ParseAlertWindow(in); err != nil || got != want {
+			t.Fatalf("ParseAlertWindow(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"", "h", "0d", "5m", "-1h"} {
+		if _, err := ParseAlertWindow(in); err == nil {
+			t.Fatalf("ParseAlertWindow(%q) should fail", in)
+		}
+	}
+}
+
+func TestAlertManager_TypedAlertsPersistAndDeduplicate(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewAlertManager(dir)
+	change := Alert{CoinID: "bitcoin", Condition: ConditionChange, Percent: 10, Window: "24h", Currency: "usd"}
+	if err := manager.AddAlert(change); err != nil {
+		t.Fatalf("AddAlert() error = %v", err)
+	}
+	if err := manager.AddAlert(change); err == nil {
+		t.Fatal("expected duplicate change alert to be rejected")
+	}
+	longer := change
+	longer.Window = "7d"
+	if err := manager.AddAlert(longer); err != nil {
+		t.Fatalf("AddAlert(7d) error = %v", err)
+	}
+
+	reloaded := NewAlertManager(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	alerts := reloaded.GetAlerts()
+	if len(alerts) != 2 || alerts[0].Percent != 10 || alerts[1].Window != "7d" {
+		t.Fatalf("unexpected alerts after reload: %+v", alerts)
+	}
+
+	if err := reloaded.RemoveMatchingAlert(change); err != nil {
+		t.Fatalf("RemoveMatchingAlert() error = %v", err)
+	}
+	if got := reloaded.GetAlerts(); len(got) != 1 || got[0].Window != "7d" {
+		t.Fatalf("unexpected alerts after remove: %+v", got)
+	}
+}
+
+func TestAlert_Describe(t *testing.T) {
+	cases := map[string]Alert{
+		"above $100.00":        {Condition: ConditionAbove, Price: 100},
+		"-5% in 1h":            {Condition: ConditionChange, Percent: -5, Window: "1h"},
+		"crosses above 50d MA": {Condition: ConditionCrossAbove, MAPeriod: 50},
+		"volume 3x 7d avg":     {Condition: ConditionVolumeSpike, Multiplier: 3, Window: "7d"},
+		"rank moves 2 from #4": {Condition: ConditionRankChange, Rank: 4, RankMove: 2},
+		"leaves $1.00-$2.00":   {Condition: ConditionRangeExit, Low: 1, High: 2},
+		"value above $5.00":    {Condition: ConditionValueAbove, Price: 5},
+		"daily P&L beyond ±5%": {Condition: ConditionDailyPnL, Percent: 5},
+		"weight above 40%":     {Condition: ConditionWeightAbove, Percent: 40},
+	}
+	for want, alert := range cases {
+		if got := alert.Describe("$"); got != want {
+			t.Fatalf("Describe() = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestAlertManager_RepeatModes(t *testing.T) {
+	manager := NewAlertManager(t.TempDir())
+	once := Alert{CoinID: "bitcoin", Price: 100, Condition: ConditionAbove}
+	recurring := Alert{CoinID: "bitcoin", Price: 90, Condition: ConditionBelow, Repeat: RepeatRecurring, Cooldown: "1h"}
+	rearm := Alert{CoinID: "ethereum", Price: 10, Condition: ConditionAbove, Repeat: RepeatRearm, Hysteresis: 2}
+	for _, alert := range []Alert{once, recurring, rearm} {
+		if err := manager.AddAlert(alert); err != nil {
+			t.Fatalf("AddAlert(%+v) error = %v", alert, err)
+		}
+	}
+
+	now := time.Now()
+	for _, alert := range []Alert{once, recurring, rearm} {
+		if err := manager.MarkTriggered(alert, now); err != nil {
+			t.Fatalf("MarkTriggered(%+v) error = %v", alert, err)
+		}
+	}
+	alerts := manager.GetAlerts()
+	if len(alerts) != 2 {
+		t.Fatalf("once alert should be removed, got %+v", alerts)
+	}
+	if !alerts[0].InCooldown(now.Add(59*time.Minute)) || alerts[0].InCooldown(now.Add(time.Hour)) {
+		t.Fatalf("unexpected cooldown for %+v", alerts[0])
+	}
+	if !alerts[1].Disarmed || alerts[1].LastTriggeredAt == nil {
+		t.Fatalf("rearm alert should be disarmed, got %+v", alerts[1])
+	}
+
+	if err := manager.RearmAlert(rearm); err != nil {
+		t.Fatalf("RearmAlert() error = %v", err)
+	}
+	if manager.GetAlerts()[1].Disarmed {
+		t.Fatal("alert should be armed again")
+	}
+}
+
+func TestAlertManager_RemoveExpiredAlerts(t *testing.T) {
+	manager := NewAlertManager(t.TempDir())
+	now := time.Now()
+	soon, later := now.Add(time.Minute), now.Add(time.Hour)
+	_ = manager.AddAlert(Alert{CoinID: "bitcoin", Price: 100, Condition: ConditionAbove, ExpiresAt: &soon})
+	_ = manager.AddAlert(Alert{CoinID: "bitcoin", Price: 200, Condition: ConditionAbove, ExpiresAt: &later})
+
+	past := now.Add(-time.Minute)
+	if err := manager.AddAlert(Alert{CoinID: "bitcoin", Price: 300, Condition: ConditionAbove, ExpiresAt: &past}); err == nil {
+		t.Fatal("expected an already expired alert to be rejected")
+	}
+
+	expired, err := manager.RemoveExpiredAlerts(now.Add(30 * time.Minute))
+	if err != nil || len(expired) != 1 || expired[0].Price != 100 {
+		t.Fatalf("RemoveExpiredAlerts() = %+v, %v", expired, err)
+	}
+	if alerts := manager.GetAlerts(); len(alerts) != 1 || alerts[0].Price != 200 {
+		t.Fatalf("unexpected alerts after expiry: %+v", alerts)
+	}
+}
+
+func TestAlert_ValidateRepeat(t *testing.T) {
+	invalid := []Alert{
+		{Condition: ConditionAbove, Price: 1, Repeat: "daily"},
+		{Condition: ConditionAbove, Price: 1, Repeat: RepeatRecurring},
+		{Condition: ConditionAbove, Price: 1, Cooldown: "1h"},
+		{Condition: ConditionAbove, Price: 1, Hysteresis: 2},
+		{Condition: ConditionAbove, Price: 1, Repeat: RepeatRearm, Hysteresis: -1},
+	}
+	for _, alert := range invalid {
+		if err := alert.Validate(); err == nil {
+			t.Fatalf("Validate(%+v) should fail", alert)
+		}
+	}
+}
+
+func TestAlertManager_ConcurrentWritersKeepEachOthersAlerts(t *testing.T) {
+	dir := t.TempDir()
+	daemon := NewAlertManager(dir)
+	_ = daemon.AddAlert(Alert{CoinID: "bitcoin", Condition: ConditionAbove, Price: 50000})
+
+	// The CLI adds an alert while the daemon still holds its old view
+	cli := NewAlertManager(dir)
+	if err := cli.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.AddAlert(Alert{CoinID: "ethereum", Condition: ConditionBelow, Price: 2000}); err != nil {
+		t.Fatal(err)
+	}
+	if err := daemon.MarkTriggered(Alert{CoinID: "bitcoin", Condition: ConditionAbove, Price: 50000}, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewAlertManager(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	alerts := reloaded.GetAlerts()
+	if len(alerts) != 1 || alerts[0].CoinID != "ethereum" {
+		t.Fatalf("expected the CLI's alert to survive the trigger, got %+v", alerts)
+	}
+}
+
+func TestAlertManager_ReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	daemon := NewAlertManager(dir)
+	if changed, err := daemon.ReloadIfChanged(); changed || err != nil {
+		t.Fatalf("ReloadIfChanged() without a file = %v, %v", changed, err)
+	}
+
+	// Its own writes are not changes
+	_ = daemon.AddAlert(Alert{CoinID: "bitcoin", Condition: ConditionAbove, Price: 50000})
+	if changed, err := daemon.ReloadIfChanged(); changed || err != nil {
+		t.Fatalf("ReloadIfChanged() after own write = %v, %v", changed, err)
+	}
+
+	cli := NewAlertManager(dir)
+	_ = cli.AddAlert(Alert{CoinID: "ethereum", Condition: ConditionBelow, Price: 2000})
+	if changed, err := daemon.ReloadIfChanged(); !changed || err != nil || len(daemon.GetAlerts()) != 2 {
+		t.Fatalf("ReloadIfChanged() = %v, %v with %d alerts", changed, err, len(daemon.GetAlerts()))
+	}
+	if changed, _ := daemon.ReloadIfChanged(); changed {
+		t.Fatal("expected no change after reloading")
+	}
+
+	// A broken file is reported once and the alerts are kept
+	if err := os.WriteFile(filepath.Join(dir, "alerts.json"), []byte("{broken"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := daemon.ReloadIfChanged(); err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if changed, err := daemon.ReloadIfChanged(); changed || err != nil || len(daemon.GetAlerts()) != 2 {
+		t.Fatalf("ReloadIfChanged() after error = %v, %v with %d alerts", changed, err, len(daemon.GetAlerts()))
+	}
+}
@@ -80,6 +80,13 @@ type OHLC struct {
 	Close float64 `json:"close"`
 }
 
+// MarketChart holds the market_chart series as [timestamp_ms, value] pairs.
+type MarketChart struct {
+	Prices       [][]float64 `json:"prices"`
+	MarketCaps   [][]float64 `json:"market_caps"`
+	TotalVolumes [][]float64 `json:"total_volumes"`
+}
+
 func UnmarshalSearch(data []byte) (SearchResponse, error) {
 	var r SearchResponse
 	err := json.Unmarshal(data, &r)
@@ -2,8 +2,11 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 // AppConfig holds user preferences stored in ~/.crypto/config.json.
@@ -11,13 +14,122 @@ type AppConfig struct {
 	Currency            string `json:"currency,omitempty"`
 	ChartWidth          int    `json:"chart_width,omitempty"`
 	ChartHeight         int    `json:"chart_height,omitempty"`
+	ChartStyle          string `json:"chart_style,omitempty"`
 	AlertCheckIntervalM int    `json:"alert_check_interval_minutes,omitempty"`
 	NoColor             bool   `json:"no_color,omitempty"`
+
+	AlertStream   AlertStreamConfig  `json:"alert_stream"`
+	Notifications NotificationConfig `json:"notifications"`
+	Log           LogConfig          `json:"log"`
+	Metrics       MetricsConfig      `json:"metrics"`
+}
+
+// MetricsConfig enables the alert daemon's Prometheus endpoint.
+type MetricsConfig struct {
+	// Listen is the address /metrics is served on, e.g. "127.0.0.1:9464".
+	// Empty disables the endpoint.
+	Listen string `json:"listen,omitempty"`
+}
+
+// Log formats of the alert daemon.
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+const (
+	defaultLogMaxSizeMB = 10
+	defaultLogMaxFiles  = 5
+)
+
+// LogConfig configures the alert daemon's log.
+type LogConfig struct {
+	// Level is debug, info (default), warn or error.
+	Level string `json:"level,omitempty"`
+	// Format is text (default) or json.
+	Format string `json:"format,omitempty"`
+	// MaxSizeMB is the size alert.log is rotated at; the default is 10.
+	MaxSizeMB int `json:"max_size_mb,omitempty"`
+	// MaxFiles is how many rotated logs are kept; the default is 5.
+	MaxFiles int `json:"max_files,omitempty"`
+}
+
+func (lc LogConfig) FormatOrDefault() string {
+	if lc.Format != "" {
+		return strings.ToLower(lc.Format)
+	}
+	return LogFormatText
+}
+
+func (lc LogConfig) MaxSizeOrDefault() int64 {
+	if lc.MaxSizeMB > 0 {
+		return int64(lc.MaxSizeMB) << 20
+	}
+	return defaultLogMaxSizeMB << 20
+}
+
+func (lc LogConfig) MaxFilesOrDefault() int {
+	if lc.MaxFiles > 0 {
+		return lc.MaxFiles
+	}
+	return defaultLogMaxFiles
+}
+
+// AlertStreamConfig enables real-time evaluation of price alerts from an
+// exchange ticker stream.
+type AlertStreamConfig struct {
+	Enabled bool `json:"enabled,omitempty"`
+	// URL is a Binance-compatible stream endpoint; the default is Binance.
+	URL string `json:"url,omitempty"`
+}
+
+// NotificationConfig lists where triggered alerts are sent besides the
+// terminal.
+type NotificationConfig struct {
+	Webhooks []WebhookConfig `json:"webhooks,omitempty"`
+	Email    *EmailConfig    `json:"email,omitempty"`
+	// Desktop enables desktop notifications when present.
+	Desktop  *DesktopConfig   `json:"desktop,omitempty"`
+	Exec     []ExecHookConfig `json:"exec,omitempty"`
+	Telegram *TelegramConfig  `json:"telegram,omitempty"`
+}
+
+// Webhook payload formats.
+const (
+	WebhookFormatJSON    = "json"
+	WebhookFormatSlack   = "slack"
+	WebhookFormatDiscord = "discord"
+)
+
+const defaultWebhookRetries = 2
+
+// WebhookConfig configures an HTTP webhook for triggered alerts.
+type WebhookConfig struct {
+	URL string `json:"url"`
+	// Format is json (default), slack or discord.
+	Format string `json:"format,omitempty"`
+	// Template is a Go text/template for the JSON body of the json format.
+	Template string            `json:"template,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	// Retries is the number of retries after a failed delivery; nil means
+	// the default of 2.
+	Retries *int `json:"retries,omitempty"`
+	// Secret, when set, signs each request with HMAC-SHA256.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (wc WebhookConfig) RetriesOrDefault() int {
+	if wc.Retries != nil && *wc.Retries >= 0 {
+		return *wc.Retries
+	}
+	return defaultWebhookRetries
 }
 
 type ConfigStore struct {
 	filePath string
 	Config   AppConfig
+	// version is the file as last read or written.
+	version fileVersion
 }
 
 func NewConfigStore(configDir string) *ConfigStore {
@@ -25,6 +137,10 @@ func NewConfigStore(configDir string) *ConfigStore {
 }
 
 func (cs *ConfigStore) Load() error {
+	version, err := statFileVersion(cs.filePath)
+	if err != nil {
+		return err
+	}
 	data, err := os.ReadFile(cs.filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -32,15 +148,75 @@ func (cs *ConfigStore) Load() error {
 		}
 		return err
 	}
-	return json.Unmarshal(data, &cs.Config)
+	if err := json.Unmarshal(data, &cs.Config); err != nil {
+		return err
+	}
+	cs.version = version
+	return nil
+}
+
+// ReloadIfChanged replaces Config with the file contents when the file has
+// changed since it was last read or written, and reports whether it did.
+// When the file cannot be parsed the current Config is kept.
+func (cs *ConfigStore) ReloadIfChanged() (bool, error) {
+	current, err := statFileVersion(cs.filePath)
+	if err != nil || current.same(cs.version) {
+		return false, err
+	}
+	reloaded := &ConfigStore{filePath: cs.filePath}
+	if err := reloaded.Load(); err != nil {
+		// Report a broken file once rather than on every check
+		cs.version = current
+		return false, err
+	}
+	cs.Config = reloaded.Config
+	cs.version = reloaded.version
+	return true, nil
 }
 
+// Save writes Config over the file. To change some settings, use Update,
+// which keeps changes other processes made since Config was read.
 func (cs *ConfigStore) Save() error {
+	unlock, err := lockFile(cs.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return cs.write()
+}
+
+// Update runs a read-modify-write cycle under the file lock: the file is
+// reloaded into Config, change applied and the result saved, so settings
+// changed by the CLI and the daemon don't overwrite each other. Nothing is
+// saved when change fails.
+func (cs *ConfigStore) Update(change func(*AppConfig) error) error {
+	unlock, err := lockFile(cs.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	current := &ConfigStore{filePath: cs.filePath}
+	if err := current.Load(); err != nil {
+		return err
+	}
+	if err := change(&current.Config); err != nil {
+		return err
+	}
+	cs.Config = current.Config
+	return cs.write()
+}
+
+func (cs *ConfigStore) write() error {
 	data, err := json.MarshalIndent(cs.Config, "", "  ")
 	if err != nil {
 		return err
 	}
-	return atomicWriteFile(cs.filePath, data)
+	if err := atomicWriteFile(cs.filePath, data); err != nil {
+		return err
+	}
+	cs.version, err = statFileVersion(cs.filePath)
+	return err
 }
 
 func (cs *ConfigStore) CurrencyOrDefault(defaultCurrency string) string {
@@ -64,9 +240,110 @@ func (cs *ConfigStore) ChartHeightOrDefault(defaultHeight int) int {
 	return defaultHeight
 }
 
+func (cs *ConfigStore) ChartStyleOrDefault(defaultStyle string) string {
+	if cs.Config.ChartStyle != "" {
+		return cs.Config.ChartStyle
+	}
+	return defaultStyle
+}
+
 func (cs *ConfigStore) AlertIntervalOrDefault(defaultMinutes int) int {
 	if cs.Config.AlertCheckIntervalM > 0 {
 		return cs.Config.AlertCheckIntervalM
 	}
 	return defaultMinutes
 }
+
+// SMTP connection security modes.
+const (
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+	SMTPSecurityNone     = "none"
+)
+
+// EmailConfig configures SMTP delivery of triggered alerts. All triggers of
+// one check cycle are sent in a single email.
+type EmailConfig struct {
+	Host string `json:"host"`
+	// Port defaults to 587 for starttls, 465 for tls and 25 for none.
+	Port int `json:"port,omitempty"`
+	// Security is starttls (default), tls for implicit TLS, or none.
+	Security string   `json:"security,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	// Subject and Body are Go text/templates; see the README for fields.
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body,omitempty"`
+}
+
+func (ec EmailConfig) SecurityOrDefault() string {
+	if ec.Security != "" {
+		return strings.ToLower(ec.Security)
+	}
+	return SMTPSecurityStartTLS
+}
+
+func (ec EmailConfig) PortOrDefault() int {
+	if ec.Port > 0 {
+		return ec.Port
+	}
+	switch ec.SecurityOrDefault() {
+	case SMTPSecurityTLS:
+		return 465
+	case SMTPSecurityNone:
+		return 25
+	}
+	return 587
+}
+
+// DesktopConfig configures desktop notifications.
+type DesktopConfig struct {
+	// Urgency is low, normal (default) or critical.
+	Urgency string `json:"urgency,omitempty"`
+	// TimeoutMS is how long the notification stays up; 0 leaves it to the
+	// notification server.
+	TimeoutMS int `json:"timeout_ms,omitempty"`
+}
+
+const defaultExecHookTimeout = 30 * time.Second
+
+// ExecHookConfig runs a shell command for each triggered alert.
+type ExecHookConfig struct {
+	Command string `json:"command"`
+	// Timeout is a Go duration such as "10s"; the default is 30s.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+func (ec ExecHookConfig) TimeoutOrDefault() (time.Duration, error) {
+	if ec.Timeout == "" {
+		return defaultExecHookTimeout, nil
+	}
+	d, err := time.ParseDuration(ec.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q", ec.Timeout)
+	}
+	return d, nil
+}
+
+// DefaultTelegramAPIURL is the public Telegram Bot API.
+const DefaultTelegramAPIURL = "https://api.telegram.org"
+
+// TelegramConfig sends triggered alerts to a Telegram chat through a bot.
+type TelegramConfig struct {
+	Token  string `json:"token"`
+	ChatID string `json:"chat_id"`
+	// APIURL is the Bot API base URL; the default is the public API.
+	APIURL string `json:"api_url,omitempty"`
+	// Commands makes the bot answer /price, /portfolio and /alerts from
+	// the chat while alerts are watched.
+	Commands bool `json:"commands,omitempty"`
+}
+
+func (tc TelegramConfig) APIURLOrDefault() string {
+	if tc.APIURL != "" {
+		return strings.TrimRight(tc.APIURL, "/")
+	}
+	return DefaultTelegramAPIURL
+}
@@ -9,21 +9,33 @@ import (
 	"syscall"
 )
 
-const alertPIDFileName = "alert.pid"
+const (
+	alertPIDFileName    = "alert.pid"
+	alertSocketFileName = "alert.sock"
+)
 
 // DaemonState manages background process PID file.
 type DaemonState struct {
-	pidFile string
+	pidFile    string
+	socketFile string
 }
 
 func NewDaemonState(configDir string) *DaemonState {
-	return &DaemonState{pidFile: filepath.Join(configDir, alertPIDFileName)}
+	return &DaemonState{
+		pidFile:    filepath.Join(configDir, alertPIDFileName),
+		socketFile: filepath.Join(configDir, alertSocketFileName),
+	}
 }
 
 func (d *DaemonState) PIDFile() string {
 	return d.pidFile
 }
 
+// SocketPath is the Unix socket the daemon accepts control requests on.
+func (d *DaemonState) SocketPath() string {
+	return d.socketFile
+}
+
 func (d *DaemonState) WritePID(pid int) error {
 	return atomicWriteFile(d.pidFile, []byte(strconv.Itoa(pid)))
 }
@@ -48,6 +60,8 @@ func (d *DaemonState) RemovePID() error {
 	return err
 }
 
+// IsRunning reports whether the PID file names a running alert daemon,
+// and the PID it holds.
 func (d *DaemonState) IsRunning() (bool, int) {
 	pid, err := d.ReadPID()
 	if err != nil {
@@ -57,6 +71,30 @@ func (d *DaemonState) IsRunning() (bool, int) {
 	if err != nil {
 		return false, pid
 	}
-	err = process.Signal(syscall.Signal(0))
-	return err == nil, pid
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return false, pid
+	}
+	return isAlertDaemon(pid), pid
+}
+
+// isAlertDaemon reports whether pid runs 'crypto alert watch --daemon', so
+// that a PID reused after the daemon died is not taken for it. Without
+// /proc the PID is trusted.
+func isAlertDaemon(pid int) bool {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		_, procErr := os.Stat("/proc/self")
+		return procErr != nil
+	}
+	args := strings.Split(strings.TrimRight(string(data), "\x00"), "\x00")
+	watch, daemon := false, false
+	for i, arg := range args {
+		if arg == "watch" && i > 0 && args[i-1] == "alert" {
+			watch = true
+		}
+		if arg == "--daemon" && watch {
+			daemon = true
+		}
+	}
+	return daemon
 }
@@ -2,6 +2,7 @@ package models
 
 import (
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 )
@@ -26,3 +27,31 @@ func TestDaemonState_WriteReadRemovePID(t *testing.T) {
 		t.Fatal("expected pid file removed")
 	}
 }
+
+func TestDaemonState_IsRunningChecksTheProcess(t *testing.T) {
+	if _, err := os.Stat("/proc/self/cmdline"); err != nil {
+		t.Skip("needs /proc")
+	}
+	d := NewDaemonState(t.TempDir())
+
+	// A PID reused by another process is not the daemon
+	_ = d.WritePID(os.Getpid())
+	if running, pid := d.IsRunning(); running || pid != os.Getpid() {
+		t.Fatalf("IsRunning() for the test process = %v, %d", running, pid)
+	}
+
+	// The extra words become the shell's arguments, as in the daemon's
+	// command line
+	daemon := exec.Command("/bin/sh", "-c", "sleep 10; true", "alert", "watch", "--daemon")
+	if err := daemon.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = daemon.Process.Kill()
+		_ = daemon.Wait()
+	}()
+	_ = d.WritePID(daemon.Process.Pid)
+	if running, _ := d.IsRunning(); !running {
+		t.Fatal("IsRunning() did not recognize the daemon")
+	}
+}
@@ -80,22 +80,24 @@ func (p *Portfolio) AddTransaction(t Transaction) error {
 
 	t.Date = time.Now()
 
-	if t.Type == "buy" {
-		p.Holdings[t.CoinID] += t.Amount
-	} else {
-		holding := p.Holdings[t.CoinID]
-		if t.Amount > holding+holdingDustThreshold {
-			return fmt.Errorf("insufficient balance")
+	return p.update(func() error {
+		if t.Type == "buy" {
+			p.Holdings[t.CoinID] += t.Amount
+		} else {
+			holding := p.Holdings[t.CoinID]
+			if t.Amount > holding+holdingDustThreshold {
+				return fmt.Errorf("insufficient balance")
+			}
+			p.Holdings[t.CoinID] = holding - t.Amount
+			if isEffectivelyZero(p.Holdings[t.CoinID]) {
+				delete(p.Holdings, t.CoinID)
+			}
 		}
-		p.Holdings[t.CoinID] = holding - t.Amount
-		if isEffectivelyZero(p.Holdings[t.CoinID]) {
-			delete(p.Holdings, t.CoinID)
-		}
-	}
 
-	p.pruneDustHoldings()
-	p.Transactions = append(p.Transactions, t)
-	return p.Save()
+		p.pruneDustHoldings()
+		p.Transactions = append(p.Transactions, t)
+		return nil
+	})
 }
 
 func (p *Portfolio) GetHolding(coinID string) float64 {
@@ -129,31 +131,36 @@ func (p *Portfolio) HasCoinData(coinID string) bool {
 
 // Clear removes all holdings and transactions.
 func (p *Portfolio) Clear() error {
-	p.Holdings = make(map[string]float64)
-	p.Transactions = []Transaction{}
-	return p.Save()
+	return p.update(func() error {
+		p.Holdings = make(map[string]float64)
+		p.Transactions = []Transaction{}
+		return nil
+	})
 }
 
 // RemoveCoin removes a coin from holdings and its transaction history.
 func (p *Portfolio) RemoveCoin(coinID string) error {
 	coinID = strings.ToLower(strings.TrimSpace(coinID))
-	delete(p.Holdings, coinID)
-	filtered := make([]Transaction, 0, len(p.Transactions))
-	for _, t := range p.Transactions {
-		if t.CoinID != coinID {
-			filtered = append(filtered, t)
+	return p.update(func() error {
+		delete(p.Holdings, coinID)
+		filtered := make([]Transaction, 0, len(p.Transactions))
+		for _, t := range p.Transactions {
+			if t.CoinID != coinID {
+				filtered = append(filtered, t)
+			}
 		}
-	}
-	p.Transactions = filtered
-	return p.Save()
+		p.Transactions = filtered
+		return nil
+	})
 }
 
 func (p *Portfolio) Save() error {
-	data, err := json.MarshalIndent(p, "", "  ")
+	unlock, err := lockFile(p.FilePath)
 	if err != nil {
 		return err
 	}
-	return atomicWriteFile(p.FilePath, data)
+	defer unlock()
+	return p.write()
 }
 
 func (p *Portfolio) Load() error {
@@ -162,20 +169,78 @@ func (p *Portfolio) Load() error {
 		return err
 	}
 	before := string(data)
-	if err := json.Unmarshal(data, p); err != nil {
+	if err := p.decode(data); err != nil {
 		return err
 	}
-	p.normalizeLoadedData()
 	after, err := json.MarshalIndent(p, "", "  ")
 	if err != nil {
 		return err
 	}
 	if string(after) != before {
-		return p.Save()
+		// Rewrite under the lock from the latest contents
+		return p.update(func() error { return nil })
+	}
+	return nil
+}
+
+// update applies change to the latest file contents and saves the result,
+// holding the file lock throughout so concurrent processes do not lose each
+// other's changes. A missing file is an empty portfolio.
+func (p *Portfolio) update(change func() error) error {
+	if p.FilePath == "" {
+		// An in-memory portfolio has no file to merge with
+		if err := change(); err != nil {
+			return err
+		}
+		return p.write()
+	}
+	unlock, err := lockFile(p.FilePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, err := os.ReadFile(p.FilePath)
+	switch {
+	case os.IsNotExist(err):
+		p.Holdings = make(map[string]float64)
+		p.Transactions = make([]Transaction, 0)
+	case err != nil:
+		return err
+	default:
+		if err := p.decode(data); err != nil {
+			return err
+		}
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return p.write()
+}
+
+// decode replaces the portfolio with data from the file.
+func (p *Portfolio) decode(data []byte) error {
+	var loaded Portfolio
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return err
 	}
+	p.Holdings = loaded.Holdings
+	p.Transactions = loaded.Transactions
+	if p.Transactions == nil {
+		p.Transactions = make([]Transaction, 0)
+	}
+	p.normalizeLoadedData()
 	return nil
 }
 
+func (p *Portfolio) write() error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicWriteFile(p.FilePath, data)
+}
+
 func (p *Portfolio) normalizeLoadedData() {
 	normalizedHoldings := make(map[string]float64)
 	for coinID, amount := range p.Holdings {
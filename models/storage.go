@@ -1,8 +1,14 @@
 package models
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
 )
 
 const privateFileMode = 0o600
@@ -35,3 +41,84 @@ func atomicWriteFile(path string, data []byte) error {
 	cleanup = func() {}
 	return nil
 }
+
+// ErrStoreLocked is returned when another process holds a store's lock for
+// longer than StoreLockTimeout.
+var ErrStoreLocked = errors.New("store is locked by another crypto process")
+
+// StoreLockTimeout bounds how long a store waits for another process to
+// finish its update.
+var StoreLockTimeout = 5 * time.Second
+
+const storeLockRetry = 20 * time.Millisecond
+
+// lockFile takes an exclusive advisory lock on "<path>.lock" and returns the
+// unlock function. It waits up to StoreLockTimeout for other processes to
+// release the lock. The data file itself cannot carry the lock because
+// atomicWriteFile replaces it. The lock file is left in place, since
+// removing it would let two processes hold locks on different files; it
+// holds the PID of the last holder for error messages.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, privateFileMode)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(StoreLockTimeout)
+	for {
+		err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			break
+		}
+		if err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+			_ = f.Close()
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			holder := ""
+			if data, readErr := os.ReadFile(path + ".lock"); readErr == nil && len(bytes.TrimSpace(data)) > 0 {
+				holder = fmt.Sprintf(" (held by PID %s)", bytes.TrimSpace(data))
+			}
+			_ = f.Close()
+			return nil, fmt.Errorf("%w: %s still locked after %s%s", ErrStoreLocked, filepath.Base(path), StoreLockTimeout, holder)
+		}
+		time.Sleep(storeLockRetry)
+	}
+
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+	}
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
+
+// fileVersion identifies the contents of a store file as last read or
+// written, so changes made by other processes can be noticed. The zero
+// value stands for a missing file.
+type fileVersion struct {
+	info os.FileInfo
+}
+
+func statFileVersion(path string) (fileVersion, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fileVersion{}, nil
+		}
+		return fileVersion{}, err
+	}
+	return fileVersion{info: info}, nil
+}
+
+// same reports whether both versions are the same file contents. Writes by
+// atomicWriteFile always change the inode; edits in place change the
+// modification time or size.
+func (v fileVersion) same(other fileVersion) bool {
+	if v.info == nil || other.info == nil {
+		return v.info == nil && other.info == nil
+	}
+	return os.SameFile(v.info, other.info) &&
+		v.info.ModTime().Equal(other.info.ModTime()) &&
+		v.info.Size() == other.info.Size()
+}
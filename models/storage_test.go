@@ -0,0 +1,195 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockFile_Exclusive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alerts.json")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan func())
+	go func() {
+		second, err := lockFile(path)
+		if err != nil {
+			t.Error(err)
+			close(acquired)
+			return
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock acquired while the first is held")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case second, ok := <-acquired:
+		if ok {
+			second()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second lock not acquired after unlock")
+	}
+}
+
+func TestLockFile_TimesOutWhileHeld(t *testing.T) {
+	original := StoreLockTimeout
+	t.Cleanup(func() { StoreLockTimeout = original })
+	StoreLockTimeout = 100 * time.Millisecond
+
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	_, err = lockFile(path)
+	if !errors.Is(err, ErrStoreLocked) {
+		t.Fatalf("lockFile() error = %v, want ErrStoreLocked", err)
+	}
+	holder := fmt.Sprintf("portfolio.json still locked after 100ms (held by PID %d)", os.Getpid())
+	if !strings.Contains(err.Error(), holder) {
+		t.Fatalf("lockFile() error = %q, want it to name the file and holder", err)
+	}
+
+	// Writes fail with the same error instead of waiting forever
+	p := NewPortfolio(path)
+	err = p.AddTransaction(Transaction{CoinID: "bitcoin", Amount: 1, Price: 100, Type: "buy"})
+	if !errors.Is(err, ErrStoreLocked) {
+		t.Fatalf("AddTransaction() error = %v, want ErrStoreLocked", err)
+	}
+}
+
+func TestPortfolio_ConcurrentWritersKeepEachOthersTransactions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "portfolio.json")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each writer stands in for a separate crypto process
+			p := NewPortfolio(path)
+			coinID := fmt.Sprintf("coin-%d", i)
+			if err := p.AddTransaction(Transaction{CoinID: coinID, Amount: 1, Price: 10, Type: "buy"}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	p := NewPortfolio(path)
+	if err := p.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Transactions) != 8 || len(p.Holdings) != 8 {
+		t.Fatalf("expected 8 transactions and holdings, got %d and %d", len(p.Transactions), len(p.Holdings))
+	}
+}
+
+func TestWatchlist_UpdatesMergeWithOtherProcesses(t *testing.T) {
+	dir := t.TempDir()
+	cli := NewWatchlist(dir)
+	tui := NewWatchlist(dir)
+	if err := cli.Add("bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+	// tui never reloaded, but its write starts from the file
+	if err := tui.Add("ethereum"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.Add("ethereum"); err == nil {
+		t.Fatal("expected adding a coin added by another process to fail")
+	}
+	if err := cli.Remove("bitcoin"); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := NewWatchlist(dir)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reloaded.CoinIDs) != 1 || reloaded.CoinIDs[0] != "ethereum" {
+		t.Fatalf("watchlist = %v, want [ethereum]", reloaded.CoinIDs)
+	}
+}
+
+func TestConfigStore_ReloadIfChanged(t *testing.T) {
+	dir := t.TempDir()
+	daemon := NewConfigStore(dir)
+	daemon.Config.Currency = "eur"
+	if err := daemon.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := daemon.ReloadIfChanged(); changed || err != nil {
+		t.Fatalf("ReloadIfChanged() after own write = %v, %v", changed, err)
+	}
+
+	cli := NewConfigStore(dir)
+	cli.Config.AlertCheckIntervalM = 1
+	if err := cli.Save(); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := daemon.ReloadIfChanged()
+	if !changed || err != nil {
+		t.Fatalf("ReloadIfChanged() = %v, %v", changed, err)
+	}
+	// Settings removed from the file are reset
+	if daemon.Config.Currency != "" || daemon.Config.AlertCheckIntervalM != 1 {
+		t.Fatalf("reloaded config = %+v", daemon.Config)
+	}
+}
+
+func TestConfigStore_UpdateKeepsOtherChanges(t *testing.T) {
+	dir := t.TempDir()
+	cli := NewConfigStore(dir)
+	daemon := NewConfigStore(dir)
+	_ = cli.Load()
+	_ = daemon.Load()
+
+	// Both stores read the file before either saves
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := cli.Update(func(c *AppConfig) error { c.Currency = "eur"; return nil }); err != nil {
+			t.Error(err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if err := daemon.Update(func(c *AppConfig) error { c.AlertCheckIntervalM = 1; return nil }); err != nil {
+			t.Error(err)
+		}
+	}()
+	wg.Wait()
+
+	saved := NewConfigStore(dir)
+	if err := saved.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if saved.Config.Currency != "eur" || saved.Config.AlertCheckIntervalM != 1 {
+		t.Fatalf("saved config = %+v", saved.Config)
+	}
+
+	// A failed change saves nothing
+	if err := cli.Update(func(c *AppConfig) error { c.Currency = "gbp"; return errors.New("invalid") }); err == nil {
+		t.Fatal("Update() should return the change's error")
+	}
+	if err := saved.Load(); err != nil || saved.Config.Currency != "eur" {
+		t.Fatalf("config after a failed update = %+v, %v", saved.Config, err)
+	}
+}
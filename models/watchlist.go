@@ -21,17 +21,56 @@ func NewWatchlist(configDir string) *Watchlist {
 }
 
 func (w *Watchlist) Load() error {
+	return w.read()
+}
+
+func (w *Watchlist) Save() error {
+	unlock, err := lockFile(w.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return w.write()
+}
+
+// update applies change to the latest file contents and saves the result
+// under the file lock.
+func (w *Watchlist) update(change func() error) error {
+	unlock, err := lockFile(w.filePath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if err := w.read(); err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	return w.write()
+}
+
+// read replaces the list with the file contents; a missing file is empty.
+func (w *Watchlist) read() error {
 	data, err := os.ReadFile(w.filePath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			w.CoinIDs = make([]string, 0)
 			return nil
 		}
 		return err
 	}
-	return json.Unmarshal(data, w)
+	w.CoinIDs = nil
+	if err := json.Unmarshal(data, w); err != nil {
+		return err
+	}
+	if w.CoinIDs == nil {
+		w.CoinIDs = make([]string, 0)
+	}
+	return nil
 }
 
-func (w *Watchlist) Save() error {
+func (w *Watchlist) write() error {
 	data, err := json.MarshalIndent(w, "", "  ")
 	if err != nil {
 		return err
@@ -44,24 +83,28 @@ func (w *Watchlist) Add(coinID string) error {
 	if coinID == "" {
 		return fmt.Errorf("coin id is required")
 	}
-	for _, id := range w.CoinIDs {
-		if id == coinID {
-			return fmt.Errorf("%s is already in watchlist", coinID)
+	return w.update(func() error {
+		for _, id := range w.CoinIDs {
+			if id == coinID {
+				return fmt.Errorf("%s is already in watchlist", coinID)
+			}
 		}
-	}
-	w.CoinIDs = append(w.CoinIDs, coinID)
-	return w.Save()
+		w.CoinIDs = append(w.CoinIDs, coinID)
+		return nil
+	})
 }
 
 func (w *Watchlist) Remove(coinID string) error {
 	coinID = strings.ToLower(strings.TrimSpace(coinID))
-	for i, id := range w.CoinIDs {
-		if id == coinID {
-			w.CoinIDs = append(w.CoinIDs[:i], w.CoinIDs[i+1:]...)
-			return w.Save()
+	return w.update(func() error {
+		for i, id := range w.CoinIDs {
+			if id == coinID {
+				w.CoinIDs = append(w.CoinIDs[:i], w.CoinIDs[i+1:]...)
+				return nil
+			}
 		}
-	}
-	return fmt.Errorf("%s is not in watchlist", coinID)
+		return fmt.Errorf("%s is not in watchlist", coinID)
+	})
 }
 
 func (w *Watchlist) Contains(coinID string) bool {
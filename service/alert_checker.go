@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,20 +16,75 @@ import (
 
 const defaultAlertCheckInterval = 5 * time.Minute
 
+// maxRecentTriggers is how many triggers CheckerStatus keeps.
+const maxRecentTriggers = 10
+
+// firedRetryInterval is how often the checker retries saving a fired alert
+// when alerts.json could not be updated.
+const firedRetryInterval = 30 * time.Second
+
+// firedAlert is an alert that fired but is not yet marked in alerts.json.
+type firedAlert struct {
+	at         time.Time
+	retryAfter time.Time
+}
+
+// CheckerStatus reports what the alert checker has been doing.
+type CheckerStatus struct {
+	Interval string `json:"interval"`
+	// Stream is the price stream URL, empty when streaming is off.
+	Stream    string    `json:"stream,omitempty"`
+	Running   bool      `json:"running"`
+	LastCheck time.Time `json:"last_check"`
+	// NextCheck is zero while no check is scheduled.
+	NextCheck      time.Time             `json:"next_check"`
+	LastError      string                `json:"last_error,omitempty"`
+	LastErrorAt    time.Time             `json:"last_error_at"`
+	RecentTriggers []models.AlertTrigger `json:"recent_triggers,omitempty"`
+}
+
 // AlertChecker periodically evaluates price alerts.
 type AlertChecker struct {
 	alertManager *models.AlertManager
+	portfolio    PortfolioLoader
+	history      *models.AlertHistory
+	source       string
+	notifiers    []Notifier
+	batch        []Notification // triggers of the current cycle for BatchNotifiers
+	// deliveries sends notifications outside checkMu.
+	deliveries deliveryQueue
 	coinGecko    *CoinGecko
-	stopChan     chan struct{}
-	doneChan     chan struct{}
-	mu           sync.Mutex
-	interval     time.Duration
+	stream       *PriceStream
+	// streamSymbols caches the ticker symbol of streamed coins, e.g. "btc".
+	streamSymbols map[string]string
+	// streamReference holds CoinGecko's latest price of streamed coins and
+	// streamVerified whether each coin's exchange pair matched it. Both are
+	// guarded by checkMu.
+	streamReference map[streamTarget]float64
+	streamVerified  map[streamTarget]bool
+	stopChan        chan struct{}
+	doneChan        chan struct{}
+	checkNow        chan struct{}
+	mu              sync.Mutex
+	// checkMu serializes polling cycles and stream ticks.
+	checkMu sync.Mutex
+	// fired holds alerts that fired while alerts.json could not be
+	// updated, so they do not fire again meanwhile. Guarded by checkMu.
+	fired    map[models.AlertKey]firedAlert
+	interval time.Duration
+	// trackCurrency and trackLoad are set by TrackPrices.
+	trackCurrency string
+	trackLoad     func() (*models.Portfolio, []string, error)
+	statusMu      sync.Mutex
+	status        CheckerStatus
+	metrics       checkerMetrics
 }
 
 func NewAlertChecker(alertManager *models.AlertManager) *AlertChecker {
 	return &AlertChecker{
 		alertManager: alertManager,
 		coinGecko:    NewCoinGecko(),
+		checkNow:     make(chan struct{}, 1),
 		interval:     defaultAlertCheckInterval,
 	}
 }
@@ -36,6 +95,66 @@ func (ac *AlertChecker) SetInterval(d time.Duration) {
 	}
 }
 
+// PortfolioLoader returns the current portfolio. It is called on every
+// check, so changes made by other processes are picked up.
+type PortfolioLoader func() (*models.Portfolio, error)
+
+// SetPortfolio sets how the portfolio that portfolio alerts are evaluated
+// against is loaded. Without it those alerts are never triggered.
+func (ac *AlertChecker) SetPortfolio(load PortfolioLoader) {
+	ac.portfolio = load
+}
+
+// SetHistory records triggered alerts in history, tagged with source.
+func (ac *AlertChecker) SetHistory(history *models.AlertHistory, source string) {
+	ac.history = history
+	ac.source = source
+}
+
+// SetNotifiers sets where triggered alerts are sent besides stdout.
+func (ac *AlertChecker) SetNotifiers(notifiers []Notifier) {
+	ac.notifiers = notifiers
+}
+
+// SetStream evaluates price alerts on every tick of stream in addition to
+// polling. Polling keeps covering the other conditions, and all alerts
+// while the stream is unavailable.
+func (ac *AlertChecker) SetStream(stream *PriceStream) {
+	ac.stream = stream
+}
+
+// Status returns the checker's recent activity. Interval and Stream are
+// read without locking, so Status must not race with their setters.
+func (ac *AlertChecker) Status() CheckerStatus {
+	ac.statusMu.Lock()
+	status := ac.status
+	status.RecentTriggers = append([]models.AlertTrigger(nil), ac.status.RecentTriggers...)
+	ac.statusMu.Unlock()
+
+	status.Interval = ac.interval.String()
+	if ac.stream != nil {
+		status.Stream = ac.stream.String()
+	}
+	return status
+}
+
+// CheckNow asks the running check loop to check all alerts right away and
+// reports whether a loop was running to take the request.
+func (ac *AlertChecker) CheckNow() bool {
+	ac.statusMu.Lock()
+	running := ac.status.Running
+	ac.statusMu.Unlock()
+	if !running {
+		return false
+	}
+	select {
+	case ac.checkNow <- struct{}{}:
+	default:
+		// A check is already pending
+	}
+	return true
+}
+
 func (ac *AlertChecker) EnsureRunning() {
 	if len(ac.alertManager.GetAlerts()) == 0 {
 		return
@@ -43,11 +162,18 @@ func (ac *AlertChecker) EnsureRunning() {
 	ac.Start()
 }
 
+// Start runs the check loop in the background, checking right away. It
+// does nothing while a loop is running, but starts a new one when the last
+// loop ended because the alerts ran out.
 func (ac *AlertChecker) Start() {
 	ac.mu.Lock()
 	if ac.stopChan != nil {
-		ac.mu.Unlock()
-		return
+		select {
+		case <-ac.doneChan:
+		default:
+			ac.mu.Unlock()
+			return
+		}
 	}
 
 	ac.stopChan = make(chan struct{})
@@ -61,26 +187,103 @@ func (ac *AlertChecker) Start() {
 
 func (ac *AlertChecker) runLoop(stopChan, doneChan chan struct{}) {
 	defer close(doneChan)
+	ac.setRunning(true)
+	defer ac.setRunning(false)
+
+	if ac.stream != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		streamDone := make(chan struct{})
+		go func() {
+			defer close(streamDone)
+			ac.streamLoop(ctx)
+		}()
+		defer func() {
+			cancel()
+			<-streamDone
+		}()
+	}
 
 	ticker := time.NewTicker(ac.interval)
 	defer ticker.Stop()
 
 	ac.runAlertChecks(stopChan)
+	ac.scheduleNextCheck()
 
 	for {
 		select {
 		case <-stopChan:
 			return
 		case <-ticker.C:
-			if len(ac.alertManager.GetAlerts()) == 0 {
+			if len(ac.alertManager.GetAlerts()) == 0 && !ac.tracksPrices() {
 				return
 			}
 			ac.runAlertChecks(stopChan)
+		case <-ac.checkNow:
+			ac.runAlertChecks(stopChan)
+			ticker.Reset(ac.interval)
 		}
+		ac.scheduleNextCheck()
 	}
 }
 
+func (ac *AlertChecker) setRunning(running bool) {
+	ac.statusMu.Lock()
+	ac.status.Running = running
+	ac.status.NextCheck = time.Time{}
+	ac.statusMu.Unlock()
+}
+
+func (ac *AlertChecker) scheduleNextCheck() {
+	ac.statusMu.Lock()
+	ac.status.NextCheck = time.Now().Add(ac.interval)
+	ac.statusMu.Unlock()
+}
+
+// logError logs an error met while checking alerts and records it as the
+// last error in Status. attrs are slog key-value pairs.
+func (ac *AlertChecker) logError(msg string, err error, attrs ...interface{}) {
+	slog.Error(msg, append(attrs, "err", err)...)
+	ac.recordError(msg, err, attrs...)
+}
+
+// recordError sets the last error in Status, e.g. "checking alert
+// (coin=bitcoin): API error".
+func (ac *AlertChecker) recordError(msg string, err error, attrs ...interface{}) {
+	pairs := make([]string, 0, len(attrs)/2)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%v=%v", attrs[i], attrs[i+1]))
+	}
+	if len(pairs) > 0 {
+		msg += " (" + strings.Join(pairs, " ") + ")"
+	}
+	ac.statusMu.Lock()
+	ac.status.LastError = fmt.Sprintf("%s: %v", msg, err)
+	ac.status.LastErrorAt = time.Now()
+	ac.metrics.errors++
+	ac.statusMu.Unlock()
+}
+
 func (ac *AlertChecker) runAlertChecks(stopChan <-chan struct{}) {
+	ac.checkMu.Lock()
+	defer ac.checkMu.Unlock()
+	defer ac.flushBatch()
+
+	now := time.Now()
+	ac.statusMu.Lock()
+	ac.status.LastCheck = now
+	ac.statusMu.Unlock()
+	defer ac.prunePrices(now)
+	defer ac.trackPrices(stopChan)
+
+	expired, err := ac.alertManager.RemoveExpiredAlerts(now)
+	if err != nil {
+		ac.logError("removing expired alerts", err)
+	}
+	for _, alert := range expired {
+		currencySymbol := utils.CurrencySymbol(utils.NormalizeCurrency(alert.Currency))
+		slog.Info("alert expired", "coin", alert.CoinID, "alert", alert.Describe(currencySymbol))
+	}
+
 	alerts := ac.alertManager.GetAlerts()
 	if len(alerts) == 0 {
 		return
@@ -88,6 +291,9 @@ func (ac *AlertChecker) runAlertChecks(stopChan <-chan struct{}) {
 
 	byCurrency := make(map[string][]models.Alert)
 	for _, alert := range alerts {
+		if alert.InCooldown(now) {
+			continue
+		}
 		currency := utils.NormalizeCurrency(alert.Currency)
 		byCurrency[currency] = append(byCurrency[currency], alert)
 	}
@@ -99,9 +305,24 @@ func (ac *AlertChecker) runAlertChecks(stopChan <-chan struct{}) {
 		default:
 		}
 
-		coinIDs := make([]string, 0, len(currencyAlerts))
-		seen := make(map[string]struct{})
+		var coinAlerts, portfolioAlerts []models.Alert
 		for _, alert := range currencyAlerts {
+			if alert.IsPortfolioAlert() {
+				portfolioAlerts = append(portfolioAlerts, alert)
+			} else {
+				coinAlerts = append(coinAlerts, alert)
+			}
+		}
+		if len(portfolioAlerts) > 0 {
+			ac.runPortfolioChecks(portfolioAlerts, currency)
+		}
+		if len(coinAlerts) == 0 {
+			continue
+		}
+
+		coinIDs := make([]string, 0, len(coinAlerts))
+		seen := make(map[string]struct{})
+		for _, alert := range coinAlerts {
 			if _, ok := seen[alert.CoinID]; ok {
 				continue
 			}
@@ -109,32 +330,379 @@ func (ac *AlertChecker) runAlertChecks(stopChan <-chan struct{}) {
 			coinIDs = append(coinIDs, alert.CoinID)
 		}
 
-		prices, err := ac.coinGecko.GetSimplePrices(coinIDs, currency)
+		quotes, err := ac.fetchQuotes(coinAlerts, coinIDs, currency)
 		if err != nil {
-			fmt.Printf("Error fetching prices for alerts: %v\n", err)
+			ac.logError("fetching prices for alerts", err, "currency", currency)
 			continue
 		}
+		ac.recordQuotes(quotes, currency)
+		for coinID, quote := range quotes {
+			ac.setStreamReference(streamTarget{coinID: coinID, currency: currency}, quote.price)
+		}
 
-		for _, alert := range currencyAlerts {
-			currentPrice, ok := prices[alert.CoinID]
+		history := newAlertHistory(ac.coinGecko)
+		for _, alert := range coinAlerts {
+			quote, ok := quotes[alert.CoinID]
 			if !ok {
 				continue
 			}
-			if ac.isTriggered(alert, currentPrice) {
-				ac.sendNotification(alert, currentPrice)
-				if err := ac.alertManager.RemoveTriggeredAlert(alert); err != nil {
-					fmt.Printf("Error removing triggered alert for %s: %v\n", alert.CoinID, err)
-				}
+			triggered, err := ac.isTriggered(alert, quote, history)
+			if err != nil {
+				ac.logError("checking alert", err, "coin", alert.CoinID, "condition", alert.Condition)
+				continue
+			}
+			ac.settle(alert, triggered, rearmed(alert, quote.price, triggered), quote.price, func() string {
+				return alertMessage(alert, quote.price)
+			})
+		}
+	}
+}
+
+// portfolioSnapshot is the portfolio state portfolio alerts are checked
+// against.
+type portfolioSnapshot struct {
+	value     float64
+	dayChange float64 // percent change of the current holdings over 24h
+	weights   map[string]float64
+}
+
+// runPortfolioChecks prices the portfolio holdings once and evaluates the
+// portfolio alerts for one currency.
+func (ac *AlertChecker) runPortfolioChecks(alerts []models.Alert, currency string) {
+	if ac.portfolio == nil {
+		return
+	}
+	portfolio, err := ac.portfolio()
+	if err != nil {
+		ac.logError("loading portfolio for portfolio alerts", err)
+		return
+	}
+	if !portfolio.HasHoldings() {
+		return
+	}
+
+	coinIDs := make([]string, 0, len(portfolio.Holdings))
+	for coinID := range portfolio.Holdings {
+		coinIDs = append(coinIDs, coinID)
+	}
+	sort.Strings(coinIDs)
+
+	coins, err := ac.coinGecko.GetMarketsByIDs(currency, coinIDs)
+	if err != nil {
+		ac.logError("fetching prices for portfolio alerts", err, "currency", currency)
+		return
+	}
+	ac.recordCoinPrices(coins, currency)
+	snapshot := newPortfolioSnapshot(portfolio, coins, currency)
+
+	for _, alert := range alerts {
+		if snapshot.value <= 0 {
+			break
+		}
+		triggered := isPortfolioTriggered(alert, snapshot)
+		level := snapshot.level(alert)
+		ac.settle(alert, triggered, rearmed(alert, level, triggered), level, func() string {
+			return portfolioAlertMessage(alert, snapshot)
+		})
+	}
+}
+
+// settle applies an alert's repeat mode to one evaluation. Armed alerts
+// notify and are marked triggered; disarmed ones re-arm when rearm is set.
+// price is what the alert compared, recorded in the trigger history.
+func (ac *AlertChecker) settle(alert models.Alert, triggered, rearm bool, price float64, message func() string) {
+	key := alert.Key()
+	if fired, ok := ac.fired[key]; ok {
+		// Already notified; only the store is behind
+		if time.Now().Before(fired.retryAfter) {
+			return
+		}
+		if err := ac.alertManager.MarkTriggered(alert, fired.at); err != nil {
+			fired.retryAfter = time.Now().Add(firedRetryInterval)
+			ac.fired[key] = fired
+			ac.logError("updating triggered alert", err, "coin", alert.CoinID, "condition", alert.Condition)
+			return
+		}
+		delete(ac.fired, key)
+		return
+	}
+	if alert.Disarmed {
+		if rearm {
+			if err := ac.alertManager.RearmAlert(alert); err != nil {
+				ac.logError("re-arming alert", err, "coin", alert.CoinID, "condition", alert.Condition)
 			}
 		}
+		return
+	}
+	if !triggered {
+		return
+	}
+	// Record the fire before saving it, so a failed save does not notify
+	// again on the next tick
+	now := time.Now()
+	if ac.fired == nil {
+		ac.fired = make(map[models.AlertKey]firedAlert)
+	}
+	ac.fired[key] = firedAlert{at: now}
+	ac.notify(alert, price, message())
+	if err := ac.alertManager.MarkTriggered(alert, now); err != nil {
+		ac.fired[key] = firedAlert{at: now, retryAfter: now.Add(firedRetryInterval)}
+		ac.logError("updating triggered alert", err, "coin", alert.CoinID, "condition", alert.Condition)
+		return
+	}
+	delete(ac.fired, key)
+}
+
+// rearmed reports whether a fired rearm alert may fire again. Threshold
+// conditions wait for level to move back past the threshold by the
+// hysteresis band; the others re-arm as soon as they stop triggering.
+func rearmed(alert models.Alert, level float64, triggered bool) bool {
+	band := alert.Hysteresis / 100
+	switch alert.Condition {
+	case models.ConditionAbove, models.ConditionValueAbove:
+		return level < alert.Price*(1-band)
+	case models.ConditionBelow, models.ConditionValueBelow:
+		return level > alert.Price*(1+band)
+	case models.ConditionWeightAbove:
+		return level < alert.Percent*(1-band)
+	case models.ConditionRangeExit:
+		return level > alert.Low*(1+band) && level < alert.High*(1-band)
+	}
+	return !triggered
+}
+
+// newPortfolioSnapshot values the portfolio at current prices and at the
+// prices 24h ago implied by each coin's 24h change.
+func newPortfolioSnapshot(p *models.Portfolio, coins []models.Coin, currency string) portfolioSnapshot {
+	prices := make(map[string]float64, len(coins))
+	dayAgoValue := 0.0
+	for _, coin := range coins {
+		prices[coin.ID] = coin.CurrentPrice
+		dayAgoValue += p.GetHolding(coin.ID) * coin.CurrentPrice / (1 + coin.PriceChangePercentage24h/100)
+	}
+
+	pnl := models.ComputePortfolioPnL(p, prices, currency)
+	snapshot := portfolioSnapshot{value: pnl.TotalValue, weights: make(map[string]float64, len(pnl.Coins))}
+	if dayAgoValue > 0 {
+		snapshot.dayChange = (pnl.TotalValue - dayAgoValue) / dayAgoValue * 100
+	}
+	if pnl.TotalValue > 0 {
+		for _, coin := range pnl.Coins {
+			snapshot.weights[coin.CoinID] = coin.CurrentValue / pnl.TotalValue * 100
+		}
+	}
+	return snapshot
+}
+
+// level is the portfolio figure alert compares with its threshold.
+func (s portfolioSnapshot) level(alert models.Alert) float64 {
+	if alert.Condition == models.ConditionWeightAbove {
+		return s.weights[alert.CoinID]
+	}
+	return s.value
+}
+
+func isPortfolioTriggered(alert models.Alert, snapshot portfolioSnapshot) bool {
+	if snapshot.value <= 0 {
+		return false
 	}
+	switch alert.Condition {
+	case models.ConditionValueAbove:
+		return snapshot.value >= alert.Price
+	case models.ConditionValueBelow:
+		return snapshot.value <= alert.Price
+	case models.ConditionDailyPnL:
+		return math.Abs(snapshot.dayChange) >= alert.Percent
+	case models.ConditionWeightAbove:
+		return snapshot.weights[alert.CoinID] >= alert.Percent
+	}
+	return false
+}
+
+// alertQuote is the current market data alerts are checked against.
+type alertQuote struct {
+	price float64
+	rank  int
 }
 
-func (ac *AlertChecker) isTriggered(alert models.Alert, currentPrice float64) bool {
-	if alert.Condition == "above" {
-		return currentPrice >= alert.Price
+// fetchQuotes loads current prices, using the markets endpoint only when a
+// rank alert needs market-cap ranks.
+func (ac *AlertChecker) fetchQuotes(alerts []models.Alert, coinIDs []string, currency string) (map[string]alertQuote, error) {
+	quotes := make(map[string]alertQuote, len(coinIDs))
+	needsRank := false
+	for _, alert := range alerts {
+		needsRank = needsRank || alert.Condition == models.ConditionRankChange
 	}
-	return currentPrice <= alert.Price
+	if !needsRank {
+		prices, err := ac.coinGecko.GetSimplePrices(coinIDs, currency)
+		if err != nil {
+			return nil, err
+		}
+		for id, price := range prices {
+			quotes[id] = alertQuote{price: price}
+		}
+		return quotes, nil
+	}
+
+	coins, err := ac.coinGecko.GetMarketsByIDs(currency, coinIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, coin := range coins {
+		quotes[coin.ID] = alertQuote{price: coin.CurrentPrice, rank: coin.MarketCapRank}
+	}
+	return quotes, nil
+}
+
+// alertHistory fetches market charts on demand and caches them for one run
+// of checks, so several alerts on a coin share a request.
+type alertHistory struct {
+	coinGecko *CoinGecko
+	charts    map[string]models.MarketChart
+}
+
+func newAlertHistory(cg *CoinGecko) *alertHistory {
+	return &alertHistory{coinGecko: cg, charts: make(map[string]models.MarketChart)}
+}
+
+// chart returns history covering lookback. Daily charts have one point per
+// day plus the current one; otherwise the API picks the finest granularity.
+func (h *alertHistory) chart(coinID, currency string, lookback time.Duration, daily bool) (models.MarketChart, error) {
+	interval := SelectIntervalForRange(time.Now().Add(-lookback), time.Now())
+	key := fmt.Sprintf("%s|%s|%s|%t", coinID, currency, interval.Name, daily)
+	if chart, ok := h.charts[key]; ok {
+		return chart, nil
+	}
+
+	var chart models.MarketChart
+	var err error
+	if daily {
+		chart, err = h.coinGecko.GetCoinMarketChart(coinID, currency, interval.Name)
+	} else {
+		chart, err = h.coinGecko.GetCoinMarketChartAuto(coinID, currency, interval.Name)
+	}
+	if err != nil {
+		return models.MarketChart{}, err
+	}
+	h.charts[key] = chart
+	return chart, nil
+}
+
+func (ac *AlertChecker) isTriggered(alert models.Alert, quote alertQuote, history *alertHistory) (bool, error) {
+	currency := utils.NormalizeCurrency(alert.Currency)
+
+	switch alert.Condition {
+	case models.ConditionAbove:
+		return quote.price >= alert.Price, nil
+	case models.ConditionBelow:
+		return quote.price <= alert.Price, nil
+	case models.ConditionRangeExit:
+		return quote.price < alert.Low || quote.price > alert.High, nil
+	case models.ConditionRankChange:
+		if quote.rank <= 0 {
+			return false, nil
+		}
+		move := quote.rank - alert.Rank
+		return move >= alert.RankMove || -move >= alert.RankMove, nil
+
+	case models.ConditionChange:
+		window, err := models.ParseAlertWindow(alert.Window)
+		if err != nil {
+			return false, err
+		}
+		chart, err := history.chart(alert.CoinID, currency, window, false)
+		if err != nil {
+			return false, err
+		}
+		change, ok := percentChangeSince(utils.PairsToSeries(chart.Prices), time.Now().Add(-window), quote.price)
+		if !ok {
+			return false, fmt.Errorf("no price history for the last %s", alert.Window)
+		}
+		if alert.Percent > 0 {
+			return change >= alert.Percent, nil
+		}
+		return change <= alert.Percent, nil
+
+	case models.ConditionCrossAbove, models.ConditionCrossBelow:
+		lookback := time.Duration(alert.MAPeriod+2) * 24 * time.Hour
+		chart, err := history.chart(alert.CoinID, currency, lookback, true)
+		if err != nil {
+			return false, err
+		}
+		prevClose, prevMA, ma, ok := movingAverageCross(pairValues(chart.Prices), alert.MAPeriod)
+		if !ok {
+			return false, fmt.Errorf("not enough history for a %d-day moving average", alert.MAPeriod)
+		}
+		if alert.Condition == models.ConditionCrossAbove {
+			return prevClose < prevMA && quote.price >= ma, nil
+		}
+		return prevClose > prevMA && quote.price <= ma, nil
+
+	case models.ConditionVolumeSpike:
+		window, err := models.ParseAlertWindow(alert.Window)
+		if err != nil {
+			return false, err
+		}
+		chart, err := history.chart(alert.CoinID, currency, window+24*time.Hour, true)
+		if err != nil {
+			return false, err
+		}
+		current, average, ok := volumeAgainstAverage(pairValues(chart.TotalVolumes), int(window.Hours()/24))
+		if !ok {
+			return false, fmt.Errorf("not enough volume history for %s", alert.Window)
+		}
+		return current >= alert.Multiplier*average, nil
+	}
+	return false, fmt.Errorf("unknown condition %q", alert.Condition)
+}
+
+func pairValues(pairs [][]float64) []float64 {
+	values := make([]float64, 0, len(pairs))
+	for _, pair := range pairs {
+		if len(pair) >= 2 {
+			values = append(values, pair[1])
+		}
+	}
+	return values
+}
+
+// percentChangeSince compares price with the history point nearest since.
+func percentChangeSince(points []utils.SeriesPoint, since time.Time, price float64) (float64, bool) {
+	i := utils.NearestPointIndex(points, since)
+	if i < 0 || points[i].Value == 0 {
+		return 0, false
+	}
+	return (price - points[i].Value) / points[i].Value * 100, true
+}
+
+// movingAverageCross reads daily closes whose last value is the current
+// price. It returns yesterday's close with the moving average ending there,
+// and the moving average ending today.
+func movingAverageCross(closes []float64, period int) (prevClose, prevMA, ma float64, ok bool) {
+	n := len(closes)
+	if period < 1 || n < period+2 {
+		return 0, 0, 0, false
+	}
+	return closes[n-2], mean(closes[n-2-period : n-2]), mean(closes[n-1-period : n-1]), true
+}
+
+// volumeAgainstAverage returns the latest 24h volume and the average of the
+// days daily volumes before it.
+func volumeAgainstAverage(volumes []float64, days int) (current, average float64, ok bool) {
+	n := len(volumes)
+	if days < 1 || n < days+1 {
+		return 0, 0, false
+	}
+	average = mean(volumes[n-1-days : n-1])
+	return volumes[n-1], average, average > 0
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
 }
 
 func (ac *AlertChecker) Stop() {
@@ -151,24 +719,126 @@ func (ac *AlertChecker) Stop() {
 	ac.mu.Unlock()
 
 	<-doneChan
+	// Send what the last checks triggered before the process can exit
+	ac.deliveries.wait()
 }
 
+// RunOnce checks all alerts and waits for their notifications to be sent.
 func (ac *AlertChecker) RunOnce() {
 	ac.runAlertChecks(nil)
+	ac.deliveries.wait()
+}
+
+// notify prints a triggered alert, records it in the history and queues
+// it for the configured notifiers.
+func (ac *AlertChecker) notify(alert models.Alert, price float64, message string) {
+	slog.Info(message, "coin", alert.CoinID, "price", price)
+	now := time.Now()
+	trigger := models.AlertTrigger{
+		Alert:       alert,
+		Price:       price,
+		Message:     message,
+		Source:      ac.source,
+		TriggeredAt: now,
+	}
+	ac.countTrigger(alert)
+	ac.statusMu.Lock()
+	ac.status.RecentTriggers = append(ac.status.RecentTriggers, trigger)
+	if extra := len(ac.status.RecentTriggers) - maxRecentTriggers; extra > 0 {
+		ac.status.RecentTriggers = ac.status.RecentTriggers[extra:]
+	}
+	ac.statusMu.Unlock()
+	if ac.history != nil {
+		if err := ac.history.Append(trigger); err != nil {
+			ac.logError("recording alert history", err)
+		}
+	}
+
+	notification := Notification{Alert: alert, Price: price, Message: message, TriggeredAt: now}
+	notifiers := ac.notifiers
+	ac.deliveries.push(func() {
+		for _, notifier := range notifiers {
+			if _, ok := notifier.(BatchNotifier); ok {
+				continue
+			}
+			if err := notifier.Notify(notification); err != nil {
+				ac.logError("sending notification", err, "notifier", notifier.Name())
+			}
+		}
+	})
+	ac.batch = append(ac.batch, notification)
 }
 
-func (ac *AlertChecker) sendNotification(alert models.Alert, currentPrice float64) {
+// flushBatch queues the triggers of a check cycle for the batch notifiers.
+func (ac *AlertChecker) flushBatch() {
+	batch := ac.batch
+	ac.batch = nil
+	if len(batch) == 0 {
+		return
+	}
+	notifiers := ac.notifiers
+	ac.deliveries.push(func() {
+		for _, notifier := range notifiers {
+			if batcher, ok := notifier.(BatchNotifier); ok {
+				if err := batcher.NotifyBatch(batch); err != nil {
+					ac.logError("sending notification", err, "notifier", notifier.Name())
+				}
+			}
+		}
+	})
+}
+
+func alertMessage(alert models.Alert, currentPrice float64) string {
+	currency := utils.NormalizeCurrency(alert.Currency)
+	currencySymbol := utils.CurrencySymbol(currency)
+
+	var message string
+	if alert.IsPriceTarget() {
+		message = fmt.Sprintf("Price Alert: %s is %s %s%.2f (Target: %s%.2f %s)",
+			strings.ToUpper(alert.CoinID),
+			alert.Condition,
+			currencySymbol,
+			currentPrice,
+			currencySymbol,
+			alert.Price,
+			strings.ToUpper(currency))
+	} else {
+		message = fmt.Sprintf("Alert: %s %s (Price: %s%.2f %s)",
+			strings.ToUpper(alert.CoinID),
+			alert.Describe(currencySymbol),
+			currencySymbol,
+			currentPrice,
+			strings.ToUpper(currency))
+	}
+
+	return message
+}
+
+func portfolioAlertMessage(alert models.Alert, snapshot portfolioSnapshot) string {
 	currency := utils.NormalizeCurrency(alert.Currency)
 	currencySymbol := utils.CurrencySymbol(currency)
 
-	message := fmt.Sprintf("Price Alert: %s is %s %s%.2f (Target: %s%.2f %s)",
-		strings.ToUpper(alert.CoinID),
-		alert.Condition,
-		currencySymbol,
-		currentPrice,
-		currencySymbol,
-		alert.Price,
-		strings.ToUpper(currency))
+	var message string
+	switch alert.Condition {
+	case models.ConditionWeightAbove:
+		message = fmt.Sprintf("Portfolio Alert: %s %s (Weight: %.1f%%)",
+			strings.ToUpper(alert.CoinID),
+			alert.Describe(currencySymbol),
+			snapshot.weights[alert.CoinID])
+	case models.ConditionDailyPnL:
+		message = fmt.Sprintf("Portfolio Alert: %s (24h: %+.2f%%, Value: %s%.2f %s)",
+			alert.Describe(currencySymbol),
+			snapshot.dayChange,
+			currencySymbol,
+			snapshot.value,
+			strings.ToUpper(currency))
+	default:
+		message = fmt.Sprintf("Portfolio Alert: %s (Value: %s%.2f %s)",
+			alert.Describe(currencySymbol),
+			currencySymbol,
+			snapshot.value,
+			strings.ToUpper(currency))
+	}
 
-	fmt.Printf("\n%s\n", message)
+	return message
 }
@@ -0,0 +1,342 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+func TestMovingAverageCross(t *testing.T) {
+	// Four days at 100, then a close at 90 and a current price of 120
+	closes := []float64{100, 100, 100, 100, 90, 120}
+	prevClose, prevMA, ma, ok := movingAverageCross(closes, 3)
+	if !ok || prevClose != 90 || prevMA != 100 || ma != (100+100+90)/3.0 {
+		t.Fatalf("movingAverageCross = %v %v %v %v", prevClose, prevMA, ma, ok)
+	}
+	if _, _, _, ok := movingAverageCross(closes[:4], 3); ok {
+		t.Fatal("expected too little history to fail")
+	}
+}
+
+func TestVolumeAgainstAverage(t *testing.T) {
+	current, average, ok := volumeAgainstAverage([]float64{5, 10, 20, 30, 90}, 3)
+	if !ok || current != 90 || average != 20 {
+		t.Fatalf("volumeAgainstAverage = %v %v %v", current, average, ok)
+	}
+}
+
+func TestPercentChangeSince(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := []utils.SeriesPoint{{Time: base, Value: 100}, {Time: base.Add(time.Hour), Value: 110}}
+	change, ok := percentChangeSince(points, base.Add(10*time.Minute), 120)
+	if !ok || change != 20 {
+		t.Fatalf("percentChangeSince = %v %v", change, ok)
+	}
+}
+
+func TestIsTriggeredWithoutHistory(t *testing.T) {
+	checker := NewAlertChecker(models.NewAlertManager(t.TempDir()))
+	cases := []struct {
+		alert models.Alert
+		quote alertQuote
+		want  bool
+	}{
+		{models.Alert{Condition: models.ConditionAbove, Price: 100}, alertQuote{price: 100}, true},
+		{models.Alert{Condition: models.ConditionBelow, Price: 100}, alertQuote{price: 101}, false},
+		{models.Alert{Condition: models.ConditionRangeExit, Low: 90, High: 110}, alertQuote{price: 100}, false},
+		{models.Alert{Condition: models.ConditionRangeExit, Low: 90, High: 110}, alertQuote{price: 111}, true},
+		{models.Alert{Condition: models.ConditionRankChange, Rank: 5, RankMove: 2}, alertQuote{rank: 6}, false},
+		{models.Alert{Condition: models.ConditionRankChange, Rank: 5, RankMove: 2}, alertQuote{rank: 3}, true},
+		{models.Alert{Condition: models.ConditionRankChange, Rank: 5, RankMove: 2}, alertQuote{}, false},
+	}
+	for _, tc := range cases {
+		got, err := checker.isTriggered(tc.alert, tc.quote, nil)
+		if err != nil || got != tc.want {
+			t.Fatalf("isTriggered(%+v, %+v) = %v, %v; want %v", tc.alert, tc.quote, got, err, tc.want)
+		}
+	}
+}
+
+func TestAlertChecker_ChangeAlertUsesHistory(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	now := time.Now()
+	var chartRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		if strings.Contains(r.URL.Path, "market_chart") {
+			chartRequests++
+			dayAgo := now.Add(-24 * time.Hour).UnixMilli()
+			_, _ = fmt.Fprintf(w, `{"prices":[[%d,100],[%d,105]],"market_caps":[],"total_volumes":[]}`, dayAgo, now.UnixMilli())
+			return
+		}
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":112}}`))
+	}))
+	defer server.Close()
+
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	manager := models.NewAlertManager(t.TempDir())
+	checker := NewAlertChecker(manager)
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionChange, Percent: 10, Window: "24h", Currency: "usd"})
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionChange, Percent: 15, Window: "24h", Currency: "usd"})
+	checker.RunOnce()
+
+	remaining := manager.GetAlerts()
+	if len(remaining) != 1 || remaining[0].Percent != 15 {
+		t.Fatalf("expected only the +15%% alert to remain, got %+v", remaining)
+	}
+	if chartRequests != 1 {
+		t.Fatalf("history should be fetched once per run, got %d requests", chartRequests)
+	}
+}
+
+func TestPortfolioSnapshotAndTriggers(t *testing.T) {
+	portfolio := models.NewPortfolio(filepath.Join(t.TempDir(), "portfolio.json"))
+	_ = portfolio.AddTransaction(models.Transaction{CoinID: "bitcoin", Amount: 1, Price: 50000, Type: "buy"})
+	_ = portfolio.AddTransaction(models.Transaction{CoinID: "ethereum", Amount: 10, Price: 2000, Type: "buy"})
+
+	// BTC 60000 after +20%, ETH 2000 after -20%: 80000 now vs 75000 a day ago
+	coins := []models.Coin{
+		{ID: "bitcoin", CurrentPrice: 60000, PriceChangePercentage24h: 20},
+		{ID: "ethereum", CurrentPrice: 2000, PriceChangePercentage24h: -20},
+	}
+	snapshot := newPortfolioSnapshot(portfolio, coins, "usd")
+	if snapshot.value != 80000 || snapshot.weights["bitcoin"] != 75 {
+		t.Fatalf("snapshot = %+v", snapshot)
+	}
+	if got := snapshot.dayChange; got < 6.66 || got > 6.67 {
+		t.Fatalf("dayChange = %v", got)
+	}
+
+	cases := []struct {
+		alert models.Alert
+		want  bool
+	}{
+		{models.Alert{Condition: models.ConditionValueAbove, Price: 80000}, true},
+		{models.Alert{Condition: models.ConditionValueBelow, Price: 70000}, false},
+		{models.Alert{Condition: models.ConditionDailyPnL, Percent: 5}, true},
+		{models.Alert{Condition: models.ConditionDailyPnL, Percent: 10}, false},
+		{models.Alert{CoinID: "bitcoin", Condition: models.ConditionWeightAbove, Percent: 70}, true},
+		{models.Alert{CoinID: "ethereum", Condition: models.ConditionWeightAbove, Percent: 30}, false},
+		{models.Alert{CoinID: "solana", Condition: models.ConditionWeightAbove, Percent: 1}, false},
+	}
+	for _, tc := range cases {
+		if got := isPortfolioTriggered(tc.alert, snapshot); got != tc.want {
+			t.Fatalf("isPortfolioTriggered(%+v) = %v, want %v", tc.alert, got, tc.want)
+		}
+	}
+}
+
+func TestRearmed(t *testing.T) {
+	above := models.Alert{Condition: models.ConditionAbove, Price: 100, Repeat: models.RepeatRearm, Hysteresis: 2}
+	if rearmed(above, 99, false) || !rearmed(above, 97.9, false) {
+		t.Fatal("above alert should re-arm only below 98")
+	}
+	below := models.Alert{Condition: models.ConditionBelow, Price: 100, Repeat: models.RepeatRearm, Hysteresis: 2}
+	if rearmed(below, 101, false) || !rearmed(below, 102.1, false) {
+		t.Fatal("below alert should re-arm only above 102")
+	}
+	band := models.Alert{Condition: models.ConditionRangeExit, Low: 100, High: 200, Repeat: models.RepeatRearm, Hysteresis: 10}
+	if rearmed(band, 105, false) || !rearmed(band, 150, false) {
+		t.Fatal("range alert should re-arm only well inside the range")
+	}
+	change := models.Alert{Condition: models.ConditionChange, Percent: 5, Window: "24h", Repeat: models.RepeatRearm}
+	if rearmed(change, 0, true) || !rearmed(change, 0, false) {
+		t.Fatal("change alert should re-arm once it stops triggering")
+	}
+}
+
+func TestAlertChecker_RearmAlertFiresOncePerCrossing(t *testing.T) {
+	manager := models.NewAlertManager(t.TempDir())
+	checker := NewAlertChecker(manager)
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 100, Repeat: models.RepeatRearm, Hysteresis: 2})
+
+	fired := 0
+	step := func(price float64) {
+		alert := manager.GetAlerts()[0]
+		triggered, _ := checker.isTriggered(alert, alertQuote{price: price}, nil)
+		checker.settle(alert, triggered, rearmed(alert, price, triggered), price, func() string {
+			fired++
+			return alertMessage(alert, price)
+		})
+	}
+	for _, price := range []float64{101, 102, 99, 101, 97, 103} {
+		step(price)
+	}
+	if fired != 2 {
+		t.Fatalf("fired %d times, want 2", fired)
+	}
+	if alerts := manager.GetAlerts(); len(alerts) != 1 || !alerts[0].Disarmed {
+		t.Fatalf("alert should remain, disarmed: %+v", alerts)
+	}
+}
+
+func TestAlertChecker_RecordsTriggerHistory(t *testing.T) {
+	dir := t.TempDir()
+	manager := models.NewAlertManager(dir)
+	history := models.NewAlertHistory(dir)
+	checker := NewAlertChecker(manager)
+	checker.SetHistory(history, models.TriggerSourceDaemon)
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 100})
+
+	alert := manager.GetAlerts()[0]
+	checker.settle(alert, true, false, 105, func() string { return alertMessage(alert, 105) })
+
+	triggers, err := history.Query(models.AlertHistoryFilter{})
+	if err != nil || len(triggers) != 1 {
+		t.Fatalf("Query() = %+v, %v", triggers, err)
+	}
+	got := triggers[0]
+	if got.Price != 105 || got.Source != models.TriggerSourceDaemon || got.Alert.Price != 100 || !strings.Contains(got.Message, "BITCOIN is above") {
+		t.Fatalf("unexpected trigger %+v", got)
+	}
+	if len(manager.GetAlerts()) != 0 {
+		t.Fatal("one-shot alert should be removed")
+	}
+}
+
+func TestAlertChecker_PortfolioAlertsFollowPortfolioFile(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":"bitcoin","current_price":60000}]`))
+	}))
+	defer server.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "portfolio.json")
+	writer := models.NewPortfolio(path)
+	_ = writer.AddTransaction(models.Transaction{CoinID: "bitcoin", Amount: 0.5, Price: 40000, Type: "buy", Currency: "usd"})
+
+	manager := models.NewAlertManager(dir)
+	_ = manager.AddAlert(models.Alert{CoinID: models.PortfolioAlertID, Condition: models.ConditionValueAbove, Price: 50000, Currency: "usd"})
+	checker := NewAlertChecker(manager)
+	checker.SetPortfolio(func() (*models.Portfolio, error) {
+		current := models.NewPortfolio(path)
+		return current, current.Load()
+	})
+
+	checker.RunOnce()
+	if len(checker.Status().RecentTriggers) != 0 {
+		t.Fatal("0.5 BTC at 60000 should not reach 50000")
+	}
+
+	// Another process buys more between checks
+	_ = models.NewPortfolio(path).AddTransaction(models.Transaction{CoinID: "bitcoin", Amount: 0.5, Price: 40000, Type: "buy", Currency: "usd"})
+	checker.RunOnce()
+	if triggers := checker.Status().RecentTriggers; len(triggers) != 1 || triggers[0].Price != 60000 {
+		t.Fatalf("RecentTriggers after the portfolio grew = %+v", triggers)
+	}
+}
+
+func TestAlertChecker_FiredAlertWaitsForLockedStore(t *testing.T) {
+	originalTimeout := models.StoreLockTimeout
+	t.Cleanup(func() { models.StoreLockTimeout = originalTimeout })
+	models.StoreLockTimeout = 20 * time.Millisecond
+
+	dir := t.TempDir()
+	manager := models.NewAlertManager(dir)
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 100, Currency: "usd"})
+	notified := make(chan Notification, 4)
+	checker := NewAlertChecker(manager)
+	checker.SetNotifiers([]Notifier{channelNotifier(notified)})
+
+	// Another crypto process holds alerts.json
+	lock, err := os.OpenFile(filepath.Join(dir, "alerts.json.lock"), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+	if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX); err != nil {
+		t.Fatal(err)
+	}
+
+	alert := manager.GetAlerts()[0]
+	fire := func() {
+		checker.settle(alert, true, false, 105, func() string { return alertMessage(alert, 105) })
+		checker.deliveries.wait()
+	}
+	fire()
+	fire()
+	if len(notified) != 1 {
+		t.Fatalf("expected one notification while alerts.json is locked, got %d", len(notified))
+	}
+	if len(manager.GetAlerts()) != 1 {
+		t.Fatal("the alert was removed although alerts.json was locked")
+	}
+
+	// Once the lock is released, the next evaluation saves the fire
+	_ = syscall.Flock(int(lock.Fd()), syscall.LOCK_UN)
+	fired := checker.fired[alert.Key()]
+	fired.retryAfter = time.Time{}
+	checker.fired[alert.Key()] = fired
+	fire()
+	if len(notified) != 1 || len(manager.GetAlerts()) != 0 || len(checker.fired) != 0 {
+		t.Fatalf("after the retry: %d notifications, alerts %+v, pending %+v", len(notified), manager.GetAlerts(), checker.fired)
+	}
+}
+
+// blockingNotifier holds every notification until release is closed.
+type blockingNotifier struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b blockingNotifier) Name() string { return "blocking" }
+
+func (b blockingNotifier) Notify(n Notification) error {
+	select {
+	case b.started <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return nil
+}
+
+func TestAlertChecker_SlowNotifierDoesNotDelayChecks(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":105}}`))
+	}))
+	defer server.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	manager := models.NewAlertManager(t.TempDir())
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 100, Currency: "usd"})
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 200, Currency: "usd"})
+	notifier := blockingNotifier{started: make(chan struct{}, 1), release: make(chan struct{})}
+	checker := NewAlertChecker(manager)
+	checker.SetInterval(time.Hour)
+	checker.SetNotifiers([]Notifier{notifier})
+	checker.Start()
+	defer checker.Stop()
+	defer close(notifier.release)
+
+	<-notifier.started
+	first := waitForStatus(t, checker, func(s CheckerStatus) bool { return !s.LastCheck.IsZero() })
+	// The notifier is still sending the first trigger
+	checker.CheckNow()
+	waitForStatus(t, checker, func(s CheckerStatus) bool { return s.LastCheck.After(first.LastCheck) })
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+// priceMaxAge is how many check intervals a coin's price stays in the
+// metrics without an update, so coins whose alerts were removed drop out.
+const priceMaxAge = 3
+
+type priceKey struct {
+	coinID   string
+	currency string
+}
+
+type seenPrice struct {
+	price float64
+	at    time.Time
+}
+
+type triggerKey struct {
+	coinID    string
+	condition string
+}
+
+// checkerMetrics is what the alert checker exposes on /metrics. It is
+// guarded by statusMu.
+type checkerMetrics struct {
+	prices            map[priceKey]seenPrice
+	portfolio         *models.PortfolioPnL
+	portfolioCurrency string
+	triggers          map[triggerKey]int64
+	errors            int64
+}
+
+// TrackPrices makes every check also price, in currency, the portfolio and
+// the coins returned by load, for the metrics. The check loop then keeps
+// running without alerts. An empty currency turns tracking off.
+func (ac *AlertChecker) TrackPrices(currency string, load func() (*models.Portfolio, []string, error)) {
+	if currency == "" || load == nil {
+		ac.trackCurrency, ac.trackLoad = "", nil
+		return
+	}
+	ac.trackCurrency, ac.trackLoad = utils.NormalizeCurrency(currency), load
+}
+
+func (ac *AlertChecker) tracksPrices() bool {
+	return ac.trackLoad != nil
+}
+
+// trackPrices prices the tracked portfolio and coins.
+func (ac *AlertChecker) trackPrices(stopChan <-chan struct{}) {
+	if !ac.tracksPrices() {
+		return
+	}
+	select {
+	case <-stopChan:
+		return
+	default:
+	}
+
+	portfolio, coinIDs, err := ac.trackLoad()
+	if err != nil {
+		ac.logError("loading coins to track", err)
+		return
+	}
+	if portfolio != nil {
+		for coinID := range portfolio.Holdings {
+			coinIDs = append(coinIDs, coinID)
+		}
+	}
+	sort.Strings(coinIDs)
+
+	currency := ac.trackCurrency
+	coins, err := ac.coinGecko.GetMarketsByIDs(currency, coinIDs)
+	if err != nil {
+		ac.logError("fetching prices for metrics", err, "currency", currency)
+		return
+	}
+	ac.recordCoinPrices(coins, currency)
+
+	var pnl *models.PortfolioPnL
+	if portfolio != nil && portfolio.HasHoldings() {
+		prices := make(map[string]float64, len(coins))
+		for _, coin := range coins {
+			prices[coin.ID] = coin.CurrentPrice
+		}
+		computed := models.ComputePortfolioPnL(portfolio, prices, currency)
+		pnl = &computed
+	}
+	ac.statusMu.Lock()
+	ac.metrics.portfolio = pnl
+	ac.metrics.portfolioCurrency = currency
+	ac.statusMu.Unlock()
+}
+
+func (ac *AlertChecker) recordPrice(coinID, currency string, price float64) {
+	ac.statusMu.Lock()
+	defer ac.statusMu.Unlock()
+	if ac.metrics.prices == nil {
+		ac.metrics.prices = make(map[priceKey]seenPrice)
+	}
+	ac.metrics.prices[priceKey{coinID: coinID, currency: currency}] = seenPrice{price: price, at: time.Now()}
+}
+
+func (ac *AlertChecker) recordQuotes(quotes map[string]alertQuote, currency string) {
+	for coinID, quote := range quotes {
+		ac.recordPrice(coinID, currency, quote.price)
+	}
+}
+
+func (ac *AlertChecker) recordCoinPrices(coins []models.Coin, currency string) {
+	for _, coin := range coins {
+		ac.recordPrice(coin.ID, currency, coin.CurrentPrice)
+	}
+}
+
+// prunePrices drops prices that have not been updated for priceMaxAge
+// check intervals.
+func (ac *AlertChecker) prunePrices(now time.Time) {
+	cutoff := now.Add(-priceMaxAge * ac.interval)
+	ac.statusMu.Lock()
+	defer ac.statusMu.Unlock()
+	for key, seen := range ac.metrics.prices {
+		if seen.at.Before(cutoff) {
+			delete(ac.metrics.prices, key)
+		}
+	}
+}
+
+func (ac *AlertChecker) countTrigger(alert models.Alert) {
+	ac.statusMu.Lock()
+	defer ac.statusMu.Unlock()
+	if ac.metrics.triggers == nil {
+		ac.metrics.triggers = make(map[triggerKey]int64)
+	}
+	ac.metrics.triggers[triggerKey{coinID: alert.CoinID, condition: alert.Condition}]++
+}
+
+// WriteMetrics writes the prices, portfolio value and trigger counts the
+// checker has seen, and the state of its checks.
+func (ac *AlertChecker) WriteMetrics(m *MetricsWriter) {
+	ac.statusMu.Lock()
+	prices := make(map[priceKey]float64, len(ac.metrics.prices))
+	for key, seen := range ac.metrics.prices {
+		prices[key] = seen.price
+	}
+	triggers := make(map[triggerKey]int64, len(ac.metrics.triggers))
+	for key, n := range ac.metrics.triggers {
+		triggers[key] = n
+	}
+	portfolio, currency := ac.metrics.portfolio, ac.metrics.portfolioCurrency
+	errors := ac.metrics.errors
+	running, lastCheck := ac.status.Running, ac.status.LastCheck
+	ac.statusMu.Unlock()
+
+	priceKeys := make([]priceKey, 0, len(prices))
+	for key := range prices {
+		priceKeys = append(priceKeys, key)
+	}
+	sort.Slice(priceKeys, func(i, j int) bool {
+		if priceKeys[i].coinID != priceKeys[j].coinID {
+			return priceKeys[i].coinID < priceKeys[j].coinID
+		}
+		return priceKeys[i].currency < priceKeys[j].currency
+	})
+	m.Family("crypto_coin_price", MetricGauge, "Latest price of a watched coin.")
+	for _, key := range priceKeys {
+		m.Sample("crypto_coin_price", prices[key], "coin", key.coinID, "currency", key.currency)
+	}
+
+	if portfolio != nil {
+		m.Family("crypto_portfolio_value", MetricGauge, "Current value of the portfolio.")
+		m.Sample("crypto_portfolio_value", portfolio.TotalValue, "currency", currency)
+		m.Family("crypto_portfolio_coin_value", MetricGauge, "Current value of each portfolio holding.")
+		for _, coin := range portfolio.Coins {
+			m.Sample("crypto_portfolio_coin_value", coin.CurrentValue, "coin", coin.CoinID, "currency", currency)
+		}
+		// Cost and P&L mix currencies when transactions were recorded in
+		// another one
+		if !portfolio.HasMixedCurrency {
+			m.Family("crypto_portfolio_cost_basis", MetricGauge, "Average cost of the current holdings.")
+			m.Sample("crypto_portfolio_cost_basis", portfolio.TotalCost, "currency", currency)
+			m.Family("crypto_portfolio_unrealized_pnl", MetricGauge, "Unrealized profit and loss of the current holdings.")
+			m.Sample("crypto_portfolio_unrealized_pnl", portfolio.TotalUnrealizedPnL, "currency", currency)
+			m.Family("crypto_portfolio_realized_pnl", MetricGauge, "Realized profit and loss from sales of the current holdings.")
+			m.Sample("crypto_portfolio_realized_pnl", portfolio.TotalRealizedPnL, "currency", currency)
+		}
+	}
+
+	triggerKeys := make([]triggerKey, 0, len(triggers))
+	for key := range triggers {
+		triggerKeys = append(triggerKeys, key)
+	}
+	sort.Slice(triggerKeys, func(i, j int) bool {
+		if triggerKeys[i].coinID != triggerKeys[j].coinID {
+			return triggerKeys[i].coinID < triggerKeys[j].coinID
+		}
+		return triggerKeys[i].condition < triggerKeys[j].condition
+	})
+	m.Family("crypto_alert_triggers_total", MetricCounter, "Alerts triggered since the process started.")
+	for _, key := range triggerKeys {
+		m.Sample("crypto_alert_triggers_total", float64(triggers[key]), "coin", key.coinID, "condition", key.condition)
+	}
+
+	m.Family("crypto_alert_check_errors_total", MetricCounter, "Errors met while checking alerts.")
+	m.Sample("crypto_alert_check_errors_total", float64(errors))
+	m.Family("crypto_alert_checker_running", MetricGauge, "Whether the alert check loop is running.")
+	m.Sample("crypto_alert_checker_running", boolMetric(running))
+	if !lastCheck.IsZero() {
+		m.Family("crypto_alert_last_check_timestamp_seconds", MetricGauge, "Unix time of the last alert check.")
+		m.Sample("crypto_alert_last_check_timestamp_seconds", float64(lastCheck.UnixNano())/1e9)
+	}
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+const (
+	streamMinBackoff = time.Second
+	streamMaxBackoff = 2 * time.Minute
+	// streamRefresh is how often the streamed coins are compared with the
+	// active alerts.
+	streamRefresh = 30 * time.Second
+	// streamPriceTolerance is how far, as a fraction, an exchange pair's
+	// first price may be from CoinGecko's for the pair to be trusted.
+	// Coins sharing a ticker symbol are usually far apart in price.
+	streamPriceTolerance = 0.1
+)
+
+// streamable reports whether alert only needs the current price, so it can
+// be evaluated on stream ticks.
+func streamable(alert models.Alert) bool {
+	if alert.IsPortfolioAlert() {
+		return false
+	}
+	switch alert.Condition {
+	case models.ConditionAbove, models.ConditionBelow, models.ConditionRangeExit:
+		return true
+	}
+	return false
+}
+
+// streamLoop keeps the price stream subscribed to the coins with streamable
+// alerts until ctx is done, reconnecting with exponential backoff.
+func (ac *AlertChecker) streamLoop(ctx context.Context) {
+	backoff := streamMinBackoff
+	for {
+		targets := ac.streamTargets()
+		if len(targets) == 0 {
+			if !sleepContext(ctx, streamRefresh) {
+				return
+			}
+			continue
+		}
+
+		runCtx, cancel := context.WithCancel(ctx)
+		refreshDone := make(chan struct{})
+		// Resubscribe when alerts are triggered, removed or added
+		go func() {
+			defer close(refreshDone)
+			ticker := time.NewTicker(streamRefresh)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-runCtx.Done():
+					return
+				case <-ticker.C:
+					if !reflect.DeepEqual(ac.streamTargets(), targets) {
+						cancel()
+						return
+					}
+				}
+			}
+		}()
+
+		err := ac.stream.Run(runCtx, targets, func() {
+			backoff = streamMinBackoff
+			slog.Info("streaming live prices", "pairs", len(targets), "url", ac.stream.String())
+		}, ac.onTick)
+		cancel()
+		<-refreshDone
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			continue
+		}
+
+		countError(ProviderPriceStream)
+		slog.Warn("price stream unavailable", "err", err, "poll_interval", ac.interval, "retry_in", backoff)
+		ac.recordError("price stream unavailable", err)
+		if !sleepContext(ctx, backoff) {
+			return
+		}
+		backoff *= 2
+		if backoff > streamMaxBackoff {
+			backoff = streamMaxBackoff
+		}
+	}
+}
+
+// streamTargets maps exchange symbols to the coins and currencies of the
+// streamable alerts. Coins whose ticker symbol cannot be looked up yet are
+// left to polling.
+func (ac *AlertChecker) streamTargets() map[string][]streamTarget {
+	ac.checkMu.Lock()
+	alerts := ac.alertManager.GetAlerts()
+	rejected := make(map[streamTarget]bool)
+	for target, ok := range ac.streamVerified {
+		rejected[target] = !ok
+	}
+	ac.checkMu.Unlock()
+
+	wanted := make(map[streamTarget]struct{})
+	var missing []string
+	for _, alert := range alerts {
+		if !streamable(alert) {
+			continue
+		}
+		target := streamTarget{coinID: alert.CoinID, currency: utils.NormalizeCurrency(alert.Currency)}
+		if rejected[target] {
+			// Left to polling
+			continue
+		}
+		wanted[target] = struct{}{}
+		if _, ok := ac.streamSymbols[alert.CoinID]; !ok {
+			missing = append(missing, alert.CoinID)
+		}
+	}
+
+	if len(missing) > 0 {
+		coins, err := ac.coinGecko.GetMarketsByIDs(utils.NormalizeCurrency(DEFAULT_CURRENCY), missing)
+		if ac.streamSymbols == nil {
+			ac.streamSymbols = make(map[string]string)
+		}
+		if err != nil {
+			ac.logError("looking up ticker symbols for streaming", err)
+		} else {
+			// Unknown coins are remembered as unstreamable
+			for _, id := range missing {
+				ac.streamSymbols[id] = ""
+			}
+		}
+		for _, coin := range coins {
+			ac.streamSymbols[coin.ID] = coin.Symbol
+			ac.checkMu.Lock()
+			ac.setStreamReference(streamTarget{coinID: coin.ID, currency: utils.NormalizeCurrency(DEFAULT_CURRENCY)}, coin.CurrentPrice)
+			ac.checkMu.Unlock()
+		}
+	}
+
+	targets := make(map[string][]streamTarget)
+	for target := range wanted {
+		symbol := ac.streamSymbols[target.coinID]
+		if symbol == "" {
+			continue
+		}
+		pair := exchangeSymbol(symbol, target.currency)
+		targets[pair] = append(targets[pair], target)
+	}
+	for _, list := range targets {
+		sort.Slice(list, func(i, j int) bool { return list[i].coinID < list[j].coinID })
+	}
+	return targets
+}
+
+// onTick evaluates the streamable alerts of the ticked coin.
+func (ac *AlertChecker) onTick(tick PriceTick) {
+	ac.checkMu.Lock()
+	defer ac.checkMu.Unlock()
+	defer ac.flushBatch()
+
+	if !ac.trustStreamPair(tick) {
+		return
+	}
+	ac.recordPrice(tick.CoinID, tick.Currency, tick.Price)
+	now := time.Now()
+	for _, alert := range ac.alertManager.GetAlerts() {
+		if alert.CoinID != tick.CoinID || utils.NormalizeCurrency(alert.Currency) != tick.Currency || !streamable(alert) {
+			continue
+		}
+		if alert.Expired(now) || alert.InCooldown(now) {
+			continue
+		}
+		triggered, err := ac.isTriggered(alert, alertQuote{price: tick.Price}, nil)
+		if err != nil {
+			continue
+		}
+		ac.settle(alert, triggered, rearmed(alert, tick.Price, triggered), tick.Price, func() string {
+			return alertMessage(alert, tick.Price)
+		})
+	}
+}
+
+// setStreamReference records CoinGecko's price of a streamed coin. The
+// caller holds checkMu.
+func (ac *AlertChecker) setStreamReference(target streamTarget, price float64) {
+	if ac.streamReference == nil {
+		ac.streamReference = make(map[streamTarget]float64)
+	}
+	ac.streamReference[target] = price
+}
+
+// trustStreamPair reports whether tick comes from an exchange pair that
+// lists the alert's coin. The first tick of each coin is compared with
+// CoinGecko's price; until there is one to compare with, ticks are
+// skipped. A pair that does not match is dropped from the stream. The
+// caller holds checkMu.
+func (ac *AlertChecker) trustStreamPair(tick PriceTick) bool {
+	target := streamTarget{coinID: tick.CoinID, currency: tick.Currency}
+	if ok, checked := ac.streamVerified[target]; checked {
+		return ok
+	}
+	reference, ok := ac.streamReference[target]
+	if !ok || reference <= 0 {
+		return false
+	}
+	if ac.streamVerified == nil {
+		ac.streamVerified = make(map[streamTarget]bool)
+	}
+	trusted := math.Abs(tick.Price-reference)/reference <= streamPriceTolerance
+	ac.streamVerified[target] = trusted
+	if !trusted {
+		slog.Warn("ignoring exchange prices that do not match CoinGecko, polling instead",
+			"coin", tick.CoinID, "currency", tick.Currency, "exchange_price", tick.Price, "coingecko_price", reference)
+	}
+	return trusted
+}
+
+// sleepContext waits for d and reports false when ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Commands accepted on the alert daemon's control socket.
+const (
+	ControlStatus   = "status"
+	ControlReload   = "reload"
+	ControlCheckNow = "check-now"
+)
+
+const controlTimeout = 5 * time.Second
+
+// ControlRequest is sent to the daemon as one line of JSON.
+type ControlRequest struct {
+	Command string `json:"command"`
+}
+
+// ControlResponse is the daemon's one-line JSON answer to a request.
+type ControlResponse struct {
+	OK      bool          `json:"ok"`
+	Error   string        `json:"error,omitempty"`
+	Message string        `json:"message,omitempty"`
+	Status  *DaemonStatus `json:"status,omitempty"`
+}
+
+// DaemonStatus is the answer to the status command.
+type DaemonStatus struct {
+	PID       int       `json:"pid"`
+	StartedAt time.Time `json:"started_at"`
+	Alerts    int       `json:"alerts"`
+	CheckerStatus
+	// Requests counts requests per provider since the daemon started.
+	Requests map[string]int64 `json:"requests"`
+}
+
+// ControlServer answers control requests on a Unix domain socket.
+type ControlServer struct {
+	listener net.Listener
+	handler  func(ControlRequest) ControlResponse
+	wg       sync.WaitGroup
+}
+
+// ListenControl listens on the socket at path and passes each request to
+// handler until Close. A socket left behind by a process that has exited
+// is replaced; one that still answers is an error.
+func ListenControl(path string, handler func(ControlRequest) ControlResponse) (*ControlServer, error) {
+	if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("%s is in use by another process", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+
+	s := &ControlServer{listener: listener, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+func (s *ControlServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *ControlServer) handle(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(controlTimeout))
+
+	var response ControlResponse
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	var request ControlRequest
+	if err == nil {
+		err = json.Unmarshal(line, &request)
+	}
+	if err != nil {
+		response = ControlResponse{Error: fmt.Sprintf("invalid request: %v", err)}
+	} else {
+		response = s.handler(request)
+	}
+	_ = json.NewEncoder(conn).Encode(response)
+}
+
+// Close stops listening, removes the socket and waits for requests being
+// answered.
+func (s *ControlServer) Close() error {
+	err := s.listener.Close()
+	s.wg.Wait()
+	return err
+}
+
+// SendControl sends a request to the daemon listening at path. A response
+// that is not OK is returned as an error.
+func SendControl(path string, request ControlRequest) (ControlResponse, error) {
+	conn, err := net.DialTimeout("unix", path, controlTimeout)
+	if err != nil {
+		return ControlResponse{}, err
+	}
+	defer conn.Close()
+	// Reloads and status wait for the daemon's main loop
+	_ = conn.SetDeadline(time.Now().Add(2 * controlTimeout))
+
+	if err := json.NewEncoder(conn).Encode(request); err != nil {
+		return ControlResponse{}, err
+	}
+	var response ControlResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return ControlResponse{}, fmt.Errorf("reading daemon response: %w", err)
+	}
+	if !response.OK {
+		if response.Error == "" {
+			response.Error = "request failed"
+		}
+		return response, errors.New(response.Error)
+	}
+	return response, nil
+}
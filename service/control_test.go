@@ -0,0 +1,201 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+func TestControlServer_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.sock")
+	server, err := ListenControl(path, func(request ControlRequest) ControlResponse {
+		switch request.Command {
+		case ControlStatus:
+			return ControlResponse{OK: true, Status: &DaemonStatus{PID: 42, Requests: map[string]int64{ProviderCoinGecko: 3}}}
+		case ControlReload:
+			return ControlResponse{OK: true, Message: "Reloaded alerts.json"}
+		}
+		return ControlResponse{Error: "unknown command"}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := SendControl(path, ControlRequest{Command: ControlStatus})
+	if err != nil || response.Status == nil || response.Status.PID != 42 || response.Status.Requests[ProviderCoinGecko] != 3 {
+		t.Fatalf("status = %+v, %v", response, err)
+	}
+	response, err = SendControl(path, ControlRequest{Command: ControlReload})
+	if err != nil || response.Message != "Reloaded alerts.json" {
+		t.Fatalf("reload = %+v, %v", response, err)
+	}
+	if _, err := SendControl(path, ControlRequest{Command: "restart"}); err == nil || err.Error() != "unknown command" {
+		t.Fatalf("unknown command error = %v", err)
+	}
+
+	// A second daemon must not take over a live socket
+	if _, err := ListenControl(path, nil); err == nil {
+		t.Fatal("expected listening on a live socket to fail")
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SendControl(path, ControlRequest{Command: ControlStatus}); err == nil {
+		t.Fatal("expected sending to a closed socket to fail")
+	}
+}
+
+func TestControlServer_RejectsInvalidRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.sock")
+	server, err := ListenControl(path, func(ControlRequest) ControlResponse {
+		return ControlResponse{OK: true}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("status\n"))
+	reply := make([]byte, 256)
+	n, _ := conn.Read(reply)
+	if !strings.Contains(string(reply[:n]), `"error":"invalid request`) {
+		t.Fatalf("reply = %s", reply[:n])
+	}
+}
+
+func TestControlServer_ReplacesStaleSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert.sock")
+	// A socket file left by a daemon that was killed
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	server, err := ListenControl(path, func(ControlRequest) ControlResponse {
+		return ControlResponse{OK: true, Message: "fresh"}
+	})
+	if err != nil {
+		t.Fatalf("ListenControl() over a stale socket error = %v", err)
+	}
+	defer server.Close()
+	if response, err := SendControl(path, ControlRequest{Command: ControlStatus}); err != nil || response.Message != "fresh" {
+		t.Fatalf("response = %+v, %v", response, err)
+	}
+}
+
+func TestAlertChecker_StatusAndCheckNow(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	var failing int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":100}}`))
+	}))
+	defer server.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	manager := models.NewAlertManager(t.TempDir())
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 90, Currency: "usd"})
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionBelow, Price: 50, Currency: "usd"})
+	checker := NewAlertChecker(manager)
+	checker.SetInterval(time.Hour)
+	if checker.CheckNow() {
+		t.Fatal("CheckNow() reported a running loop before Start")
+	}
+	requestsBefore := RequestCounts()[ProviderCoinGecko]
+	checker.Start()
+	defer checker.Stop()
+
+	status := waitForStatus(t, checker, func(s CheckerStatus) bool { return !s.NextCheck.IsZero() })
+	if !status.Running || status.Interval != "1h0m0s" || status.LastCheck.IsZero() || status.LastError != "" {
+		t.Fatalf("status after the first check = %+v", status)
+	}
+	if time.Until(status.NextCheck) < 59*time.Minute {
+		t.Fatalf("NextCheck = %v, want about an hour away", status.NextCheck)
+	}
+	if len(status.RecentTriggers) != 1 || status.RecentTriggers[0].Alert.Condition != models.ConditionAbove {
+		t.Fatalf("RecentTriggers = %+v", status.RecentTriggers)
+	}
+
+	atomic.StoreInt32(&failing, 1)
+	if !checker.CheckNow() {
+		t.Fatal("CheckNow() did not reach the running loop")
+	}
+	status = waitForStatus(t, checker, func(s CheckerStatus) bool { return s.LastError != "" })
+	if !strings.HasPrefix(status.LastError, "fetching prices for alerts (currency=usd): API error: 404") {
+		t.Fatalf("LastError = %q", status.LastError)
+	}
+	if got := RequestCounts()[ProviderCoinGecko] - requestsBefore; got != 2 {
+		t.Fatalf("counted %d CoinGecko requests, want 2", got)
+	}
+
+	checker.Stop()
+	if status := checker.Status(); status.Running || !status.NextCheck.IsZero() {
+		t.Fatalf("status after Stop = %+v", status)
+	}
+}
+
+func TestAlertChecker_StartAfterAlertsRanOut(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":100}}`))
+	}))
+	defer server.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	manager := models.NewAlertManager(t.TempDir())
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 90, Currency: "usd"})
+	checker := NewAlertChecker(manager)
+	checker.SetInterval(10 * time.Millisecond)
+	checker.Start()
+	defer checker.Stop()
+
+	// The one-shot alert fires and the loop ends on the next tick
+	first := waitForStatus(t, checker, func(s CheckerStatus) bool { return !s.Running && !s.LastCheck.IsZero() })
+
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 1000, Currency: "usd"})
+	checker.Start()
+	waitForStatus(t, checker, func(s CheckerStatus) bool { return s.Running && s.LastCheck.After(first.LastCheck) })
+}
+
+func waitForStatus(t *testing.T, checker *AlertChecker, done func(CheckerStatus) bool) CheckerStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status := checker.Status()
+		if done(status) {
+			return status
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("status never reached the expected state: %+v", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
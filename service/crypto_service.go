@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -76,6 +77,7 @@ func (cg *CoinGecko) get(requestURL string) ([]byte, error) {
 		if attempt > 0 {
 			time.Sleep(backoff)
 			backoff *= 2
+			countRetry(ProviderCoinGecko)
 		}
 
 		req, err := http.NewRequest(http.MethodGet, requestURL, nil)
@@ -84,8 +86,10 @@ func (cg *CoinGecko) get(requestURL string) ([]byte, error) {
 		}
 		cg.applyAPIKey(req)
 
+		countRequest(ProviderCoinGecko)
 		resp, err := cg.client.Do(req)
 		if err != nil {
+			countError(ProviderCoinGecko)
 			lastErr = err
 			continue
 		}
@@ -93,9 +97,13 @@ func (cg *CoinGecko) get(requestURL string) ([]byte, error) {
 		body, readErr := io.ReadAll(resp.Body)
 		resp.Body.Close()
 		if readErr != nil {
+			countError(ProviderCoinGecko)
 			lastErr = readErr
 			continue
 		}
+		if resp.StatusCode != http.StatusOK {
+			countError(ProviderCoinGecko)
+		}
 
 		switch resp.StatusCode {
 		case http.StatusOK:
@@ -281,25 +289,44 @@ func (cg *CoinGecko) GetSimplePrices(ids []string, currency string) (map[string]
 }
 
 func (cg *CoinGecko) GetCoinPriceHistory(id, currency string, interval string) ([][]float64, error) {
+	chart, err := cg.GetCoinMarketChart(id, currency, interval)
+	if err != nil {
+		return nil, err
+	}
+	return chart.Prices, nil
+}
+
+// GetCoinMarketChart returns the price, market cap and 24h volume series for a coin.
+func (cg *CoinGecko) GetCoinMarketChart(id, currency string, interval string) (models.MarketChart, error) {
 	selectedInterval := selectInterval(interval)
+	return cg.getMarketChart(id, currency, selectedInterval, selectedInterval.Value)
+}
 
-	requestURL := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=%s&days=%d&interval=%s",
-		BaseURL, strings.ToLower(strings.TrimSpace(id)), strings.ToLower(currency), selectedInterval.Days, selectedInterval.Value)
+// GetCoinMarketChartAuto is GetCoinMarketChart with CoinGecko's automatic
+// granularity: 5-minute points for 1 day, hourly up to 90 days and daily
+// beyond. It is used to build intraday candles.
+func (cg *CoinGecko) GetCoinMarketChartAuto(id, currency string, interval string) (models.MarketChart, error) {
+	return cg.getMarketChart(id, currency, selectInterval(interval), "")
+}
 
-	bodyBytes, err := cg.get(requestURL)
-	if err != nil {
-		return nil, err
+func (cg *CoinGecko) getMarketChart(id, currency string, interval Interval, granularity string) (models.MarketChart, error) {
+	requestURL := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=%s&days=%s",
+		BaseURL, strings.ToLower(strings.TrimSpace(id)), strings.ToLower(currency), marketChartDays(interval))
+	if granularity != "" {
+		requestURL += "&interval=" + granularity
 	}
 
-	var result struct {
-		Prices [][]float64 `json:"prices"`
+	bodyBytes, err := cg.get(requestURL)
+	if err != nil {
+		return models.MarketChart{}, err
 	}
 
+	var result models.MarketChart
 	if err := json.Unmarshal(bodyBytes, &result); err != nil {
-		return nil, fmt.Errorf("decode error: %v", err)
+		return models.MarketChart{}, fmt.Errorf("decode error: %v", err)
 	}
 
-	return result.Prices, nil
+	return result, nil
 }
 
 func (cg *CoinGecko) GetCoinOHLC(id, currency string, interval string) ([]models.OHLC, error) {
@@ -374,6 +401,15 @@ func SelectIntervalForRange(from, to time.Time) Interval {
 	return best
 }
 
+// marketChartDays returns the market_chart days parameter; the "max"
+// interval requests the full history.
+func marketChartDays(interval Interval) string {
+	if interval.Days <= 0 {
+		return "max"
+	}
+	return strconv.Itoa(interval.Days)
+}
+
 func effectiveDays(interval Interval) int {
 	if interval.Days <= 0 {
 		return ohlcMaxDays
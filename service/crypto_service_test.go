@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 	"time"
@@ -207,3 +208,55 @@ func TestAlertChecker_TriggersAndRemovesAlert(t *testing.T) {
 		t.Fatalf("expected triggered alert removed, got %d alerts", len(manager.GetAlerts()))
 	}
 }
+
+func TestCoinGecko_GetCoinMarketChartDecodesAllSeries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"prices":[[1700000000000,100]],"market_caps":[[1700000000000,2000000]],"total_volumes":[[1700000000000,5000]]}`))
+	}))
+	defer server.Close()
+
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	cg := NewCoinGecko()
+	chart, err := cg.GetCoinMarketChart("bitcoin", "usd", "7d")
+	if err != nil {
+		t.Fatalf("GetCoinMarketChart() error = %v", err)
+	}
+	if len(chart.Prices) != 1 || chart.MarketCaps[0][1] != 2000000 || chart.TotalVolumes[0][1] != 5000 {
+		t.Fatalf("unexpected market chart: %+v", chart)
+	}
+}
+
+func TestMarketChartDaysRequestsFullHistoryForMax(t *testing.T) {
+	if got := marketChartDays(selectInterval("max")); got != "max" {
+		t.Fatalf("marketChartDays(max) = %q, want max", got)
+	}
+	if got := marketChartDays(selectInterval("30d")); got != "30" {
+		t.Fatalf("marketChartDays(30d) = %q, want 30", got)
+	}
+}
+
+func TestCoinGecko_GetCoinMarketChartAutoOmitsInterval(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"prices":[[1700000000000,100]],"market_caps":[],"total_volumes":[]}`))
+	}))
+	defer server.Close()
+
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	cg := NewCoinGecko()
+	if _, err := cg.GetCoinMarketChartAuto("bitcoin", "usd", "30d"); err != nil {
+		t.Fatalf("GetCoinMarketChartAuto() error = %v", err)
+	}
+	if query.Get("days") != "30" || query.Has("interval") {
+		t.Fatalf("unexpected query: %v", query)
+	}
+}
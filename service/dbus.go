@@ -0,0 +1,367 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A minimal D-Bus client, just enough to call methods on the session bus
+// with EXTERNAL authentication over a unix socket.
+
+const (
+	dbusMethodCall   = 1
+	dbusMethodReturn = 2
+	dbusError        = 3
+
+	dbusFieldPath        = 1
+	dbusFieldInterface   = 2
+	dbusFieldMember      = 3
+	dbusFieldErrorName   = 4
+	dbusFieldReplySerial = 5
+	dbusFieldDestination = 6
+	dbusFieldSignature   = 8
+
+	dbusTimeout = 5 * time.Second
+)
+
+type dbusConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	serial uint32
+}
+
+// sessionBusAddress returns the unix socket of the session bus.
+func sessionBusAddress() (string, error) {
+	address := os.Getenv("DBUS_SESSION_BUS_ADDRESS")
+	if address == "" {
+		path := fmt.Sprintf("/run/user/%d/bus", os.Getuid())
+		if _, err := os.Stat(path); err != nil {
+			return "", fmt.Errorf("no D-Bus session bus (DBUS_SESSION_BUS_ADDRESS is not set)")
+		}
+		return path, nil
+	}
+	for _, entry := range strings.Split(address, ";") {
+		transport, params, ok := strings.Cut(entry, ":")
+		if !ok || transport != "unix" {
+			continue
+		}
+		for _, param := range strings.Split(params, ",") {
+			key, value, _ := strings.Cut(param, "=")
+			switch key {
+			case "path":
+				return value, nil
+			case "abstract":
+				return "@" + value, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("unsupported D-Bus address %q", address)
+}
+
+// dialDBus connects to the bus at the unix socket path, authenticates and
+// registers with the bus daemon.
+func dialDBus(path string) (*dbusConn, error) {
+	conn, err := net.DialTimeout("unix", path, dbusTimeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(dbusTimeout))
+	c := &dbusConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := fmt.Fprintf(conn, "\x00AUTH EXTERNAL %s\r\n", uid); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "OK") {
+		conn.Close()
+		return nil, fmt.Errorf("D-Bus authentication failed: %s", strings.TrimSpace(line))
+	}
+	if _, err := io.WriteString(conn, "BEGIN\r\n"); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if _, err := c.call("org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "Hello", "", nil); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *dbusConn) Close() error {
+	return c.conn.Close()
+}
+
+// call sends a method call with an encoded body and waits for its reply,
+// skipping signals. It returns the reply body.
+func (c *dbusConn) call(destination, path, iface, member, signature string, body []byte) ([]byte, error) {
+	c.serial++
+	serial := c.serial
+
+	fields := []dbusHeaderField{
+		{dbusFieldPath, "o", path},
+		{dbusFieldInterface, "s", iface},
+		{dbusFieldMember, "s", member},
+		{dbusFieldDestination, "s", destination},
+	}
+	if signature != "" {
+		fields = append(fields, dbusHeaderField{dbusFieldSignature, "g", signature})
+	}
+	if _, err := c.conn.Write(encodeDBusMessage(dbusMethodCall, serial, fields, body)); err != nil {
+		return nil, err
+	}
+
+	for {
+		msg, err := readDBusMessage(c.reader)
+		if err != nil {
+			return nil, err
+		}
+		if msg.replySerial != serial {
+			continue
+		}
+		if msg.kind == dbusError {
+			detail := msg.errorName
+			if text, ok := msg.firstString(); ok {
+				detail += ": " + text
+			}
+			return nil, fmt.Errorf("D-Bus error %s", detail)
+		}
+		return msg.body, nil
+	}
+}
+
+type dbusHeaderField struct {
+	code      byte
+	signature string
+	value     interface{}
+}
+
+func encodeDBusMessage(kind byte, serial uint32, fields []dbusHeaderField, body []byte) []byte {
+	e := &dbusEncoder{}
+	e.byte('l')
+	e.byte(kind)
+	e.byte(0)
+	e.byte(1)
+	e.uint32(uint32(len(body)))
+	e.uint32(serial)
+	e.array(8, func() {
+		for _, field := range fields {
+			e.align(8)
+			e.byte(field.code)
+			e.signature(field.signature)
+			switch v := field.value.(type) {
+			case string:
+				if field.signature == "g" {
+					e.signature(v)
+				} else {
+					e.string(v)
+				}
+			case uint32:
+				e.uint32(v)
+			}
+		}
+	})
+	e.align(8)
+	return append(e.buf.Bytes(), body...)
+}
+
+// dbusEncoder writes little-endian D-Bus values, aligned relative to the
+// start of the buffer.
+type dbusEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *dbusEncoder) align(n int) {
+	for e.buf.Len()%n != 0 {
+		e.buf.WriteByte(0)
+	}
+}
+
+func (e *dbusEncoder) byte(b byte) {
+	e.buf.WriteByte(b)
+}
+
+func (e *dbusEncoder) uint32(v uint32) {
+	e.align(4)
+	_ = binary.Write(&e.buf, binary.LittleEndian, v)
+}
+
+func (e *dbusEncoder) int32(v int32) {
+	e.align(4)
+	_ = binary.Write(&e.buf, binary.LittleEndian, v)
+}
+
+func (e *dbusEncoder) string(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf.WriteString(s)
+	e.buf.WriteByte(0)
+}
+
+func (e *dbusEncoder) signature(s string) {
+	e.buf.WriteByte(byte(len(s)))
+	e.buf.WriteString(s)
+	e.buf.WriteByte(0)
+}
+
+// array writes an array whose elements have the given alignment; contents
+// writes the elements and the length is patched in afterwards.
+func (e *dbusEncoder) array(elemAlign int, contents func()) {
+	e.align(4)
+	lengthAt := e.buf.Len()
+	e.buf.Write([]byte{0, 0, 0, 0})
+	e.align(elemAlign)
+	start := e.buf.Len()
+	contents()
+	binary.LittleEndian.PutUint32(e.buf.Bytes()[lengthAt:], uint32(e.buf.Len()-start))
+}
+
+type dbusMessage struct {
+	kind        byte
+	serial      uint32
+	replySerial uint32
+	member      string
+	errorName   string
+	signature   string
+	body        []byte
+}
+
+// firstString returns the body's first argument when it is a string.
+func (m dbusMessage) firstString() (string, bool) {
+	if !strings.HasPrefix(m.signature, "s") || len(m.body) < 4 {
+		return "", false
+	}
+	n := binary.LittleEndian.Uint32(m.body)
+	if int(n)+4 > len(m.body) {
+		return "", false
+	}
+	return string(m.body[4 : 4+n]), true
+}
+
+func readDBusMessage(r io.Reader) (dbusMessage, error) {
+	fixed := make([]byte, 16)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return dbusMessage{}, err
+	}
+	if fixed[0] != 'l' {
+		return dbusMessage{}, fmt.Errorf("unsupported D-Bus byte order %q", fixed[0])
+	}
+	bodyLen := binary.LittleEndian.Uint32(fixed[4:])
+	fieldsLen := binary.LittleEndian.Uint32(fixed[12:])
+	if bodyLen > 1<<24 || fieldsLen > 1<<16 {
+		return dbusMessage{}, fmt.Errorf("D-Bus message too large")
+	}
+
+	headerLen := 16 + int(fieldsLen)
+	padded := (headerLen + 7) &^ 7
+	rest := make([]byte, padded-16+int(bodyLen))
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return dbusMessage{}, err
+	}
+
+	msg := dbusMessage{
+		kind:   fixed[1],
+		serial: binary.LittleEndian.Uint32(fixed[8:]),
+		body:   rest[padded-16:],
+	}
+	d := &dbusDecoder{data: append(fixed, rest[:fieldsLen]...), pos: 16}
+	for d.pos < len(d.data) {
+		d.align(8)
+		code := d.byte()
+		signature := d.signature()
+		switch signature {
+		case "s", "o":
+			value := d.string()
+			switch code {
+			case dbusFieldMember:
+				msg.member = value
+			case dbusFieldErrorName:
+				msg.errorName = value
+			}
+		case "g":
+			value := d.signature()
+			if code == dbusFieldSignature {
+				msg.signature = value
+			}
+		case "u":
+			value := d.uint32()
+			if code == dbusFieldReplySerial {
+				msg.replySerial = value
+			}
+		default:
+			return dbusMessage{}, fmt.Errorf("unexpected D-Bus header field type %q", signature)
+		}
+		if d.err != nil {
+			return dbusMessage{}, d.err
+		}
+	}
+	return msg, nil
+}
+
+type dbusDecoder struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (d *dbusDecoder) align(n int) {
+	for d.pos%n != 0 {
+		d.pos++
+	}
+}
+
+func (d *dbusDecoder) need(n int) bool {
+	if d.err == nil && d.pos+n > len(d.data) {
+		d.err = fmt.Errorf("truncated D-Bus message")
+	}
+	return d.err == nil
+}
+
+func (d *dbusDecoder) byte() byte {
+	if !d.need(1) {
+		return 0
+	}
+	d.pos++
+	return d.data[d.pos-1]
+}
+
+func (d *dbusDecoder) uint32() uint32 {
+	d.align(4)
+	if !d.need(4) {
+		return 0
+	}
+	d.pos += 4
+	return binary.LittleEndian.Uint32(d.data[d.pos-4:])
+}
+
+func (d *dbusDecoder) string() string {
+	n := int(d.uint32())
+	if !d.need(n + 1) {
+		return ""
+	}
+	d.pos += n + 1
+	return string(d.data[d.pos-n-1 : d.pos-1])
+}
+
+func (d *dbusDecoder) signature() string {
+	n := int(d.byte())
+	if !d.need(n + 1) {
+		return ""
+	}
+	d.pos += n + 1
+	return string(d.data[d.pos-n-1 : d.pos-1])
+}
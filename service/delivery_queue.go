@@ -0,0 +1,50 @@
+package service
+
+import "sync"
+
+// deliveryQueue runs notification deliveries one at a time, in order, on a
+// goroutine of its own, so slow notifiers never hold up alert checks. The
+// goroutine exits once the queue is empty.
+type deliveryQueue struct {
+	mu      sync.Mutex
+	jobs    []func()
+	running bool
+	idle    chan struct{} // closed when the running goroutine exits
+}
+
+func (q *deliveryQueue) push(job func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs = append(q.jobs, job)
+	if !q.running {
+		q.running = true
+		q.idle = make(chan struct{})
+		go q.run(q.idle)
+	}
+}
+
+func (q *deliveryQueue) run(idle chan struct{}) {
+	defer close(idle)
+	for {
+		q.mu.Lock()
+		if len(q.jobs) == 0 {
+			q.running = false
+			q.mu.Unlock()
+			return
+		}
+		job := q.jobs[0]
+		q.jobs = q.jobs[1:]
+		q.mu.Unlock()
+		job()
+	}
+}
+
+// wait blocks until every queued delivery has run.
+func (q *deliveryQueue) wait() {
+	q.mu.Lock()
+	idle := q.idle
+	q.mu.Unlock()
+	if idle != nil {
+		<-idle
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+const desktopAppName = "crypto"
+
+// Freedesktop notification urgencies.
+var desktopUrgencies = map[string]byte{"low": 0, "normal": 1, "critical": 2}
+
+// DesktopNotifier shows triggered alerts as desktop notifications through
+// the freedesktop notification service on the session bus, falling back to
+// the notify-send command.
+type DesktopNotifier struct {
+	urgency  string
+	expireMS int32
+	// busAddress returns the session bus socket; replaced in tests.
+	busAddress func() (string, error)
+	// notifySend is the fallback command, empty when it is not installed.
+	notifySend string
+}
+
+func NewDesktopNotifier(cfg models.DesktopConfig) (*DesktopNotifier, error) {
+	urgency := strings.ToLower(cfg.Urgency)
+	if urgency == "" {
+		urgency = "normal"
+	}
+	if _, ok := desktopUrgencies[urgency]; !ok {
+		return nil, fmt.Errorf("unknown urgency %q (use low, normal or critical)", cfg.Urgency)
+	}
+	expire := int32(-1)
+	if cfg.TimeoutMS > 0 {
+		expire = int32(cfg.TimeoutMS)
+	}
+	notifySend, _ := exec.LookPath("notify-send")
+	return &DesktopNotifier{
+		urgency:    urgency,
+		expireMS:   expire,
+		busAddress: sessionBusAddress,
+		notifySend: notifySend,
+	}, nil
+}
+
+func (d *DesktopNotifier) Name() string {
+	return "desktop"
+}
+
+func (d *DesktopNotifier) Notify(n Notification) error {
+	data := newNotificationData(n)
+	summary := fmt.Sprintf("%s %s", strings.ToUpper(data.CoinID), data.Description)
+	body := n.Message
+
+	busErr := d.notifyDBus(summary, body)
+	if busErr == nil {
+		return nil
+	}
+	if d.notifySend == "" {
+		return busErr
+	}
+	args := []string{"--app-name=" + desktopAppName, "--urgency=" + d.urgency}
+	if d.expireMS > 0 {
+		args = append(args, "--expire-time="+strconv.Itoa(int(d.expireMS)))
+	}
+	out, err := exec.Command(d.notifySend, append(args, summary, body)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v; notify-send: %v %s", busErr, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// notifyDBus calls org.freedesktop.Notifications.Notify.
+func (d *DesktopNotifier) notifyDBus(summary, body string) error {
+	path, err := d.busAddress()
+	if err != nil {
+		return err
+	}
+	conn, err := dialDBus(path)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.call(
+		"org.freedesktop.Notifications",
+		"/org/freedesktop/Notifications",
+		"org.freedesktop.Notifications",
+		"Notify",
+		"susssasa{sv}i",
+		encodeNotifyArgs(summary, body, desktopUrgencies[d.urgency], d.expireMS),
+	)
+	return err
+}
+
+// encodeNotifyArgs encodes the Notify arguments: app name, replaced id,
+// icon, summary, body, actions, hints and expiry.
+func encodeNotifyArgs(summary, body string, urgency byte, expireMS int32) []byte {
+	e := &dbusEncoder{}
+	e.string(desktopAppName)
+	e.uint32(0)
+	e.string("")
+	e.string(summary)
+	e.string(body)
+	e.array(4, func() {})
+	e.array(8, func() {
+		e.align(8)
+		e.string("urgency")
+		e.signature("y")
+		e.byte(urgency)
+	})
+	e.int32(expireMS)
+	return e.buf.Bytes()
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// fakeBus is a session bus stand-in that answers Hello and Notify, or fails
+// Notify with an error when failNotify is set.
+type fakeBus struct {
+	path       string
+	failNotify bool
+	mu         sync.Mutex
+	auth       string
+	calls      []dbusMessage
+}
+
+func startFakeBus(t *testing.T, failNotify bool) *fakeBus {
+	t.Helper()
+	bus := &fakeBus{path: filepath.Join(t.TempDir(), "bus"), failNotify: failNotify}
+	listener, err := net.Listen("unix", bus.path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go bus.serve(conn)
+		}
+	}()
+	return bus
+}
+
+func (b *fakeBus) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	auth, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	b.mu.Lock()
+	b.auth = auth
+	b.mu.Unlock()
+	_, _ = conn.Write([]byte("OK 0123456789abcdef\r\n"))
+	if begin, err := reader.ReadString('\n'); err != nil || begin != "BEGIN\r\n" {
+		return
+	}
+
+	var serial uint32 = 100
+	reply := func(kind byte, to uint32, fields []dbusHeaderField, signature string, body []byte) {
+		serial++
+		fields = append(fields, dbusHeaderField{dbusFieldReplySerial, "u", to})
+		if signature != "" {
+			fields = append(fields, dbusHeaderField{dbusFieldSignature, "g", signature})
+		}
+		_, _ = conn.Write(encodeDBusMessage(kind, serial, fields, body))
+	}
+	for {
+		msg, err := readDBusMessage(reader)
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.calls = append(b.calls, msg)
+		b.mu.Unlock()
+
+		switch msg.member {
+		case "Hello":
+			// A signal first, which the client must skip
+			serial++
+			_, _ = conn.Write(encodeDBusMessage(4, serial, []dbusHeaderField{{dbusFieldMember, "s", "NameAcquired"}}, nil))
+			name := &dbusEncoder{}
+			name.string(":1.42")
+			reply(dbusMethodReturn, msg.serial, nil, "s", name.buf.Bytes())
+		case "Notify":
+			if b.failNotify {
+				text := &dbusEncoder{}
+				text.string("no notification daemon")
+				reply(dbusError, msg.serial, []dbusHeaderField{{dbusFieldErrorName, "s", "org.freedesktop.DBus.Error.ServiceUnknown"}}, "s", text.buf.Bytes())
+				continue
+			}
+			id := &dbusEncoder{}
+			id.uint32(7)
+			reply(dbusMethodReturn, msg.serial, nil, "u", id.buf.Bytes())
+		}
+	}
+}
+
+func (b *fakeBus) received() []dbusMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]dbusMessage(nil), b.calls...)
+}
+
+func newTestDesktopNotifier(t *testing.T, busPath, notifySend string) *DesktopNotifier {
+	t.Helper()
+	notifier, err := NewDesktopNotifier(models.DesktopConfig{Urgency: "critical", TimeoutMS: 5000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	notifier.busAddress = func() (string, error) { return busPath, nil }
+	notifier.notifySend = notifySend
+	return notifier
+}
+
+func TestDesktopNotifier_DBus(t *testing.T) {
+	bus := startFakeBus(t, false)
+	if err := newTestDesktopNotifier(t, bus.path, "").Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	calls := bus.received()
+	if len(calls) != 2 || calls[0].member != "Hello" || calls[1].member != "Notify" {
+		t.Fatalf("calls = %+v", calls)
+	}
+	if !strings.HasPrefix(bus.auth, "\x00AUTH EXTERNAL ") {
+		t.Fatalf("auth = %q", bus.auth)
+	}
+
+	notify := calls[1]
+	if notify.signature != "susssasa{sv}i" {
+		t.Fatalf("signature = %q", notify.signature)
+	}
+	d := &dbusDecoder{data: notify.body}
+	app, id, icon, summary, body := d.string(), d.uint32(), d.string(), d.string(), d.string()
+	if d.err != nil || app != "crypto" || id != 0 || icon != "" || summary != "BITCOIN above $50000.00" || body != testNotification().Message {
+		t.Fatalf("Notify args = %q %d %q %q %q (%v)", app, id, icon, summary, body, d.err)
+	}
+	if !strings.Contains(string(notify.body), "urgency") {
+		t.Fatal("missing urgency hint")
+	}
+}
+
+func TestDesktopNotifier_FallsBackToNotifySend(t *testing.T) {
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	script := filepath.Join(dir, "notify-send")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nprintf '%s\\n' \"$@\" > "+argsFile+"\n"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	bus := startFakeBus(t, true)
+	if err := newTestDesktopNotifier(t, bus.path, script).Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "--app-name=crypto\n--urgency=critical\n--expire-time=5000\nBITCOIN above $50000.00\n" + testNotification().Message + "\n"
+	if string(args) != want {
+		t.Fatalf("notify-send args = %q, want %q", args, want)
+	}
+
+	err = newTestDesktopNotifier(t, filepath.Join(dir, "missing"), "").Notify(testNotification())
+	if err == nil {
+		t.Fatal("expected an error without a bus or notify-send")
+	}
+}
+
+func TestSessionBusAddress(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "tcp:host=localhost,port=1;unix:path=/run/user/1000/bus,guid=abc")
+	if got, err := sessionBusAddress(); err != nil || got != "/run/user/1000/bus" {
+		t.Fatalf("sessionBusAddress() = %q, %v", got, err)
+	}
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:abstract=/tmp/dbus-xyz,guid=abc")
+	if got, err := sessionBusAddress(); err != nil || got != "@/tmp/dbus-xyz" {
+		t.Fatalf("sessionBusAddress() = %q, %v", got, err)
+	}
+	if _, err := NewDesktopNotifier(models.DesktopConfig{Urgency: "urgent"}); err == nil {
+		t.Fatal("expected an unknown urgency error")
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+const (
+	defaultEmailSubject = `{{if eq (len .Alerts) 1}}{{with index .Alerts 0}}Crypto alert: {{upper .CoinID}} {{.Description}}{{end}}{{else}}Crypto alerts: {{len .Alerts}} triggered{{end}}`
+	defaultEmailBody    = `{{range .Alerts}}{{upper .CoinID}}: {{.Description}}
+  Current: {{.PriceText}} {{upper .Currency}}
+  Time:    {{.TriggeredAt.Format "2006-01-02 15:04:05 MST"}}
+  {{.Message}}
+
+{{end}}`
+	smtpTimeout = 30 * time.Second
+)
+
+// EmailNotifier sends triggered alerts over SMTP, one email per check cycle.
+type EmailNotifier struct {
+	cfg       models.EmailConfig
+	subject   *template.Template
+	body      *template.Template
+	tlsConfig *tls.Config
+}
+
+// emailData is the value email templates are executed with.
+type emailData struct {
+	Alerts []notificationData
+}
+
+func NewEmailNotifier(cfg models.EmailConfig) (*EmailNotifier, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("SMTP host is required")
+	}
+	switch cfg.SecurityOrDefault() {
+	case models.SMTPSecurityStartTLS, models.SMTPSecurityTLS, models.SMTPSecurityNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP security %q (use starttls, tls or none)", cfg.Security)
+	}
+	if _, err := mail.ParseAddress(cfg.From); err != nil {
+		return nil, fmt.Errorf("invalid from address %q", cfg.From)
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("at least one recipient is required")
+	}
+	for _, to := range cfg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return nil, fmt.Errorf("invalid recipient %q", to)
+		}
+	}
+
+	e := &EmailNotifier{cfg: cfg, tlsConfig: &tls.Config{ServerName: cfg.Host}}
+	var err error
+	if e.subject, err = parseEmailTemplate("subject", cfg.Subject, defaultEmailSubject); err != nil {
+		return nil, err
+	}
+	if e.body, err = parseEmailTemplate("body", cfg.Body, defaultEmailBody); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func parseEmailTemplate(name, text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func (e *EmailNotifier) Name() string {
+	return "email"
+}
+
+func (e *EmailNotifier) Notify(n Notification) error {
+	return e.NotifyBatch([]Notification{n})
+}
+
+// NotifyBatch sends all notifications in one email.
+func (e *EmailNotifier) NotifyBatch(ns []Notification) error {
+	if len(ns) == 0 {
+		return nil
+	}
+	message, err := e.message(ns, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := e.send(message); err != nil {
+		countError(ProviderSMTP)
+		return err
+	}
+	return nil
+}
+
+func (e *EmailNotifier) message(ns []Notification, now time.Time) ([]byte, error) {
+	data := emailData{Alerts: make([]notificationData, 0, len(ns))}
+	for _, n := range ns {
+		data.Alerts = append(data.Alerts, newNotificationData(n))
+	}
+
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, data); err != nil {
+		return nil, fmt.Errorf("email subject template: %w", err)
+	}
+	if err := e.body.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("email body template: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String())))
+	fmt.Fprintf(&msg, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&msg)
+	if _, err := qp.Write(bytes.ReplaceAll(body.Bytes(), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
+
+func (e *EmailNotifier) send(message []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.PortOrDefault()))
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	countRequest(ProviderSMTP)
+	if e.cfg.SecurityOrDefault() == models.SMTPSecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, e.tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.cfg.SecurityOrDefault() == models.SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("SMTP server %s does not support STARTTLS", e.cfg.Host)
+		}
+		if err := client.StartTLS(e.tlsConfig); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return err
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.cfg.From)
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		addr, _ := mail.ParseAddress(to)
+		if err := client.Rcpt(addr.Address); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
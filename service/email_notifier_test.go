@@ -0,0 +1,344 @@
+package service
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime/quotedprintable"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+type smtpMessage struct {
+	from string
+	to   []string
+	auth string
+	tls  bool
+	data string
+}
+
+// fakeSMTP is a minimal SMTP server for tests. With a TLS config it offers
+// STARTTLS, or serves TLS from the start when implicit is set.
+type fakeSMTP struct {
+	listener net.Listener
+	tls      *tls.Config
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func startFakeSMTP(t *testing.T, tlsConfig *tls.Config, implicit bool) *fakeSMTP {
+	t.Helper()
+	var listener net.Listener
+	var err error
+	if implicit {
+		listener, err = tls.Listen("tcp", "127.0.0.1:0", tlsConfig)
+	} else {
+		listener, err = net.Listen("tcp", "127.0.0.1:0")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{listener: listener, tls: tlsConfig}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn, implicit)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTP) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTP) received() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTP) serve(conn net.Conn, secure bool) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	msg := smtpMessage{tls: secure}
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO", "HELO":
+			if s.tls != nil && !msg.tls {
+				_ = tp.PrintfLine("250-fake")
+				_ = tp.PrintfLine("250-STARTTLS")
+			} else {
+				_ = tp.PrintfLine("250-fake")
+			}
+			_ = tp.PrintfLine("250 AUTH PLAIN")
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			tp = textproto.NewConn(tlsConn)
+			msg.tls = true
+		case "AUTH":
+			parts := strings.Fields(line)
+			decoded, _ := base64.StdEncoding.DecodeString(parts[len(parts)-1])
+			msg.auth = string(decoded)
+			_ = tp.PrintfLine("235 ok")
+		case "MAIL":
+			msg.from = strings.Trim(strings.SplitN(line, ":", 2)[1], "<> ")
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			msg.to = append(msg.to, strings.Trim(strings.SplitN(line, ":", 2)[1], "<> "))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			msg.data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+// testTLSConfigs returns a server config with a self-signed certificate
+// for 127.0.0.1 and a client config trusting it.
+func testTLSConfigs(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{ServerName: "127.0.0.1", RootCAs: pool}
+	return server, client
+}
+
+func newTestEmailNotifier(t *testing.T, cfg models.EmailConfig, clientTLS *tls.Config) *EmailNotifier {
+	t.Helper()
+	notifier, err := NewEmailNotifier(cfg)
+	if err != nil {
+		t.Fatalf("NewEmailNotifier() error = %v", err)
+	}
+	if clientTLS != nil {
+		notifier.tlsConfig = clientTLS
+	}
+	return notifier
+}
+
+// decodeBody returns the decoded body and the Subject header of a message
+// as read by textproto, which turns CRLF into LF.
+func decodeBody(t *testing.T, data string) (subject, body string) {
+	t.Helper()
+	headers, raw, _ := strings.Cut(data, "\n\n")
+	for _, line := range strings.Split(headers, "\n") {
+		if strings.HasPrefix(line, "Subject: ") {
+			subject = strings.TrimPrefix(line, "Subject: ")
+		}
+	}
+	decoded, err := io.ReadAll(quotedprintable.NewReader(strings.NewReader(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subject, string(decoded)
+}
+
+func TestEmailNotifier_StartTLSAuthAndBatch(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+	server := startFakeSMTP(t, serverTLS, false)
+	notifier := newTestEmailNotifier(t, models.EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Username: "user",
+		Password: "pass",
+		From:     "Crypto <alerts@example.com>",
+		To:       []string{"me@example.com", "you@example.com"},
+	}, clientTLS)
+
+	second := testNotification()
+	second.Alert = models.Alert{CoinID: "ethereum", Condition: models.ConditionChange, Percent: -5, Window: "1h", Currency: "eur"}
+	second.Price = 2000
+	if err := notifier.NotifyBatch([]Notification{testNotification(), second}); err != nil {
+		t.Fatalf("NotifyBatch() error = %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 {
+		t.Fatalf("got %d emails, want 1", len(messages))
+	}
+	msg := messages[0]
+	if !msg.tls || msg.auth != "\x00user\x00pass" || msg.from != "alerts@example.com" || strings.Join(msg.to, ",") != "me@example.com,you@example.com" {
+		t.Fatalf("unexpected envelope %+v", msg)
+	}
+	subject, body := decodeBody(t, msg.data)
+	if subject != "Crypto alerts: 2 triggered" {
+		t.Fatalf("subject = %q", subject)
+	}
+	for _, want := range []string{"BITCOIN: above $50000.00", "Current: $50123.45 USD", "ETHEREUM: -5% in 1h", "Current: EUR2000.00 EUR"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("body missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestEmailNotifier_ImplicitTLSAndTemplates(t *testing.T) {
+	serverTLS, clientTLS := testTLSConfigs(t)
+	server := startFakeSMTP(t, serverTLS, true)
+	notifier := newTestEmailNotifier(t, models.EmailConfig{
+		Host:     "127.0.0.1",
+		Port:     server.port(),
+		Security: "tls",
+		From:     "alerts@example.com",
+		To:       []string{"me@example.com"},
+		Subject:  `{{range .Alerts}}{{upper .CoinID}} hit {{.PriceText}}{{end}}`,
+		Body:     `{{range .Alerts}}{{.Condition}} target {{.Target}}{{end}}`,
+	}, clientTLS)
+	if err := notifier.Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	messages := server.received()
+	if len(messages) != 1 || !messages[0].tls {
+		t.Fatalf("messages = %+v", messages)
+	}
+	subject, body := decodeBody(t, messages[0].data)
+	if subject != "BITCOIN hit $50123.45" || strings.TrimSpace(body) != "above target 50000" {
+		t.Fatalf("subject = %q, body = %q", subject, body)
+	}
+}
+
+func TestEmailNotifier_StartTLSRequired(t *testing.T) {
+	server := startFakeSMTP(t, nil, false)
+	notifier := newTestEmailNotifier(t, models.EmailConfig{
+		Host: "127.0.0.1", Port: server.port(), From: "a@example.com", To: []string{"b@example.com"},
+	}, nil)
+	if err := notifier.Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("expected a STARTTLS error, got %v", err)
+	}
+
+	notifier = newTestEmailNotifier(t, models.EmailConfig{
+		Host: "127.0.0.1", Port: server.port(), Security: "none", From: "a@example.com", To: []string{"b@example.com"},
+	}, nil)
+	if err := notifier.Notify(testNotification()); err != nil {
+		t.Fatalf("plain SMTP error = %v", err)
+	}
+	if messages := server.received(); len(messages) != 1 || messages[0].tls {
+		t.Fatalf("messages = %+v", messages)
+	}
+}
+
+func TestNewEmailNotifier_Validation(t *testing.T) {
+	valid := models.EmailConfig{Host: "smtp.example.com", From: "a@example.com", To: []string{"b@example.com"}}
+	if _, err := NewEmailNotifier(valid); err != nil {
+		t.Fatalf("NewEmailNotifier() error = %v", err)
+	}
+	invalid := []func(*models.EmailConfig){
+		func(c *models.EmailConfig) { c.Host = "" },
+		func(c *models.EmailConfig) { c.From = "nobody" },
+		func(c *models.EmailConfig) { c.To = nil },
+		func(c *models.EmailConfig) { c.Security = "ssl3" },
+		func(c *models.EmailConfig) { c.Body = "{{.Missing" },
+	}
+	for i, mutate := range invalid {
+		cfg := valid
+		mutate(&cfg)
+		if _, err := NewEmailNotifier(cfg); err == nil {
+			t.Fatalf("case %d: expected an error", i)
+		}
+	}
+	if port := (models.EmailConfig{Security: "tls"}).PortOrDefault(); port != 465 {
+		t.Fatalf("implicit TLS port = %d", port)
+	}
+	if port := (models.EmailConfig{}).PortOrDefault(); port != 587 {
+		t.Fatalf("STARTTLS port = %d", port)
+	}
+}
+
+type recordingBatchNotifier struct {
+	recordingNotifier
+	batches [][]Notification
+}
+
+func (r *recordingBatchNotifier) NotifyBatch(ns []Notification) error {
+	r.batches = append(r.batches, ns)
+	return nil
+}
+
+func TestAlertChecker_BatchesTriggersPerCycle(t *testing.T) {
+	manager := models.NewAlertManager(t.TempDir())
+	checker := NewAlertChecker(manager)
+	batcher := &recordingBatchNotifier{}
+	single := &recordingNotifier{}
+	checker.SetNotifiers([]Notifier{batcher, single})
+	for i := 1; i <= 3; i++ {
+		_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: float64(i)})
+	}
+
+	for _, alert := range manager.GetAlerts() {
+		alert := alert
+		checker.settle(alert, true, false, 10, func() string { return "fired " + strconv.FormatFloat(alert.Price, 'f', 0, 64) })
+	}
+	checker.flushBatch()
+	checker.flushBatch()
+	checker.deliveries.wait()
+
+	if len(batcher.got) != 0 || len(batcher.batches) != 1 || len(batcher.batches[0]) != 3 {
+		t.Fatalf("batch notifier got %d single and %d batches", len(batcher.got), len(batcher.batches))
+	}
+	if len(single.got) != 3 {
+		t.Fatalf("plain notifier got %d notifications, want 3", len(single.got))
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// execHookWaitDelay is how long a timed-out hook may keep its output open
+// after it was killed.
+const execHookWaitDelay = 100 * time.Millisecond
+
+// ExecNotifier runs a shell command for each triggered alert. Alert
+// details are passed as CRYPTO_ALERT_* environment variables and as the
+// default JSON payload on stdin.
+type ExecNotifier struct {
+	command string
+	timeout time.Duration
+}
+
+func NewExecNotifier(cfg models.ExecHookConfig) (*ExecNotifier, error) {
+	if strings.TrimSpace(cfg.Command) == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	timeout, err := cfg.TimeoutOrDefault()
+	if err != nil {
+		return nil, err
+	}
+	return &ExecNotifier{command: cfg.Command, timeout: timeout}, nil
+}
+
+func (e *ExecNotifier) Name() string {
+	return "exec hook"
+}
+
+func (e *ExecNotifier) Notify(n Notification) error {
+	data := newNotificationData(n)
+	payload, err := data.payload()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", e.command)
+	// Run the hook in its own process group and kill the whole group on
+	// timeout; killing only the shell would leave its children holding
+	// the output pipe
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = execHookWaitDelay
+	cmd.Env = append(os.Environ(), execHookEnv(data)...)
+	cmd.Stdin = bytes.NewReader(payload)
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%q timed out after %s", e.command, e.timeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(string(out))
+		if len(output) > 200 {
+			output = output[:200] + "..."
+		}
+		return fmt.Errorf("%q: %v %s", e.command, err, output)
+	}
+	return nil
+}
+
+func execHookEnv(data notificationData) []string {
+	return []string{
+		"CRYPTO_ALERT_COIN=" + data.CoinID,
+		"CRYPTO_ALERT_CONDITION=" + data.Condition,
+		"CRYPTO_ALERT_DESCRIPTION=" + data.Description,
+		"CRYPTO_ALERT_TARGET=" + strconv.FormatFloat(data.Target, 'f', -1, 64),
+		"CRYPTO_ALERT_PRICE=" + strconv.FormatFloat(data.Price, 'f', -1, 64),
+		"CRYPTO_ALERT_CURRENCY=" + data.Currency,
+		"CRYPTO_ALERT_MESSAGE=" + data.Message,
+		"CRYPTO_ALERT_TIME=" + data.TriggeredAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+func TestExecNotifier_PassesEnvAndJSON(t *testing.T) {
+	dir := t.TempDir()
+	envFile, stdinFile := filepath.Join(dir, "env"), filepath.Join(dir, "stdin.json")
+	notifier, err := NewExecNotifier(models.ExecHookConfig{
+		Command: `printf '%s|%s|%s|%s' "$CRYPTO_ALERT_COIN" "$CRYPTO_ALERT_CONDITION" "$CRYPTO_ALERT_TARGET" "$CRYPTO_ALERT_PRICE" > ` + envFile + ` && cat > ` + stdinFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	env, err := os.ReadFile(envFile)
+	if err != nil || string(env) != "bitcoin|above|50000|50123.45" {
+		t.Fatalf("env = %q, %v", env, err)
+	}
+	stdin, err := os.ReadFile(stdinFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Message string       `json:"message"`
+		Price   float64      `json:"price"`
+		Alert   models.Alert `json:"alert"`
+	}
+	if err := json.Unmarshal(stdin, &payload); err != nil || payload.Price != 50123.45 || payload.Alert.CoinID != "bitcoin" {
+		t.Fatalf("stdin payload = %s, %v", stdin, err)
+	}
+}
+
+func TestExecNotifier_Errors(t *testing.T) {
+	notifier, _ := NewExecNotifier(models.ExecHookConfig{Command: "echo broken >&2; exit 3"})
+	if err := notifier.Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Fatalf("expected the command output in the error, got %v", err)
+	}
+
+	// The hook's children are killed with it, so the timeout holds even
+	// when they keep the output open
+	notifier, _ = NewExecNotifier(models.ExecHookConfig{Command: "sleep 5; true", Timeout: "50ms"})
+	start := time.Now()
+	if err := notifier.Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("a 50ms timeout returned after %s", elapsed)
+	}
+
+	for _, cfg := range []models.ExecHookConfig{{Command: " "}, {Command: "true", Timeout: "soon"}} {
+		if _, err := NewExecNotifier(cfg); err == nil {
+			t.Fatalf("NewExecNotifier(%+v) should fail", cfg)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metric types of the Prometheus text format.
+const (
+	MetricGauge   = "gauge"
+	MetricCounter = "counter"
+)
+
+const metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MetricsWriter builds a page in the Prometheus text exposition format.
+// Each family's samples must follow its Family call.
+type MetricsWriter struct {
+	buf bytes.Buffer
+}
+
+// Family starts a metric family with its HELP and TYPE lines.
+func (m *MetricsWriter) Family(name, kind, help string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	m.buf.WriteString("# HELP " + name + " " + help + "\n")
+	m.buf.WriteString("# TYPE " + name + " " + kind + "\n")
+}
+
+// Sample writes one sample. labels are name, value pairs.
+func (m *MetricsWriter) Sample(name string, value float64, labels ...string) {
+	m.buf.WriteString(name)
+	if len(labels) > 0 {
+		m.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.buf.WriteByte(',')
+			}
+			m.buf.WriteString(labels[i] + `="` + escapeLabelValue(labels[i+1]) + `"`)
+		}
+		m.buf.WriteByte('}')
+	}
+	m.buf.WriteString(" " + strconv.FormatFloat(value, 'g', -1, 64) + "\n")
+}
+
+// Bytes returns the page written so far.
+func (m *MetricsWriter) Bytes() []byte {
+	return m.buf.Bytes()
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// WriteRequestMetrics writes the request, error and retry counts of each
+// provider and the time spent waiting for the rate limiter.
+func WriteRequestMetrics(m *MetricsWriter) {
+	stats := ProviderStats()
+	providers := make([]string, 0, len(stats))
+	for provider := range stats {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+
+	m.Family("crypto_api_requests_total", MetricCounter, "Requests made to each provider, including retries.")
+	for _, provider := range providers {
+		m.Sample("crypto_api_requests_total", float64(stats[provider].Requests), "provider", provider)
+	}
+	m.Family("crypto_api_errors_total", MetricCounter, "Requests to each provider that failed.")
+	for _, provider := range providers {
+		m.Sample("crypto_api_errors_total", float64(stats[provider].Errors), "provider", provider)
+	}
+	m.Family("crypto_api_retries_total", MetricCounter, "Requests to each provider repeated after a failure.")
+	for _, provider := range providers {
+		m.Sample("crypto_api_retries_total", float64(stats[provider].Retries), "provider", provider)
+	}
+	m.Family("crypto_rate_limit_wait_seconds_total", MetricCounter, "Time CoinGecko requests spent waiting for the rate limiter.")
+	m.Sample("crypto_rate_limit_wait_seconds_total", RateLimitWait().Seconds())
+}
+
+// MetricsServer serves /metrics over HTTP.
+type MetricsServer struct {
+	listener net.Listener
+	server   *http.Server
+}
+
+// ListenMetrics serves the page written by collect on /metrics at addr
+// until Close. collect runs on the HTTP server's goroutines.
+func ListenMetrics(addr string, collect func(*MetricsWriter)) (*MetricsServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var m MetricsWriter
+		collect(&m)
+		w.Header().Set("Content-Type", metricsContentType)
+		_, _ = w.Write(m.Bytes())
+	})
+	s := &MetricsServer{
+		listener: listener,
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second},
+	}
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+	return s, nil
+}
+
+// Addr returns the address the server listens on.
+func (s *MetricsServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Close stops the server, letting scrapes in progress finish.
+func (s *MetricsServer) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
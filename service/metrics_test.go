@@ -0,0 +1,143 @@
+package service
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	var m MetricsWriter
+	m.Family("crypto_coin_price", MetricGauge, "Latest price.\nIn the quote currency.")
+	m.Sample("crypto_coin_price", 64250.5, "coin", "bitcoin", "currency", "usd")
+	m.Sample("crypto_coin_price", 0.0000012, "coin", `odd"coin\`, "currency", "usd")
+	m.Family("crypto_up", MetricGauge, "Always 1.")
+	m.Sample("crypto_up", 1)
+
+	want := `# HELP crypto_coin_price Latest price.\nIn the quote currency.
+# TYPE crypto_coin_price gauge
+crypto_coin_price{coin="bitcoin",currency="usd"} 64250.5
+crypto_coin_price{coin="odd\"coin\\",currency="usd"} 1.2e-06
+# HELP crypto_up Always 1.
+# TYPE crypto_up gauge
+crypto_up 1
+`
+	if got := string(m.Bytes()); got != want {
+		t.Fatalf("page =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestListenMetrics(t *testing.T) {
+	server, err := ListenMetrics("127.0.0.1:0", func(m *MetricsWriter) {
+		m.Family("crypto_up", MetricGauge, "Always 1.")
+		m.Sample("crypto_up", 1)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + server.Addr() + "/metrics"
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != metricsContentType ||
+		!strings.HasSuffix(string(body), "crypto_up 1\n") {
+		t.Fatalf("GET /metrics = %s %q: %s", resp.Status, resp.Header.Get("Content-Type"), body)
+	}
+
+	resp, err = http.Post(url, "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("POST /metrics = %s", resp.Status)
+	}
+
+	if err := server.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get(url); err == nil {
+		t.Fatal("expected scraping a closed server to fail")
+	}
+}
+
+func TestAlertChecker_TrackPricesMetrics(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first request fails and is retried
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		switch r.URL.Path {
+		case "/simple/price":
+			_, _ = w.Write([]byte(`{"bitcoin":{"usd":60000}}`))
+		case "/coins/markets":
+			_, _ = w.Write([]byte(`[{"id":"bitcoin","current_price":60000},{"id":"solana","current_price":150}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = server.URL
+
+	manager := models.NewAlertManager(t.TempDir())
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 50000, Currency: "usd"})
+	checker := NewAlertChecker(manager)
+	checker.SetInterval(time.Hour)
+	checker.TrackPrices("USD", func() (*models.Portfolio, []string, error) {
+		portfolio := models.NewPortfolio("")
+		_ = portfolio.AddTransaction(models.Transaction{CoinID: "bitcoin", Amount: 0.5, Price: 40000, Type: "buy", Currency: "usd"})
+		return portfolio, []string{"solana"}, nil
+	})
+
+	before := ProviderStats()[ProviderCoinGecko]
+	checker.RunOnce()
+	after := ProviderStats()[ProviderCoinGecko]
+	if after.Requests-before.Requests != 3 || after.Errors-before.Errors != 1 || after.Retries-before.Retries != 1 {
+		t.Fatalf("CoinGecko stats went from %+v to %+v", before, after)
+	}
+
+	var m MetricsWriter
+	checker.WriteMetrics(&m)
+	page := string(m.Bytes())
+	for _, want := range []string{
+		`crypto_coin_price{coin="bitcoin",currency="usd"} 60000` + "\n",
+		`crypto_coin_price{coin="solana",currency="usd"} 150` + "\n",
+		`crypto_portfolio_value{currency="usd"} 30000` + "\n",
+		`crypto_portfolio_coin_value{coin="bitcoin",currency="usd"} 30000` + "\n",
+		`crypto_portfolio_cost_basis{currency="usd"} 20000` + "\n",
+		`crypto_portfolio_unrealized_pnl{currency="usd"} 10000` + "\n",
+		`crypto_alert_triggers_total{coin="bitcoin",condition="above"} 1` + "\n",
+		"crypto_alert_check_errors_total 0\n",
+		"# TYPE crypto_alert_last_check_timestamp_seconds gauge\n",
+	} {
+		if !strings.Contains(page, want) {
+			t.Fatalf("metrics are missing %q:\n%s", want, page)
+		}
+	}
+
+	// Prices not seen for a few intervals drop out
+	checker.prunePrices(time.Now().Add(4 * time.Hour))
+	m = MetricsWriter{}
+	checker.WriteMetrics(&m)
+	if strings.Contains(string(m.Bytes()), "crypto_coin_price{") {
+		t.Fatalf("stale prices were kept:\n%s", m.Bytes())
+	}
+}
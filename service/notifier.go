@@ -0,0 +1,139 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+// Notification is a triggered alert handed to notifiers.
+type Notification struct {
+	Alert models.Alert
+	// Price is the value that triggered the alert, as in models.AlertTrigger.
+	Price       float64
+	Message     string
+	TriggeredAt time.Time
+}
+
+// Notifier delivers triggered alerts somewhere besides the terminal.
+type Notifier interface {
+	// Name identifies the notifier in error messages.
+	Name() string
+	Notify(n Notification) error
+}
+
+// BatchNotifier is implemented by notifiers that deliver all triggers of a
+// check cycle together. The checker calls NotifyBatch once per cycle instead
+// of Notify for each trigger.
+type BatchNotifier interface {
+	Notifier
+	NotifyBatch(ns []Notification) error
+}
+
+// notificationData is the value notification templates are executed with.
+type notificationData struct {
+	Message     string
+	CoinID      string
+	Condition   string
+	Description string
+	Target      float64
+	Price       float64
+	PriceText   string
+	Currency    string
+	TriggeredAt time.Time
+	Alert       models.Alert
+}
+
+func newNotificationData(n Notification) notificationData {
+	currency := utils.NormalizeCurrency(n.Alert.Currency)
+	currencySymbol := utils.CurrencySymbol(currency)
+	priceText := fmt.Sprintf("%s%.2f", currencySymbol, n.Price)
+	if n.Alert.Condition == models.ConditionWeightAbove {
+		priceText = fmt.Sprintf("%.1f%%", n.Price)
+	}
+	return notificationData{
+		Message:     n.Message,
+		CoinID:      n.Alert.CoinID,
+		Condition:   n.Alert.Condition,
+		Description: n.Alert.Describe(currencySymbol),
+		Target:      n.Alert.Price,
+		Price:       n.Price,
+		PriceText:   priceText,
+		Currency:    currency,
+		TriggeredAt: n.TriggeredAt,
+		Alert:       n.Alert,
+	}
+}
+
+// payload is the default JSON description of a notification, posted by
+// webhooks and piped to exec hooks.
+func (d notificationData) payload() ([]byte, error) {
+	return json.Marshal(struct {
+		Message     string       `json:"message"`
+		Description string       `json:"description"`
+		Price       float64      `json:"price"`
+		Currency    string       `json:"currency"`
+		TriggeredAt time.Time    `json:"triggered_at"`
+		Alert       models.Alert `json:"alert"`
+	}{d.Message, d.Description, d.Price, d.Currency, d.TriggeredAt, d.Alert})
+}
+
+// templateFuncs are available in notification templates.
+var templateFuncs = map[string]interface{}{
+	"json":  jsonValue,
+	"upper": strings.ToUpper,
+}
+
+// NewNotifiers builds the notifiers configured in cfg. Invalid entries are
+// skipped and reported in the returned error, so one bad webhook does not
+// silence the others.
+func NewNotifiers(cfg models.NotificationConfig) ([]Notifier, error) {
+	var notifiers []Notifier
+	var errs []error
+	for i, webhook := range cfg.Webhooks {
+		notifier, err := NewWebhookNotifier(webhook)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %d: %w", i+1, err))
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	if cfg.Email != nil {
+		notifier, err := NewEmailNotifier(*cfg.Email)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("email: %w", err))
+		} else {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	if cfg.Desktop != nil {
+		notifier, err := NewDesktopNotifier(*cfg.Desktop)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("desktop: %w", err))
+		} else {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	for i, hook := range cfg.Exec {
+		notifier, err := NewExecNotifier(hook)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("exec hook %d: %w", i+1, err))
+			continue
+		}
+		notifiers = append(notifiers, notifier)
+	}
+	if cfg.Telegram != nil {
+		notifier, err := NewTelegramNotifier(*cfg.Telegram)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("telegram: %w", err))
+		} else {
+			notifiers = append(notifiers, notifier)
+		}
+	}
+	return notifiers, errors.Join(errs...)
+}
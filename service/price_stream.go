@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPriceStreamURL is Binance's public market data stream endpoint.
+const DefaultPriceStreamURL = "wss://stream.binance.com:9443"
+
+// Binance sends a mini ticker about every second; a connection quiet for
+// much longer than that is considered dead.
+const priceStreamIdleTimeout = time.Minute
+
+// PriceTick is a live price for a coin from an exchange stream.
+type PriceTick struct {
+	CoinID   string
+	Currency string
+	Price    float64
+	Time     time.Time
+}
+
+// streamTarget is a coin and currency fed by an exchange ticker.
+type streamTarget struct {
+	coinID   string
+	currency string
+}
+
+// PriceStream reads ticker updates from a Binance-compatible combined
+// stream endpoint.
+type PriceStream struct {
+	url         string
+	idleTimeout time.Duration
+}
+
+func NewPriceStream(url string) *PriceStream {
+	if url == "" {
+		url = DefaultPriceStreamURL
+	}
+	return &PriceStream{url: strings.TrimRight(url, "/"), idleTimeout: priceStreamIdleTimeout}
+}
+
+func (s *PriceStream) String() string {
+	return s.url
+}
+
+// exchangeSymbol returns the exchange pair for a coin symbol quoted in
+// currency, e.g. BTCUSDT. US dollar prices come from USDT pairs, taking
+// USDT as worth one dollar. Ticker symbols are not unique, so the pair
+// may list another coin; streamPriceTolerance guards against that.
+func exchangeSymbol(symbol, currency string) string {
+	quote := strings.ToUpper(currency)
+	if currency == "usd" {
+		quote = "USDT"
+	}
+	return strings.ToUpper(symbol) + quote
+}
+
+// Run subscribes to the mini tickers of the targets, keyed by exchange
+// symbol, and passes each price to onTick. onConnect is called once the
+// stream is open. Run returns nil when ctx is cancelled, and an error when
+// the connection fails or goes quiet.
+func (s *PriceStream) Run(ctx context.Context, targets map[string][]streamTarget, onConnect func(), onTick func(PriceTick)) error {
+	streams := make([]string, 0, len(targets))
+	for symbol := range targets {
+		streams = append(streams, strings.ToLower(symbol)+"@miniTicker")
+	}
+	sort.Strings(streams)
+
+	countRequest(ProviderPriceStream)
+	conn, err := dialWebSocket(ctx, s.url+"/stream?streams="+strings.Join(streams, "/"))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer conn.Close()
+
+	// Unblock the read below when ctx is cancelled
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-finished:
+		}
+	}()
+
+	onConnect()
+	for {
+		_ = conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+		message, err := conn.ReadMessage()
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Binance keys differ only in case ("e" is the event type, "E" its
+		// time), so both are declared to stop case-insensitive matching
+		var event struct {
+			Data struct {
+				EventType string `json:"e"`
+				EventTime int64  `json:"E"`
+				Symbol    string `json:"s"`
+				Close     string `json:"c"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(message, &event); err != nil {
+			continue
+		}
+		price, err := strconv.ParseFloat(event.Data.Close, 64)
+		if err != nil || price <= 0 {
+			continue
+		}
+		at := time.Now()
+		if event.Data.EventTime > 0 {
+			at = time.UnixMilli(event.Data.EventTime)
+		}
+		for _, target := range targets[event.Data.Symbol] {
+			onTick(PriceTick{CoinID: target.coinID, Currency: target.currency, Price: price, Time: at})
+		}
+	}
+}
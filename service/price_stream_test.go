@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// wsTestServer upgrades requests and hands the connection to serve. The
+// first reject requests are answered with 503 instead.
+func wsTestServer(t *testing.T, reject int32, serve func(r *http.Request, conn net.Conn, client *wsConn)) (string, *int32) {
+	t.Helper()
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= reject {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Upgrade") != "websocket" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+			wsAcceptKey(r.Header.Get("Sec-WebSocket-Key")))
+		_ = rw.Flush()
+		serve(r, conn, &wsConn{conn: conn, reader: rw.Reader})
+	}))
+	t.Cleanup(server.Close)
+	return "ws://" + strings.TrimPrefix(server.URL, "http://"), &requests
+}
+
+// wsServerFrame builds an unmasked frame, as servers send them.
+func wsServerFrame(fin bool, opcode byte, payload string) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	return append([]byte{first, byte(len(payload))}, payload...)
+}
+
+func miniTicker(symbol, price string) string {
+	return fmt.Sprintf(`{"stream":"%s@miniTicker","data":{"e":"24hrMiniTicker","E":1767225600000,"s":"%s","c":"%s"}}`,
+		strings.ToLower(symbol), symbol, price)
+}
+
+func TestPriceStream_Run(t *testing.T) {
+	var mu sync.Mutex
+	var query, pong string
+	closes := 0
+	served := make(chan struct{})
+	url, _ := wsTestServer(t, 0, func(r *http.Request, conn net.Conn, client *wsConn) {
+		defer close(served)
+		mu.Lock()
+		query = r.URL.RawQuery
+		mu.Unlock()
+
+		_, _ = conn.Write(wsServerFrame(true, wsOpPing, "hi"))
+		if _, opcode, payload, err := client.readFrame(); err == nil && opcode == wsOpPong {
+			mu.Lock()
+			pong = string(payload)
+			mu.Unlock()
+		}
+		// A fragmented ticker, then one for an unsubscribed symbol
+		message := miniTicker("BTCUSDT", "64250.5")
+		_, _ = conn.Write(wsServerFrame(false, wsOpText, message[:20]))
+		_, _ = conn.Write(wsServerFrame(true, wsOpContinuation, message[20:]))
+		_, _ = conn.Write(wsServerFrame(true, wsOpText, miniTicker("DOGEUSDT", "0.1")))
+		_, _ = conn.Write(wsServerFrame(true, wsOpClose, "\x03\xe9going away"))
+		// The client answers with one close frame and hangs up
+		for {
+			_, opcode, _, err := client.readFrame()
+			if err != nil {
+				break
+			}
+			if opcode == wsOpClose {
+				mu.Lock()
+				closes++
+				mu.Unlock()
+			}
+		}
+	})
+
+	stream := NewPriceStream(url + "/")
+	var ticks []PriceTick
+	connected := false
+	err := stream.Run(context.Background(),
+		map[string][]streamTarget{
+			"BTCUSDT": {{coinID: "bitcoin", currency: "usd"}},
+			"ETHEUR":  {{coinID: "ethereum", currency: "eur"}},
+		},
+		func() { connected = true },
+		func(tick PriceTick) { ticks = append(ticks, tick) })
+
+	if err == nil || !strings.Contains(err.Error(), "1001 going away") {
+		t.Fatalf("Run() error = %v, want the close reason", err)
+	}
+	if !connected || len(ticks) != 1 {
+		t.Fatalf("connected = %v, ticks = %+v", connected, ticks)
+	}
+	want := PriceTick{CoinID: "bitcoin", Currency: "usd", Price: 64250.5, Time: time.UnixMilli(1767225600000)}
+	if ticks[0] != want {
+		t.Fatalf("tick = %+v, want %+v", ticks[0], want)
+	}
+	<-served
+	mu.Lock()
+	defer mu.Unlock()
+	if query != "streams=btcusdt@miniTicker/etheur@miniTicker" || pong != "hi" || closes != 1 {
+		t.Fatalf("query = %q, pong = %q, close frames = %d", query, pong, closes)
+	}
+}
+
+func TestPriceStream_RunStopsOnCancel(t *testing.T) {
+	url, _ := wsTestServer(t, 0, func(r *http.Request, conn net.Conn, client *wsConn) {
+		_, _, _, _ = client.readFrame()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- NewPriceStream(url).Run(ctx, map[string][]streamTarget{"BTCUSDT": {{coinID: "bitcoin", currency: "usd"}}}, cancel, func(PriceTick) {})
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run() error = %v after cancel", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+}
+
+func TestAlertChecker_StreamTriggersBetweenPolls(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	// Polling only ever sees 100, the stream sees a wick to 108
+	market := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "coins/markets") {
+			_, _ = w.Write([]byte(`[{"id":"bitcoin","symbol":"btc","name":"Bitcoin","current_price":100}]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"bitcoin":{"usd":100}}`))
+	}))
+	defer market.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = market.URL
+
+	url, requests := wsTestServer(t, 1, func(r *http.Request, conn net.Conn, client *wsConn) {
+		_, _ = conn.Write(wsServerFrame(true, wsOpText, miniTicker("BTCUSDT", "108")))
+		_, _, _, _ = client.readFrame()
+	})
+
+	manager := models.NewAlertManager(t.TempDir())
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 105, Currency: "usd"})
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionBelow, Price: 50, Currency: "usd"})
+	notified := make(chan Notification, 4)
+	checker := NewAlertChecker(manager)
+	checker.SetInterval(time.Hour)
+	checker.SetNotifiers([]Notifier{channelNotifier(notified)})
+	checker.SetStream(NewPriceStream(url))
+	checker.Start()
+
+	var sent Notification
+	select {
+	case sent = <-notified:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the streamed wick did not trigger the alert")
+	}
+	checker.Stop()
+
+	if sent.Price != 108 || sent.Alert.Condition != models.ConditionAbove || len(notified) != 0 {
+		t.Fatalf("notification = %+v, %d more", sent, len(notified))
+	}
+	remaining := manager.GetAlerts()
+	if len(remaining) != 1 || remaining[0].Condition != models.ConditionBelow {
+		t.Fatalf("expected only the below alert to remain, got %+v", remaining)
+	}
+	if atomic.LoadInt32(requests) < 2 {
+		t.Fatalf("expected a reconnect after the rejected handshake, got %d requests", atomic.LoadInt32(requests))
+	}
+}
+
+// channelNotifier passes notifications to a channel.
+type channelNotifier chan Notification
+
+func (c channelNotifier) Name() string { return "channel" }
+
+func (c channelNotifier) Notify(n Notification) error {
+	c <- n
+	return nil
+}
+
+func TestExchangeSymbol(t *testing.T) {
+	if got := exchangeSymbol("btc", "usd"); got != "BTCUSDT" {
+		t.Fatalf("exchangeSymbol(btc, usd) = %q", got)
+	}
+	if got := exchangeSymbol("eth", "eur"); got != "ETHEUR" {
+		t.Fatalf("exchangeSymbol(eth, eur) = %q", got)
+	}
+}
+
+func TestAlertChecker_IgnoresPairOfAnotherCoin(t *testing.T) {
+	manager := models.NewAlertManager(t.TempDir())
+	// A small coin whose ticker symbol is also a major coin's
+	_ = manager.AddAlert(models.Alert{CoinID: "ether-clone", Condition: models.ConditionAbove, Price: 1, Currency: "usd"})
+	_ = manager.AddAlert(models.Alert{CoinID: "ethereum", Condition: models.ConditionAbove, Price: 4000, Currency: "usd"})
+	notified := make(chan Notification, 4)
+	checker := NewAlertChecker(manager)
+	checker.SetNotifiers([]Notifier{channelNotifier(notified)})
+	checker.streamSymbols = map[string]string{"ether-clone": "eth", "ethereum": "eth"}
+	checker.setStreamReference(streamTarget{coinID: "ether-clone", currency: "usd"}, 0.02)
+	checker.setStreamReference(streamTarget{coinID: "ethereum", currency: "usd"}, 3100)
+
+	// Without a CoinGecko price to compare with, ticks are skipped
+	checker.onTick(PriceTick{CoinID: "solana", Currency: "usd", Price: 150})
+	// ETHUSDT lists ethereum, not the clone
+	checker.onTick(PriceTick{CoinID: "ether-clone", Currency: "usd", Price: 3150})
+	checker.onTick(PriceTick{CoinID: "ethereum", Currency: "usd", Price: 3150})
+	checker.deliveries.wait()
+	if len(notified) != 0 {
+		t.Fatalf("the clone's alert fired on ethereum's price: %+v", <-notified)
+	}
+	if trusted, checked := checker.streamVerified[streamTarget{coinID: "solana", currency: "usd"}]; checked || trusted {
+		t.Fatal("a tick without a CoinGecko price was judged")
+	}
+	if !checker.streamVerified[streamTarget{coinID: "ethereum", currency: "usd"}] {
+		t.Fatal("ETHUSDT should be trusted for ethereum")
+	}
+
+	// The clone is left to polling
+	targets := checker.streamTargets()
+	if len(targets) != 1 || len(targets["ETHUSDT"]) != 1 || targets["ETHUSDT"][0].coinID != "ethereum" {
+		t.Fatalf("streamTargets() = %+v", targets)
+	}
+}
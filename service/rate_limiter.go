@@ -2,6 +2,7 @@ package service
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +16,8 @@ type RateLimiter struct {
 	mu       sync.Mutex
 	interval time.Duration
 	last     time.Time
+	// waited is the total time callers spent in Wait.
+	waited atomic.Int64
 }
 
 func NewRateLimiter(requestsPerMinute int) *RateLimiter {
@@ -28,8 +31,10 @@ func NewRateLimiter(requestsPerMinute int) *RateLimiter {
 }
 
 func (rl *RateLimiter) Wait() {
+	start := time.Now()
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
+	defer func() { rl.waited.Add(int64(time.Since(start))) }()
 
 	if rl.last.IsZero() {
 		rl.last = time.Now()
@@ -51,3 +56,14 @@ func setGlobalRateLimiter(requestsPerMinute int) {
 func waitForRateLimit() {
 	globalRateLimiter.Wait()
 }
+
+// RateLimitWait returns the total time requests have waited for the rate
+// limiter, including waiting behind other requests.
+func RateLimitWait() time.Duration {
+	return time.Duration(globalRateLimiter.waited.Load())
+}
+
+// RateLimitInterval returns the minimum spacing between API requests.
+func RateLimitInterval() time.Duration {
+	return globalRateLimiter.interval
+}
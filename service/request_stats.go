@@ -0,0 +1,75 @@
+package service
+
+import "sync"
+
+// Providers counted in RequestCounts.
+const (
+	ProviderCoinGecko   = "coingecko"
+	ProviderPriceStream = "price_stream"
+	ProviderTelegram    = "telegram"
+	ProviderWebhook     = "webhook"
+	ProviderSMTP        = "smtp"
+)
+
+// RequestStats counts the requests made to one provider.
+type RequestStats struct {
+	// Requests counts every attempt, including retries.
+	Requests int64 `json:"requests"`
+	// Errors counts attempts that failed, by network error or status.
+	Errors int64 `json:"errors"`
+	// Retries counts attempts made after a failed one.
+	Retries int64 `json:"retries"`
+}
+
+var requestStats = struct {
+	sync.Mutex
+	stats map[string]RequestStats
+}{stats: make(map[string]RequestStats)}
+
+func updateRequestStats(provider string, update func(*RequestStats)) {
+	requestStats.Lock()
+	stats := requestStats.stats[provider]
+	update(&stats)
+	requestStats.stats[provider] = stats
+	requestStats.Unlock()
+}
+
+// countRequest records one outgoing request or connection attempt,
+// including retries.
+func countRequest(provider string) {
+	updateRequestStats(provider, func(s *RequestStats) { s.Requests++ })
+}
+
+// countError records a failed request.
+func countError(provider string) {
+	updateRequestStats(provider, func(s *RequestStats) { s.Errors++ })
+}
+
+// countRetry records a request repeated after a failure.
+func countRetry(provider string) {
+	updateRequestStats(provider, func(s *RequestStats) { s.Retries++ })
+}
+
+// RequestCounts returns the requests made to each provider since the
+// process started.
+func RequestCounts() map[string]int64 {
+	requestStats.Lock()
+	defer requestStats.Unlock()
+	counts := make(map[string]int64, len(requestStats.stats))
+	for provider, stats := range requestStats.stats {
+		counts[provider] = stats.Requests
+	}
+	return counts
+}
+
+// ProviderStats returns the requests, errors and retries of each provider
+// since the process started.
+func ProviderStats() map[string]RequestStats {
+	requestStats.Lock()
+	defer requestStats.Unlock()
+	stats := make(map[string]RequestStats, len(requestStats.stats))
+	for provider, s := range requestStats.stats {
+		stats[provider] = s
+	}
+	return stats
+}
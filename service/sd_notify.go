@@ -0,0 +1,29 @@
+package service
+
+import (
+	"net"
+	"os"
+)
+
+// SdNotify sends a state such as "READY=1" to the service manager when the
+// process runs as a systemd Type=notify service, and reports whether it
+// did. Outside systemd, NOTIFY_SOCKET is unset and nothing is sent.
+func SdNotify(state string) (bool, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return false, nil
+	}
+	// A leading @ names a socket in the abstract namespace
+	if socket[0] == '@' {
+		socket = "\x00" + socket[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func TestSdNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if sent, err := SdNotify("READY=1"); sent || err != nil {
+		t.Fatalf("SdNotify() outside systemd = %v, %v", sent, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	if sent, err := SdNotify("READY=1\nSTATUS=Watching 2 alert(s)"); !sent || err != nil {
+		t.Fatalf("SdNotify() = %v, %v", sent, err)
+	}
+	buf := make([]byte, 128)
+	n, err := conn.Read(buf)
+	if err != nil || string(buf[:n]) != "READY=1\nSTATUS=Watching 2 alert(s)" {
+		t.Fatalf("received %q, %v", buf[:n], err)
+	}
+}
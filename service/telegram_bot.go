@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+	"github.com/mrcnserkan/crypto/utils"
+)
+
+const (
+	telegramPollTimeout = 30 * time.Second
+	telegramRetryDelay  = 5 * time.Second
+)
+
+const telegramHelp = `Commands:
+/price <coin-id> [coin-id...] - current price and 24h change
+/portfolio - holdings, value and P&L
+/alerts - active alerts`
+
+// TelegramBot long-polls the Bot API and answers commands from the
+// configured chat. Messages from other chats are ignored.
+type TelegramBot struct {
+	client       *telegramClient
+	chatID       string
+	alertManager *models.AlertManager
+	portfolio    PortfolioLoader
+	currency     string
+	coinGecko    *CoinGecko
+	pollTimeout  time.Duration
+	retryDelay   time.Duration
+	cancel       context.CancelFunc
+	doneChan     chan struct{}
+	mu           sync.Mutex
+	// offset is the next update to ask for, kept across restarts so
+	// answered commands are not fetched again.
+	offset atomic.Int64
+}
+
+func NewTelegramBot(cfg models.TelegramConfig, alertManager *models.AlertManager, portfolio PortfolioLoader, currency string) (*TelegramBot, error) {
+	client, err := newTelegramClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramBot{
+		client:       client,
+		chatID:       cfg.ChatID,
+		alertManager: alertManager,
+		portfolio:    portfolio,
+		currency:     utils.NormalizeCurrency(currency),
+		coinGecko:    NewCoinGecko(),
+		pollTimeout:  telegramPollTimeout,
+		retryDelay:   telegramRetryDelay,
+	}, nil
+}
+
+func (b *TelegramBot) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b.cancel = cancel
+	b.doneChan = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		b.run(ctx)
+	}(b.doneChan)
+}
+
+// Offset returns the ID of the next update the bot will ask Telegram for.
+func (b *TelegramBot) Offset() int64 {
+	return b.offset.Load()
+}
+
+// SetOffset makes the bot continue after the updates a previous bot already
+// answered. Call it before Start.
+func (b *TelegramBot) SetOffset(offset int64) {
+	b.offset.Store(offset)
+}
+
+// Stop cancels a pending poll and waits for the bot to exit.
+func (b *TelegramBot) Stop() {
+	b.mu.Lock()
+	cancel, done := b.cancel, b.doneChan
+	b.cancel, b.doneChan = nil, nil
+	b.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+type telegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *telegramMessage `json:"message"`
+}
+
+type telegramMessage struct {
+	Text string `json:"text"`
+	Chat struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"chat"`
+}
+
+func (b *TelegramBot) run(ctx context.Context) {
+	for {
+		var updates []telegramUpdate
+		err := b.client.call(ctx, "getUpdates", map[string]interface{}{
+			"offset":          b.offset.Load(),
+			"timeout":         int(b.pollTimeout / time.Second),
+			"allowed_updates": []string{"message"},
+		}, &updates)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			slog.Error("telegram bot", "err", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(b.retryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			b.offset.Store(update.UpdateID + 1)
+			if update.Message == nil || !b.fromConfiguredChat(*update.Message) {
+				continue
+			}
+			reply := b.reply(update.Message.Text)
+			if reply == "" {
+				continue
+			}
+			if err := b.client.sendMessage(ctx, b.chatID, reply); err != nil && ctx.Err() == nil {
+				slog.Error("telegram bot", "err", err)
+			}
+		}
+	}
+}
+
+// fromConfiguredChat matches the chat by numeric ID or @username.
+func (b *TelegramBot) fromConfiguredChat(msg telegramMessage) bool {
+	if strconv.FormatInt(msg.Chat.ID, 10) == b.chatID {
+		return true
+	}
+	return msg.Chat.Username != "" && strings.EqualFold("@"+msg.Chat.Username, b.chatID)
+}
+
+// reply answers a command message; non-commands get no reply.
+func (b *TelegramBot) reply(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// In groups commands may be addressed as /price@SomeBot
+	command, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+
+	switch command {
+	case "/start", "/help":
+		return telegramHelp
+	case "/price":
+		return b.priceReply(fields[1:])
+	case "/portfolio":
+		return b.portfolioReply()
+	case "/alerts":
+		return b.alertsReply()
+	}
+	return "Unknown command. Try /help"
+}
+
+func (b *TelegramBot) priceReply(args []string) string {
+	if len(args) == 0 {
+		return "Usage: /price <coin-id> [coin-id...]"
+	}
+	ids := make([]string, 0, len(args))
+	for _, arg := range args {
+		ids = append(ids, utils.NormalizeCoinID(arg))
+	}
+	coins, err := b.coinGecko.GetMarketsByIDs(b.currency, ids)
+	if err != nil {
+		return fmt.Sprintf("Error fetching prices: %v", err)
+	}
+	coinByID := make(map[string]models.Coin, len(coins))
+	for _, coin := range coins {
+		coinByID[coin.ID] = coin
+	}
+
+	symbol := utils.CurrencySymbol(b.currency)
+	lines := make([]string, 0, len(ids))
+	for _, id := range ids {
+		coin, ok := coinByID[id]
+		if !ok {
+			lines = append(lines, fmt.Sprintf("%s: coin not found", id))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s): %s%s (%+.2f%% 24h)",
+			coin.Name, strings.ToUpper(coin.Symbol), symbol, utils.FormatCurrency(coin.CurrentPrice),
+			coin.PriceChangePercentage24h))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *TelegramBot) portfolioReply() string {
+	if b.portfolio == nil {
+		return "Portfolio is empty"
+	}
+	portfolio, err := b.portfolio()
+	if err != nil {
+		return fmt.Sprintf("Error loading portfolio: %v", err)
+	}
+	if !portfolio.HasHoldings() {
+		return "Portfolio is empty"
+	}
+	ids := make([]string, 0, len(portfolio.Holdings))
+	for id := range portfolio.Holdings {
+		ids = append(ids, id)
+	}
+	coins, err := b.coinGecko.GetMarketsByIDs(b.currency, ids)
+	if err != nil {
+		return fmt.Sprintf("Error fetching market data: %v", err)
+	}
+	symbols := make(map[string]string, len(coins))
+	prices := make(map[string]float64, len(coins))
+	for _, coin := range coins {
+		symbols[coin.ID] = strings.ToUpper(coin.Symbol)
+		prices[coin.ID] = coin.CurrentPrice
+	}
+
+	pnl := models.ComputePortfolioPnL(portfolio, prices, b.currency)
+	sort.Slice(pnl.Coins, func(i, j int) bool {
+		return pnl.Coins[i].CurrentValue > pnl.Coins[j].CurrentValue
+	})
+
+	symbol := utils.CurrencySymbol(b.currency)
+	lines := make([]string, 0, len(pnl.Coins)+3)
+	for _, coin := range pnl.Coins {
+		lines = append(lines, fmt.Sprintf("%s %.6f = %s%s (P&L %s, %+.2f%%)",
+			symbols[coin.CoinID], coin.Amount, symbol, utils.FormatCurrency(coin.CurrentValue),
+			signedAmount(symbol, coin.UnrealizedPnL), coin.UnrealizedPnLPct))
+	}
+	totalPct := 0.0
+	if pnl.TotalCost > 0 {
+		totalPct = pnl.TotalUnrealizedPnL / pnl.TotalCost * 100
+	}
+	lines = append(lines,
+		fmt.Sprintf("Total: %s%s", symbol, utils.FormatCurrency(pnl.TotalValue)),
+		fmt.Sprintf("Unrealized P&L: %s (%+.2f%%)", signedAmount(symbol, pnl.TotalUnrealizedPnL), totalPct))
+	if pnl.HasMixedCurrency {
+		lines = append(lines, "Mixed transaction currencies, P&L is approximate")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (b *TelegramBot) alertsReply() string {
+	alerts := b.alertManager.GetAlerts()
+	if len(alerts) == 0 {
+		return "No active alerts"
+	}
+	lines := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		currencySymbol := utils.CurrencySymbol(utils.NormalizeCurrency(alert.Currency))
+		lines = append(lines, fmt.Sprintf("%s %s", strings.ToUpper(alert.CoinID), alert.Describe(currencySymbol)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// signedAmount formats an amount as +$1.50 or -$1.50.
+func signedAmount(symbol string, value float64) string {
+	sign := "+"
+	if value < 0 {
+		sign = "-"
+	}
+	return sign + symbol + utils.FormatCurrency(math.Abs(value))
+}
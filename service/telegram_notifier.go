@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// telegramClient calls Telegram Bot API methods.
+type telegramClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func newTelegramClient(cfg models.TelegramConfig) (*telegramClient, error) {
+	if strings.TrimSpace(cfg.Token) == "" {
+		return nil, fmt.Errorf("missing bot token")
+	}
+	if strings.TrimSpace(cfg.ChatID) == "" {
+		return nil, fmt.Errorf("missing chat_id")
+	}
+	baseURL := cfg.APIURLOrDefault()
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid api_url %q", cfg.APIURL)
+	}
+	return &telegramClient{
+		baseURL: baseURL,
+		token:   cfg.Token,
+		// Long enough for getUpdates long polling
+		client: &http.Client{Timeout: telegramPollTimeout + 10*time.Second},
+	}, nil
+}
+
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	Description string          `json:"description"`
+}
+
+// call posts params as JSON to the API method and decodes the result into
+// result when it is not nil.
+func (tc *telegramClient) call(ctx context.Context, method string, params, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		fmt.Sprintf("%s/bot%s/%s", tc.baseURL, tc.token, method), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	countRequest(ProviderTelegram)
+	if err := tc.do(req, method, result); err != nil {
+		// A cancelled long poll is a shutdown, not a failure
+		if ctx.Err() == nil {
+			countError(ProviderTelegram)
+		}
+		return err
+	}
+	return nil
+}
+
+// do sends req and decodes the API response.
+func (tc *telegramClient) do(req *http.Request, method string, result interface{}) error {
+	resp, err := tc.client.Do(req)
+	if err != nil {
+		// The request URL contains the bot token, keep it out of errors
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("telegram %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var decoded telegramResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return fmt.Errorf("telegram %s: %s", method, resp.Status)
+	}
+	if !decoded.OK {
+		if decoded.Description == "" {
+			decoded.Description = resp.Status
+		}
+		return fmt.Errorf("telegram %s: %s", method, decoded.Description)
+	}
+	if result != nil {
+		if err := json.Unmarshal(decoded.Result, result); err != nil {
+			return fmt.Errorf("telegram %s: decode error: %v", method, err)
+		}
+	}
+	return nil
+}
+
+func (tc *telegramClient) sendMessage(ctx context.Context, chatID, text string) error {
+	return tc.call(ctx, "sendMessage", map[string]interface{}{
+		"chat_id": chatID,
+		"text":    text,
+	}, nil)
+}
+
+// TelegramNotifier sends triggered alerts to a Telegram chat.
+type TelegramNotifier struct {
+	client *telegramClient
+	chatID string
+}
+
+func NewTelegramNotifier(cfg models.TelegramConfig) (*TelegramNotifier, error) {
+	client, err := newTelegramClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &TelegramNotifier{client: client, chatID: cfg.ChatID}, nil
+}
+
+func (t *TelegramNotifier) Name() string {
+	return "telegram"
+}
+
+func (t *TelegramNotifier) Notify(n Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return t.client.sendMessage(ctx, t.chatID, "🔔 "+n.Message)
+}
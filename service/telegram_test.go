@@ -0,0 +1,240 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+const testBotToken = "123:secret-token"
+
+// fakeTelegram is a Bot API stand-in. getUpdates returns the queued updates
+// once, then long-polls empty; sendMessage calls are recorded.
+type fakeTelegram struct {
+	server  *httptest.Server
+	mu      sync.Mutex
+	updates []telegramUpdate
+	offsets []int64
+	sent    []map[string]interface{}
+}
+
+func startFakeTelegram(t *testing.T, updates []telegramUpdate) *fakeTelegram {
+	t.Helper()
+	fake := &fakeTelegram{updates: updates}
+	fake.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/bot"+testBotToken+"/")
+		if method == r.URL.Path {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"ok":false,"error_code":401,"description":"Unauthorized"}`))
+			return
+		}
+		var params map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&params)
+
+		switch method {
+		case "getUpdates":
+			fake.mu.Lock()
+			fake.offsets = append(fake.offsets, int64(params["offset"].(float64)))
+			updates := fake.updates
+			fake.updates = nil
+			fake.mu.Unlock()
+			if len(updates) == 0 {
+				select {
+				case <-r.Context().Done():
+					return
+				case <-time.After(50 * time.Millisecond):
+				}
+				updates = []telegramUpdate{}
+			}
+			result, _ := json.Marshal(updates)
+			_, _ = fmt.Fprintf(w, `{"ok":true,"result":%s}`, result)
+		case "sendMessage":
+			fake.mu.Lock()
+			fake.sent = append(fake.sent, params)
+			fake.mu.Unlock()
+			if params["chat_id"] == "blocked" {
+				_, _ = w.Write([]byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"ok":true,"result":{"message_id":1}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(fake.server.Close)
+	return fake
+}
+
+func (f *fakeTelegram) messages() []map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]interface{}(nil), f.sent...)
+}
+
+func (f *fakeTelegram) config(chatID string) models.TelegramConfig {
+	return models.TelegramConfig{Token: testBotToken, ChatID: chatID, APIURL: f.server.URL + "/"}
+}
+
+func TestTelegramNotifier(t *testing.T) {
+	fake := startFakeTelegram(t, nil)
+	notifier, err := NewTelegramNotifier(fake.config("42"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := notifier.Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	sent := fake.messages()
+	if len(sent) != 1 || sent[0]["chat_id"] != "42" || sent[0]["text"] != "🔔 "+testNotification().Message {
+		t.Fatalf("sent = %+v", sent)
+	}
+
+	blocked, _ := NewTelegramNotifier(fake.config("blocked"))
+	if err := blocked.Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "bot was blocked") {
+		t.Fatalf("expected the API description in the error, got %v", err)
+	}
+
+	cfg := fake.config("42")
+	cfg.APIURL = "http://127.0.0.1:1"
+	unreachable, _ := NewTelegramNotifier(cfg)
+	if err := unreachable.Notify(testNotification()); err == nil || strings.Contains(err.Error(), testBotToken) {
+		t.Fatalf("expected an error without the token, got %v", err)
+	}
+
+	for _, cfg := range []models.TelegramConfig{{ChatID: "42"}, {Token: testBotToken}, {Token: testBotToken, ChatID: "42", APIURL: "ftp://x"}} {
+		if _, err := NewTelegramNotifier(cfg); err == nil {
+			t.Fatalf("NewTelegramNotifier(%+v) should fail", cfg)
+		}
+	}
+}
+
+func telegramTextUpdate(id, chatID int64, username, text string) telegramUpdate {
+	msg := &telegramMessage{Text: text}
+	msg.Chat.ID = chatID
+	msg.Chat.Username = username
+	return telegramUpdate{UpdateID: id, Message: msg}
+}
+
+func TestTelegramBot_AnswersCommands(t *testing.T) {
+	originalLimiter := globalRateLimiter
+	t.Cleanup(func() { globalRateLimiter = originalLimiter })
+	setGlobalRateLimiter(6000)
+
+	market := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var coins []string
+		for _, id := range strings.Split(r.URL.Query().Get("ids"), ",") {
+			switch id {
+			case "bitcoin":
+				coins = append(coins, `{"id":"bitcoin","symbol":"btc","name":"Bitcoin","current_price":60000,"price_change_percentage_24h":2.5}`)
+			case "ethereum":
+				coins = append(coins, `{"id":"ethereum","symbol":"eth","name":"Ethereum","current_price":1500,"price_change_percentage_24h":-1}`)
+			}
+		}
+		_, _ = fmt.Fprintf(w, "[%s]", strings.Join(coins, ","))
+	}))
+	defer market.Close()
+	originalBaseURL := BaseURL
+	t.Cleanup(func() { BaseURL = originalBaseURL })
+	BaseURL = market.URL
+
+	dir := t.TempDir()
+	alerts := models.NewAlertManager(dir)
+	_ = alerts.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 70000, Currency: "usd"})
+	path := filepath.Join(dir, "portfolio.json")
+	_ = models.NewPortfolio(path).AddTransaction(models.Transaction{CoinID: "bitcoin", Amount: 0.5, Price: 50000, Type: "buy", Currency: "usd"})
+	loadPortfolio := func() (*models.Portfolio, error) {
+		current := models.NewPortfolio(path)
+		return current, current.Load()
+	}
+
+	fake := startFakeTelegram(t, []telegramUpdate{
+		telegramTextUpdate(1, 99, "stranger", "/alerts"),
+		telegramTextUpdate(2, 42, "", "/price bitcoin dogecoin"),
+		telegramTextUpdate(3, 42, "", "hello"),
+		telegramTextUpdate(4, 42, "", "/alerts"),
+		telegramTextUpdate(5, 42, "", "/portfolio@CryptoBot"),
+	})
+	bot, err := NewTelegramBot(fake.config("42"), alerts, loadPortfolio, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Bought by another process after the bot started; /portfolio reads
+	// the file when it is asked
+	_ = models.NewPortfolio(path).AddTransaction(models.Transaction{CoinID: "ethereum", Amount: 2, Price: 2000, Type: "buy", Currency: "usd"})
+	bot.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(fake.messages()) < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	bot.Stop()
+
+	sent := fake.messages()
+	if len(sent) != 3 {
+		t.Fatalf("expected 3 replies, got %+v", sent)
+	}
+	want := []string{
+		"Bitcoin (BTC): $60.00K (+2.50% 24h)\ndogecoin: coin not found",
+		"BITCOIN above $70000.00",
+		"BTC 0.500000 = $30.00K (P&L +$5.00K, +20.00%)\n" +
+			"ETH 2.000000 = $3.00K (P&L -$1.00K, -25.00%)\n" +
+			"Total: $33.00K\nUnrealized P&L: +$4.00K (+13.79%)",
+	}
+	for i, msg := range sent {
+		if msg["chat_id"] != "42" || msg["text"] != want[i] {
+			t.Fatalf("reply %d = %q, want %q", i, msg["text"], want[i])
+		}
+	}
+
+	fake.mu.Lock()
+	if len(fake.offsets) < 2 || fake.offsets[0] != 0 || fake.offsets[1] != 6 {
+		t.Fatalf("getUpdates offsets = %v", fake.offsets)
+	}
+	polls := len(fake.offsets)
+	fake.mu.Unlock()
+
+	// A bot restarted on a config reload continues after the answered
+	// commands
+	restarted, err := NewTelegramBot(fake.config("42"), alerts, loadPortfolio, "usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted.SetOffset(bot.Offset())
+	restarted.Start()
+	for time.Now().Before(deadline) {
+		fake.mu.Lock()
+		done := len(fake.offsets) > polls
+		fake.mu.Unlock()
+		if done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	restarted.Stop()
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if len(fake.offsets) <= polls || fake.offsets[polls] != 6 {
+		t.Fatalf("getUpdates offsets after the restart = %v", fake.offsets)
+	}
+}
+
+func TestTelegramBot_FromConfiguredChat(t *testing.T) {
+	bot := &TelegramBot{chatID: "@MyChannel"}
+	if !bot.fromConfiguredChat(*telegramTextUpdate(1, -100, "mychannel", "").Message) {
+		t.Fatal("expected a username match")
+	}
+	if bot.fromConfiguredChat(*telegramTextUpdate(1, -100, "", "").Message) {
+		t.Fatal("expected no match without a username")
+	}
+	if got := bot.reply("/unknown"); got != "Unknown command. Try /help" {
+		t.Fatalf("reply = %q", got)
+	}
+}
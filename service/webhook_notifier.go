@@ -0,0 +1,177 @@
+package service
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// Headers of signed webhook requests. The signature is the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the configured secret.
+const (
+	WebhookSignatureHeader = "X-Crypto-Signature"
+	WebhookTimestampHeader = "X-Crypto-Timestamp"
+)
+
+// WebhookNotifier posts triggered alerts to an HTTP endpoint.
+type WebhookNotifier struct {
+	cfg      models.WebhookConfig
+	template *template.Template
+	client   *http.Client
+	backoff  time.Duration
+}
+
+func NewWebhookNotifier(cfg models.WebhookConfig) (*WebhookNotifier, error) {
+	parsed, err := url.Parse(cfg.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid webhook URL %q", cfg.URL)
+	}
+
+	cfg.Format = strings.ToLower(strings.TrimSpace(cfg.Format))
+	if cfg.Format == "" {
+		cfg.Format = models.WebhookFormatJSON
+	}
+	w := &WebhookNotifier{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		backoff: 500 * time.Millisecond,
+	}
+
+	switch cfg.Format {
+	case models.WebhookFormatJSON:
+		if cfg.Template != "" {
+			w.template, err = template.New("webhook").Funcs(templateFuncs).Parse(cfg.Template)
+			if err != nil {
+				return nil, fmt.Errorf("invalid webhook template: %w", err)
+			}
+		}
+	case models.WebhookFormatSlack, models.WebhookFormatDiscord:
+		if cfg.Template != "" {
+			return nil, fmt.Errorf("templates only apply to the json format")
+		}
+	default:
+		return nil, fmt.Errorf("unknown webhook format %q (use json, slack or discord)", cfg.Format)
+	}
+	return w, nil
+}
+
+func (w *WebhookNotifier) Name() string {
+	if parsed, err := url.Parse(w.cfg.URL); err == nil {
+		return "webhook " + parsed.Host
+	}
+	return "webhook"
+}
+
+// Notify posts the payload, retrying network errors, 429 and 5xx responses
+// with exponential backoff.
+func (w *WebhookNotifier) Notify(n Notification) error {
+	body, err := w.payload(n)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	backoff := w.backoff
+	for attempt := 0; attempt <= w.cfg.RetriesOrDefault(); attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+			countRetry(ProviderWebhook)
+		}
+
+		req, err := w.newRequest(body)
+		if err != nil {
+			return err
+		}
+		countRequest(ProviderWebhook)
+		resp, err := w.client.Do(req)
+		if err != nil {
+			countError(ProviderWebhook)
+			lastErr = err
+			continue
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			countError(ProviderWebhook)
+		}
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			lastErr = fmt.Errorf("webhook error: %s", resp.Status)
+		default:
+			return fmt.Errorf("webhook error: %s", resp.Status)
+		}
+	}
+	return lastErr
+}
+
+func (w *WebhookNotifier) newRequest(body []byte) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "crypto-cli")
+	for key, value := range w.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	if w.cfg.Secret != "" {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(w.cfg.Secret, timestamp, body))
+	}
+	return req, nil
+}
+
+// SignWebhook returns the hex signature receivers compare with the
+// X-Crypto-Signature header.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *WebhookNotifier) payload(n Notification) ([]byte, error) {
+	switch w.cfg.Format {
+	case models.WebhookFormatSlack:
+		return json.Marshal(map[string]string{"text": n.Message})
+	case models.WebhookFormatDiscord:
+		return json.Marshal(map[string]string{"content": n.Message})
+	}
+
+	data := newNotificationData(n)
+	if w.template == nil {
+		return data.payload()
+	}
+
+	var buf bytes.Buffer
+	if err := w.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("webhook template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template did not produce valid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// jsonValue lets templates embed values safely, e.g. {"text": {{json .Message}}}.
+func jsonValue(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
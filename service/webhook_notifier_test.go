@@ -0,0 +1,192 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+type recordedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookServer answers with statuses in order, then 200, and records the
+// requests it receives.
+func webhookServer(t *testing.T, statuses ...int) (*httptest.Server, func() []recordedRequest) {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []recordedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, recordedRequest{header: r.Header.Clone(), body: body})
+		n := len(requests)
+		mu.Unlock()
+		if n <= len(statuses) {
+			w.WriteHeader(statuses[n-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []recordedRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]recordedRequest(nil), requests...)
+	}
+}
+
+func testNotification() Notification {
+	return Notification{
+		Alert:       models.Alert{CoinID: "bitcoin", Condition: models.ConditionAbove, Price: 50000, Currency: "usd"},
+		Price:       50123.45,
+		Message:     "Price Alert: BITCOIN is above $50123.45 (Target: $50000.00 USD)",
+		TriggeredAt: time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func newTestWebhook(t *testing.T, cfg models.WebhookConfig) *WebhookNotifier {
+	t.Helper()
+	notifier, err := NewWebhookNotifier(cfg)
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier() error = %v", err)
+	}
+	notifier.backoff = time.Millisecond
+	return notifier
+}
+
+func TestWebhookNotifier_DefaultPayloadHeadersAndSignature(t *testing.T) {
+	server, requests := webhookServer(t)
+	notifier := newTestWebhook(t, models.WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Secret:  "s3cret",
+	})
+	if err := notifier.Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	got := requests()
+	if len(got) != 1 {
+		t.Fatalf("got %d requests", len(got))
+	}
+	req := got[0]
+	if req.header.Get("Authorization") != "Bearer token" || req.header.Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected headers %v", req.header)
+	}
+	want := "sha256=" + SignWebhook("s3cret", req.header.Get(WebhookTimestampHeader), req.body)
+	if req.header.Get(WebhookSignatureHeader) != want {
+		t.Fatalf("signature = %q, want %q", req.header.Get(WebhookSignatureHeader), want)
+	}
+
+	var payload struct {
+		Message     string       `json:"message"`
+		Description string       `json:"description"`
+		Price       float64      `json:"price"`
+		Currency    string       `json:"currency"`
+		Alert       models.Alert `json:"alert"`
+	}
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Price != 50123.45 || payload.Description != "above $50000.00" || payload.Currency != "usd" || payload.Alert.CoinID != "bitcoin" {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+}
+
+func TestWebhookNotifier_Formats(t *testing.T) {
+	cases := []struct {
+		cfg  models.WebhookConfig
+		want string
+	}{
+		{models.WebhookConfig{Format: "slack"}, `{"text":"Price Alert: BITCOIN is above $50123.45 (Target: $50000.00 USD)"}`},
+		{models.WebhookConfig{Format: "discord"}, `{"content":"Price Alert: BITCOIN is above $50123.45 (Target: $50000.00 USD)"}`},
+		{models.WebhookConfig{Template: `{"coin": {{json .CoinID}}, "price": {{.Price}}, "note": {{json .Description}}}`}, `{"coin": "bitcoin", "price": 50123.45, "note": "above $50000.00"}`},
+	}
+	for _, tc := range cases {
+		server, requests := webhookServer(t)
+		tc.cfg.URL = server.URL
+		if err := newTestWebhook(t, tc.cfg).Notify(testNotification()); err != nil {
+			t.Fatalf("Notify(%+v) error = %v", tc.cfg, err)
+		}
+		if got := string(requests()[0].body); got != tc.want {
+			t.Fatalf("body = %s, want %s", got, tc.want)
+		}
+	}
+}
+
+func TestWebhookNotifier_Retries(t *testing.T) {
+	server, requests := webhookServer(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	if err := newTestWebhook(t, models.WebhookConfig{URL: server.URL}).Notify(testNotification()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if n := len(requests()); n != 3 {
+		t.Fatalf("got %d attempts, want 3", n)
+	}
+
+	noRetries := 0
+	server, requests = webhookServer(t, http.StatusServiceUnavailable)
+	if err := newTestWebhook(t, models.WebhookConfig{URL: server.URL, Retries: &noRetries}).Notify(testNotification()); err == nil {
+		t.Fatal("expected an error without retries")
+	}
+	if n := len(requests()); n != 1 {
+		t.Fatalf("got %d attempts, want 1", n)
+	}
+
+	server, requests = webhookServer(t, http.StatusBadRequest)
+	if err := newTestWebhook(t, models.WebhookConfig{URL: server.URL}).Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("expected a 400 error, got %v", err)
+	}
+	if n := len(requests()); n != 1 {
+		t.Fatalf("client errors should not be retried, got %d attempts", n)
+	}
+}
+
+func TestNewNotifiers_SkipsInvalidWebhooks(t *testing.T) {
+	cfg := models.NotificationConfig{Webhooks: []models.WebhookConfig{
+		{URL: "https://hooks.example.com/a"},
+		{URL: "ftp://example.com"},
+		{URL: "https://hooks.example.com/b", Format: "teams"},
+		{URL: "https://hooks.example.com/c", Format: "slack", Template: "{}"},
+		{URL: "https://hooks.example.com/d", Template: "{{"},
+	}}
+	notifiers, err := NewNotifiers(cfg)
+	if len(notifiers) != 1 || notifiers[0].Name() != "webhook hooks.example.com" {
+		t.Fatalf("notifiers = %v", notifiers)
+	}
+	if err == nil || !strings.Contains(err.Error(), "webhook 2") || !strings.Contains(err.Error(), "webhook 5") {
+		t.Fatalf("error = %v", err)
+	}
+}
+
+type recordingNotifier struct{ got []Notification }
+
+func (r *recordingNotifier) Name() string { return "recording" }
+
+func (r *recordingNotifier) Notify(n Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestAlertChecker_SendsToNotifiers(t *testing.T) {
+	manager := models.NewAlertManager(t.TempDir())
+	checker := NewAlertChecker(manager)
+	recorder := &recordingNotifier{}
+	checker.SetNotifiers([]Notifier{recorder})
+	_ = manager.AddAlert(models.Alert{CoinID: "bitcoin", Condition: models.ConditionBelow, Price: 100})
+
+	alert := manager.GetAlerts()[0]
+	checker.settle(alert, true, false, 95, func() string { return alertMessage(alert, 95) })
+	checker.deliveries.wait()
+	if len(recorder.got) != 1 || recorder.got[0].Price != 95 || !strings.Contains(recorder.got[0].Message, "below") {
+		t.Fatalf("notifications = %+v", recorder.got)
+	}
+}
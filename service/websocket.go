@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// A minimal WebSocket client (RFC 6455), enough to read text messages from
+// public market data streams.
+
+const (
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+
+	wsAcceptGUID     = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	wsMaxMessageSize = 1 << 20
+)
+
+type wsConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// writeMu serializes frames written by pongs and Close.
+	writeMu sync.Mutex
+	// closeSent is set once a close frame was written, as nothing may
+	// follow it. Guarded by writeMu.
+	closeSent bool
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// dialWebSocket opens a ws:// or wss:// URL.
+func dialWebSocket(ctx context.Context, rawURL string) (*wsConn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		switch u.Scheme {
+		case "ws":
+			host = net.JoinHostPort(u.Hostname(), "80")
+		case "wss":
+			host = net.JoinHostPort(u.Hostname(), "443")
+		}
+	}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ws":
+		conn, err = (&net.Dialer{Timeout: 10 * time.Second}).DialContext(ctx, "tcp", host)
+	case "wss":
+		dialer := &tls.Dialer{
+			NetDialer: &net.Dialer{Timeout: 10 * time.Second},
+			Config:    &tls.Config{ServerName: u.Hostname()},
+		}
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("unsupported WebSocket scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := wsHandshake(conn, u)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func wsHandshake(conn net.Conn, u *url.URL) (*wsConn, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetDeadline(time.Time{})
+
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    u,
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-WebSocket-Key":     {key},
+			"Sec-WebSocket-Version": {"13"},
+		},
+	}
+	if _, err := fmt.Fprintf(conn, "GET %s HTTP/1.1\r\nHost: %s\r\n", u.RequestURI(), u.Host); err != nil {
+		return nil, err
+	}
+	if err := req.Header.Write(conn); err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(conn, "\r\n"); err != nil {
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, fmt.Errorf("WebSocket handshake failed: %s", resp.Status)
+	}
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAcceptKey(key) {
+		return nil, fmt.Errorf("WebSocket handshake failed: bad Sec-WebSocket-Accept")
+	}
+	return &wsConn{conn: conn, reader: reader}, nil
+}
+
+func wsAcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// ReadMessage returns the next text or binary message, answering pings on
+// the way. A close frame from the server ends the stream with an error.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	started := false
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+		case wsOpPong:
+		case wsOpClose:
+			_ = c.writeFrame(wsOpClose, payload)
+			if len(payload) >= 2 {
+				return nil, fmt.Errorf("WebSocket closed by server (%d %s)", binary.BigEndian.Uint16(payload), payload[2:])
+			}
+			return nil, fmt.Errorf("WebSocket closed by server")
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			if (opcode == wsOpContinuation) != started {
+				return nil, fmt.Errorf("unexpected WebSocket frame sequence")
+			}
+			started = true
+			if len(message)+len(payload) > wsMaxMessageSize {
+				return nil, fmt.Errorf("WebSocket message too large")
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, fmt.Errorf("unknown WebSocket opcode %d", opcode)
+		}
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(c.reader, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+	if length > wsMaxMessageSize {
+		return false, 0, nil, fmt.Errorf("WebSocket frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a single masked frame, as clients must.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closeSent {
+		return nil
+	}
+	c.closeSent = opcode == wsOpClose
+
+	frame := []byte{0x80 | opcode}
+	switch n := len(payload); {
+	case n < 126:
+		frame = append(frame, 0x80|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 0x80|126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 0x80|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := c.conn.Write(frame)
+	return err
+}
+
+// SetReadDeadline bounds the wait for the next frame.
+func (c *wsConn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// Close sends a normal closure, unless a close frame was already sent in
+// reply to the server's, and closes the connection. It may be called more
+// than once.
+func (c *wsConn) Close() error {
+	c.closeOnce.Do(func() {
+		_ = c.conn.SetWriteDeadline(time.Now().Add(time.Second))
+		_ = c.writeFrame(wsOpClose, []byte{0x03, 0xE8}) // 1000 normal closure
+		c.closeErr = c.conn.Close()
+	})
+	return c.closeErr
+}
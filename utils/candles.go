@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// weekAlignment shifts weekly buckets so they start on Monday; the Unix
+// epoch fell on a Thursday.
+const weekAlignment = 4 * 24 * time.Hour
+
+// ParseTimeframe parses a candle timeframe such as "1h", "4h", "1d" or "1w".
+// An empty string returns 0, meaning the data's native granularity.
+func ParseTimeframe(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	units := map[byte]time.Duration{
+		'm': time.Minute,
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}
+	unit, ok := units[s[len(s)-1]]
+	if !ok || len(s) < 2 {
+		return 0, fmt.Errorf("invalid timeframe %q (use e.g. 1h, 4h, 1d or 1w)", s)
+	}
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid timeframe %q (use e.g. 1h, 4h, 1d or 1w)", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// FormatTimeframe renders a timeframe in the units ParseTimeframe accepts.
+func FormatTimeframe(frame time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case frame >= 7*day && frame%(7*day) == 0:
+		return fmt.Sprintf("%dw", frame/(7*day))
+	case frame >= day && frame%day == 0:
+		return fmt.Sprintf("%dd", frame/day)
+	case frame >= time.Hour && frame%time.Hour == 0:
+		return fmt.Sprintf("%dh", frame/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", frame/time.Minute)
+	}
+}
+
+// ResampleOHLC merges candles into buckets of frame aligned to UTC (weekly
+// buckets start on Monday). Each result is stamped with its bucket start.
+func ResampleOHLC(data []models.OHLC, frame time.Duration) []models.OHLC {
+	if frame <= 0 || len(data) == 0 {
+		return data
+	}
+	sorted := make([]models.OHLC, len(data))
+	copy(sorted, data)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Time < sorted[j].Time })
+
+	var out []models.OHLC
+	for _, c := range sorted {
+		start := bucketStart(c.Time, frame)
+		if n := len(out); n > 0 && out[n-1].Time == start {
+			last := &out[n-1]
+			last.High = math.Max(last.High, c.High)
+			last.Low = math.Min(last.Low, c.Low)
+			last.Close = c.Close
+			continue
+		}
+		out = append(out, models.OHLC{Time: start, Open: c.Open, High: c.High, Low: c.Low, Close: c.Close})
+	}
+	return out
+}
+
+// CandlesFromSeries builds candles of frame from price points.
+func CandlesFromSeries(points []SeriesPoint, frame time.Duration) []models.OHLC {
+	data := make([]models.OHLC, len(points))
+	for i, p := range points {
+		data[i] = models.OHLC{Time: p.Time.UnixMilli(), Open: p.Value, High: p.Value, Low: p.Value, Close: p.Value}
+	}
+	return ResampleOHLC(data, frame)
+}
+
+// OHLCStep returns the typical spacing between candles, or 0 for fewer than two.
+func OHLCStep(data []models.OHLC) time.Duration {
+	times := make([]int64, len(data))
+	for i, c := range data {
+		times[i] = c.Time
+	}
+	return medianStep(times)
+}
+
+// SeriesStep returns the typical spacing between points, or 0 for fewer than two.
+func SeriesStep(points []SeriesPoint) time.Duration {
+	times := make([]int64, len(points))
+	for i, p := range points {
+		times[i] = p.Time.UnixMilli()
+	}
+	return medianStep(times)
+}
+
+// medianStep uses the median gap so a trailing "now" sample does not skew it.
+func medianStep(times []int64) time.Duration {
+	if len(times) < 2 {
+		return 0
+	}
+	gaps := make([]int64, 0, len(times)-1)
+	for i := 1; i < len(times); i++ {
+		if gap := times[i] - times[i-1]; gap > 0 {
+			gaps = append(gaps, gap)
+		}
+	}
+	if len(gaps) == 0 {
+		return 0
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i] < gaps[j] })
+	return time.Duration(gaps[len(gaps)/2]) * time.Millisecond
+}
+
+func bucketStart(timeMs int64, frame time.Duration) int64 {
+	frameMs := frame.Milliseconds()
+	offsetMs := int64(0)
+	if frame%(7*24*time.Hour) == 0 {
+		offsetMs = weekAlignment.Milliseconds()
+	}
+	shifted := timeMs - offsetMs
+	start := shifted - shifted%frameMs
+	if shifted < 0 && shifted%frameMs != 0 {
+		start -= frameMs
+	}
+	return start + offsetMs
+}
+
+// fitCandles merges runs of consecutive candles so at most maxCandles remain.
+// Runs are counted back from the newest candle, so only the oldest may be
+// partial. It also returns the index of each run's last source candle, which
+// carries the run's close.
+func fitCandles(data []models.OHLC, maxCandles int) ([]models.OHLC, []int) {
+	group := 1
+	if maxCandles > 0 && len(data) > maxCandles {
+		group = (len(data) + maxCandles - 1) / maxCandles
+	}
+
+	var out []models.OHLC
+	var lastIndices []int
+	for end := len(data); end > 0; end -= group {
+		start := intMax(0, end-group)
+		merged := data[start]
+		for _, c := range data[start+1 : end] {
+			merged.High = math.Max(merged.High, c.High)
+			merged.Low = math.Min(merged.Low, c.Low)
+			merged.Close = c.Close
+		}
+		out = append(out, merged)
+		lastIndices = append(lastIndices, end-1)
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+		lastIndices[i], lastIndices[j] = lastIndices[j], lastIndices[i]
+	}
+	return out, lastIndices
+}
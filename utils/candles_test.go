@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+func TestParseTimeframe(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":   0,
+		"1h": time.Hour,
+		"4H": 4 * time.Hour,
+		"1d": 24 * time.Hour,
+		"1w": 7 * 24 * time.Hour,
+	}
+	for input, want := range cases {
+		got, err := ParseTimeframe(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseTimeframe(%q) = (%v, %v), want %v", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"h", "0h", "1y", "abc"} {
+		if _, err := ParseTimeframe(input); err == nil {
+			t.Fatalf("ParseTimeframe(%q) expected error", input)
+		}
+	}
+	if got := FormatTimeframe(4 * time.Hour); got != "4h" {
+		t.Fatalf("FormatTimeframe(4h) = %q", got)
+	}
+}
+
+func TestResampleOHLCMergesBuckets(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := time.Hour.Milliseconds()
+	data := []models.OHLC{
+		{Time: base, Open: 10, High: 12, Low: 9, Close: 11},
+		{Time: base + hour, Open: 11, High: 15, Low: 10, Close: 14},
+		{Time: base + 4*hour, Open: 14, High: 14, Low: 6, Close: 7},
+	}
+	got := ResampleOHLC(data, 4*time.Hour)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2: %+v", len(got), got)
+	}
+	want := models.OHLC{Time: base, Open: 10, High: 15, Low: 9, Close: 14}
+	if got[0] != want {
+		t.Fatalf("first candle = %+v, want %+v", got[0], want)
+	}
+	if got[1].Time != base+4*hour || got[1].Low != 6 {
+		t.Fatalf("second candle = %+v", got[1])
+	}
+}
+
+func TestResampleOHLCWeeklyStartsOnMonday(t *testing.T) {
+	// 2026-06-03 is a Wednesday
+	wed := time.Date(2026, 6, 3, 12, 0, 0, 0, time.UTC)
+	got := ResampleOHLC([]models.OHLC{{Time: wed.UnixMilli(), Open: 1, High: 1, Low: 1, Close: 1}}, 7*24*time.Hour)
+	start := time.UnixMilli(got[0].Time).UTC()
+	if start.Weekday() != time.Monday || start.Day() != 1 {
+		t.Fatalf("weekly bucket starts %v, want Monday 2026-06-01", start)
+	}
+}
+
+func TestCandlesFromSeries(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	var points []SeriesPoint
+	for i, v := range []float64{5, 8, 3, 6, 7, 9} {
+		points = append(points, SeriesPoint{Time: base.Add(time.Duration(i) * 20 * time.Minute), Value: v})
+	}
+	got := CandlesFromSeries(points, time.Hour)
+	if len(got) != 2 {
+		t.Fatalf("len = %d, want 2", len(got))
+	}
+	want := models.OHLC{Time: base.UnixMilli(), Open: 5, High: 8, Low: 3, Close: 3}
+	if got[0] != want {
+		t.Fatalf("first candle = %+v, want %+v", got[0], want)
+	}
+	if step := SeriesStep(points); step != 20*time.Minute {
+		t.Fatalf("SeriesStep = %v", step)
+	}
+}
+
+func TestFitCandlesKeepsWholeRange(t *testing.T) {
+	data := make([]models.OHLC, 10)
+	for i := range data {
+		v := float64(i + 1)
+		data[i] = models.OHLC{Time: int64(i), Open: v, High: v + 0.5, Low: v - 0.5, Close: v}
+	}
+	got, last := fitCandles(data, 4)
+	if len(got) != 4 {
+		t.Fatalf("len = %d, want 4", len(got))
+	}
+	// Groups of 3 counted from the newest: [0] [1-3] [4-6] [7-9]
+	if got[0].Close != 1 || got[1].Open != 2 || got[1].Close != 4 || got[3].High != 10.5 {
+		t.Fatalf("unexpected merged candles: %+v", got)
+	}
+	if last[0] != 0 || last[3] != 9 {
+		t.Fatalf("last indices = %v", last)
+	}
+
+	out := RenderCandleChart(data, ChartConfig{Width: 20, Height: 8, CurrencySymbol: "$", YTickCount: 3, XTickCount: 2})
+	// Dropping old candles would lift the axis minimum to ~$5
+	if !strings.Contains(out, "$0.00 ┤") {
+		t.Fatalf("oldest candles missing from chart:\n%s", out)
+	}
+}
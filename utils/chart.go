@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/mrcnserkan/crypto/models"
 )
 
@@ -16,14 +17,52 @@ type ChartConfig struct {
 	CurrencySymbol string
 	YTickCount     int
 	XTickCount     int
+	Normalize      bool
+	Indicators     []IndicatorSpec
+	// Volume, when set, is drawn as a histogram panel under the price panel.
+	// Samples are matched to plotted points by timestamp.
+	Volume []SeriesPoint
+	// Style selects line rendering: ChartStyleASCII (default), ChartStyleBraille
+	// or ChartStyleBlock. Candlestick charts always use ASCII cells.
+	Style string
+	// LogScale plots prices on a logarithmic Y axis. It is ignored for
+	// normalized charts and for series with non-positive values.
+	LogScale bool
+	// Crosshair, when non-zero, marks the point or candle nearest this time
+	// with a vertical guide in the price panel.
+	Crosshair time.Time
 }
 
+// Chart rendering styles for line charts.
+const (
+	ChartStyleASCII   = "ascii"
+	ChartStyleBraille = "braille"
+	ChartStyleBlock   = "block"
+)
+
 // SeriesPoint is a single time-series data point for line charts.
 type SeriesPoint struct {
 	Time  time.Time
 	Value float64
 }
 
+// ChartSeries is a named series drawn by RenderMultiLineChart.
+type ChartSeries struct {
+	Name   string
+	Points []SeriesPoint
+	Color  color.Attribute
+}
+
+// SeriesPalette holds the colors assigned to series that do not set one.
+var SeriesPalette = []color.Attribute{
+	color.FgHiCyan,
+	color.FgHiYellow,
+	color.FgHiMagenta,
+	color.FgHiGreen,
+	color.FgHiRed,
+	color.FgHiBlue,
+}
+
 // DefaultChartConfig returns sensible defaults for terminal charts.
 func DefaultChartConfig() ChartConfig {
 	return ChartConfig{
@@ -50,6 +89,33 @@ func ParseChartDate(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid date format %q (use YYYY-MM-DD or YYYY-MM-DD HH:MM, UTC)", s)
 }
 
+// ParseChartStyle validates a --chart-style value.
+func ParseChartStyle(s string) (string, error) {
+	switch style := strings.ToLower(strings.TrimSpace(s)); style {
+	case "", ChartStyleASCII:
+		return ChartStyleASCII, nil
+	case ChartStyleBraille, ChartStyleBlock:
+		return style, nil
+	default:
+		return "", fmt.Errorf("invalid chart style %q (use braille, block or ascii)", s)
+	}
+}
+
+// PairsToSeries converts CoinGecko [timestamp_ms, value] pairs to series points.
+func PairsToSeries(pairs [][]float64) []SeriesPoint {
+	series := make([]SeriesPoint, 0, len(pairs))
+	for _, pair := range pairs {
+		if len(pair) < 2 {
+			continue
+		}
+		series = append(series, SeriesPoint{
+			Time:  time.Unix(int64(pair[0])/1000, 0),
+			Value: pair[1],
+		})
+	}
+	return series
+}
+
 // FilterSeriesByDateRange keeps points within [from, to] inclusive.
 func FilterSeriesByDateRange(points []SeriesPoint, from, to *time.Time) []SeriesPoint {
 	if from == nil && to == nil {
@@ -141,38 +207,207 @@ func FormatPeriodStatsLine(stats PeriodStats, symbol string) string {
 	)
 }
 
+// NormalizeSeries converts values to percent change from the first point.
+func NormalizeSeries(points []SeriesPoint) []SeriesPoint {
+	if len(points) == 0 || points[0].Value == 0 {
+		return points
+	}
+	base := points[0].Value
+	normalized := make([]SeriesPoint, len(points))
+	for i, p := range points {
+		normalized[i] = SeriesPoint{Time: p.Time, Value: (p.Value - base) / base * 100}
+	}
+	return normalized
+}
+
 // RenderLineChart draws an ASCII line chart with aligned Y and X axes.
+// Indicators in cfg are computed on the full series before downsampling.
 func RenderLineChart(points []SeriesPoint, cfg ChartConfig) string {
 	if len(points) == 0 {
 		return "No data available"
 	}
 	cfg = normalizeChartConfig(cfg)
 
-	points = downsampleLTTB(points, cfg.Width)
+	// Braille and block styles plot several points per cell, so keep more of them
+	canvas := newDotCanvas(cfg.Style, cfg.Width, cfg.Height)
+	sampleWidth := cfg.Width
+	if canvas != nil {
+		sampleWidth = canvas.pixelWidth()
+	}
+
+	values := seriesValues(points)
+	indices := lttbIndices(points, sampleWidth)
+	points = pickPoints(points, indices)
+	overlays, legend := computeOverlays(values, cfg.Indicators)
+	overlays = sampleOverlays(overlays, indices)
+
+	// On a log axis everything is plotted as log10 values
+	logScale := cfg.LogScale && canUseLogScale(values)
+	if logScale {
+		points = logSeries(points)
+		overlays = logOverlays(overlays)
+	}
+
 	minP, maxP := seriesMinMax(points)
-	minP, maxP = padPriceRange(minP, maxP)
+	minP, maxP = overlayMinMax(overlays, minP, maxP)
+	minP, maxP = padValueRange(minP, maxP, logScale)
+
+	yLabels, tickRows := buildYTickLabels(minP, maxP, cfg.Height, cfg.YTickCount, cfg.CurrencySymbol, logScale)
+	column := func(i int) int { return indexToX(i, len(points), cfg.Width) }
+
+	var plot [][]rune
+	var colors [][]color.Attribute
+	if canvas != nil {
+		pixelX := func(i int) int { return indexToX(i, len(points), canvas.pixelWidth()) }
+		for _, line := range overlays {
+			canvas.plotValues(line.values, pixelX, minP, maxP, line.color)
+		}
+		canvas.plotValues(seriesValues(points), pixelX, minP, maxP, 0)
+		plot, colors = canvas.render()
+	} else {
+		plot = newPlotGrid(cfg.Width, cfg.Height)
+		colors = newColorGrid(cfg.Width, cfg.Height)
+		for i := 1; i < len(points); i++ {
+			x0 := column(i - 1)
+			y0 := priceToRow(points[i-1].Value, minP, maxP, cfg.Height)
+			x1 := column(i)
+			y1 := priceToRow(points[i].Value, minP, maxP, cfg.Height)
+			drawLine(plot, nil, 0, x0, y0, x1, y1, '╱', '╲', '─')
+		}
+		for i, p := range points {
+			x := column(i)
+			y := priceToRow(p.Value, minP, maxP, cfg.Height)
+			plot[y][x] = '●'
+		}
+		drawOverlays(plot, colors, overlays, column, minP, maxP)
+	}
+	if !cfg.Crosshair.IsZero() {
+		drawCrosshair(plot, column(NearestPointIndex(points, cfg.Crosshair)))
+	}
+
+	panels := []chartPanel{{plot: plot, colors: colors, yLabels: yLabels, tickRows: tickRows}}
+	if len(cfg.Volume) > 0 {
+		rising := make([]bool, len(points))
+		for i := range points {
+			rising[i] = i == 0 || points[i].Value >= points[i-1].Value
+		}
+		panels = append(panels, volumePanel(points, rising, cfg, column))
+	}
+	panels = append(panels, indicatorPanels(values, indices, cfg, column)...)
+	return assembleChart(panels, cfg, points, legend)
+}
+
+// RenderMultiLineChart draws several series on shared axes with a color legend.
+// Series are placed on the X axis by timestamp, so they need not share sample
+// times. With cfg.Normalize set, each series is plotted as percent change from
+// its first point; otherwise cfg.LogScale applies when every value is positive.
+func RenderMultiLineChart(series []ChartSeries, cfg ChartConfig) string {
+	cfg = normalizeChartConfig(cfg)
+
+	canvas := newDotCanvas(cfg.Style, cfg.Width, cfg.Height)
+	sampleWidth := cfg.Width
+	if canvas != nil {
+		sampleWidth = canvas.pixelWidth()
+	}
+
+	prepared := make([]ChartSeries, 0, len(series))
+	for i, s := range series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		if s.Color == 0 {
+			s.Color = SeriesPalette[i%len(SeriesPalette)]
+		}
+		if cfg.Normalize {
+			s.Points = NormalizeSeries(s.Points)
+		}
+		s.Points = downsampleLTTB(s.Points, sampleWidth)
+		prepared = append(prepared, s)
+	}
+	if len(prepared) == 0 {
+		return "No data available"
+	}
+
+	logScale := cfg.LogScale && !cfg.Normalize
+	for _, s := range prepared {
+		logScale = logScale && canUseLogScale(seriesValues(s.Points))
+	}
+	// Legend values come from the raw series, so transform a copy
+	plotted := make([]ChartSeries, len(prepared))
+	copy(plotted, prepared)
+	if logScale {
+		for i := range plotted {
+			plotted[i].Points = logSeries(plotted[i].Points)
+		}
+	}
+
+	minP, maxP := seriesMinMax(plotted[0].Points)
+	start, end := seriesTimeRange(plotted[0].Points)
+	for _, s := range plotted[1:] {
+		sMin, sMax := seriesMinMax(s.Points)
+		minP = math.Min(minP, sMin)
+		maxP = math.Max(maxP, sMax)
+		sStart, sEnd := seriesTimeRange(s.Points)
+		if sStart.Before(start) {
+			start = sStart
+		}
+		if sEnd.After(end) {
+			end = sEnd
+		}
+	}
+	minP, maxP = padValueRange(minP, maxP, logScale)
 
-	yLabels := buildYTickLabels(minP, maxP, cfg.YTickCount, cfg.CurrencySymbol)
-	yAxisWidth := maxStringLen(yLabels)
+	var yLabels []string
+	var tickRows []int
+	if cfg.Normalize {
+		yLabels = buildPercentTickLabels(minP, maxP, cfg.YTickCount)
+	} else {
+		yLabels, tickRows = buildYTickLabels(minP, maxP, cfg.Height, cfg.YTickCount, cfg.CurrencySymbol, logScale)
+	}
 
 	plot := newPlotGrid(cfg.Width, cfg.Height)
-	for i := 1; i < len(points); i++ {
-		x0 := indexToX(i-1, len(points), cfg.Width)
-		y0 := priceToRow(points[i-1].Value, minP, maxP, cfg.Height)
-		x1 := indexToX(i, len(points), cfg.Width)
-		y1 := priceToRow(points[i].Value, minP, maxP, cfg.Height)
-		drawLine(plot, x0, y0, x1, y1, '╱', '╲', '─')
+	colors := newColorGrid(cfg.Width, cfg.Height)
+	legend := make([]legendEntry, 0, len(prepared))
+	for i, s := range plotted {
+		if canvas != nil {
+			points := s.Points
+			pixelX := func(i int) int { return timeToX(points[i].Time, start, end, canvas.pixelWidth()) }
+			canvas.plotValues(seriesValues(points), pixelX, minP, maxP, s.Color)
+		} else {
+			for i := 1; i < len(s.Points); i++ {
+				x0 := timeToX(s.Points[i-1].Time, start, end, cfg.Width)
+				y0 := priceToRow(s.Points[i-1].Value, minP, maxP, cfg.Height)
+				x1 := timeToX(s.Points[i].Time, start, end, cfg.Width)
+				y1 := priceToRow(s.Points[i].Value, minP, maxP, cfg.Height)
+				drawLine(plot, colors, s.Color, x0, y0, x1, y1, '╱', '╲', '─')
+			}
+			for _, p := range s.Points {
+				x := timeToX(p.Time, start, end, cfg.Width)
+				y := priceToRow(p.Value, minP, maxP, cfg.Height)
+				plot[y][x] = '●'
+				colors[y][x] = s.Color
+			}
+		}
+
+		last := prepared[i].Points[len(prepared[i].Points)-1].Value
+		valueText := cfg.CurrencySymbol + FormatCurrency(last)
+		if cfg.Normalize {
+			valueText = formatPercentTick(last)
+		}
+		legend = append(legend, legendEntry{label: fmt.Sprintf("● %s %s", s.Name, valueText), color: s.Color})
 	}
-	for i, p := range points {
-		x := indexToX(i, len(points), cfg.Width)
-		y := priceToRow(p.Value, minP, maxP, cfg.Height)
-		plot[y][x] = '●'
+	if canvas != nil {
+		plot, colors = canvas.render()
 	}
 
-	return assembleChart(plot, yLabels, yAxisWidth, cfg, points)
+	panels := []chartPanel{{plot: plot, colors: colors, yLabels: yLabels, tickRows: tickRows}}
+	return assembleChart(panels, cfg, timeAxisPoints(start, end, cfg.Width), legend)
 }
 
 // RenderCandleChart draws an ASCII candlestick chart with Y and X axes.
+// When there are more candles than fit the width, consecutive candles are
+// merged so the whole range stays visible. Indicators in cfg are computed on
+// closes of the original candles.
 func RenderCandleChart(data []models.OHLC, cfg ChartConfig) string {
 	if len(data) == 0 {
 		return "No data available"
@@ -181,8 +416,20 @@ func RenderCandleChart(data []models.OHLC, cfg ChartConfig) string {
 
 	candleWidth := 3
 	maxCandles := cfg.Width / (candleWidth + 1)
-	if len(data) > maxCandles {
-		data = data[len(data)-maxCandles:]
+	closes := make([]float64, len(data))
+	for i, c := range data {
+		closes[i] = c.Close
+	}
+	data, indices := fitCandles(data, maxCandles)
+	overlays, legend := computeOverlays(closes, cfg.Indicators)
+	overlays = sampleOverlays(overlays, indices)
+
+	// Keep the raw candles for the X axis and volume coloring
+	candles := data
+	logScale := cfg.LogScale && canUseLogScale(ohlcLows(data))
+	if logScale {
+		data = logOHLC(data)
+		overlays = logOverlays(overlays)
 	}
 
 	var minP, maxP float64
@@ -196,14 +443,16 @@ func RenderCandleChart(data []models.OHLC, cfg ChartConfig) string {
 			maxP = c.High
 		}
 	}
-	minP, maxP = padPriceRange(minP, maxP)
+	minP, maxP = overlayMinMax(overlays, minP, maxP)
+	minP, maxP = padValueRange(minP, maxP, logScale)
 
-	yLabels := buildYTickLabels(minP, maxP, cfg.YTickCount, cfg.CurrencySymbol)
-	yAxisWidth := maxStringLen(yLabels)
+	yLabels, tickRows := buildYTickLabels(minP, maxP, cfg.Height, cfg.YTickCount, cfg.CurrencySymbol, logScale)
+	column := func(i int) int { return i*(candleWidth+1) + candleWidth/2 }
 
 	plot := newPlotGrid(cfg.Width, cfg.Height)
+	colors := newColorGrid(cfg.Width, cfg.Height)
 	for i, candle := range data {
-		xCenter := i*(candleWidth+1) + candleWidth/2
+		xCenter := column(i)
 		if xCenter >= cfg.Width {
 			break
 		}
@@ -236,9 +485,22 @@ func RenderCandleChart(data []models.OHLC, cfg ChartConfig) string {
 			}
 		}
 	}
+	drawOverlays(plot, colors, overlays, column, minP, maxP)
+	if !cfg.Crosshair.IsZero() {
+		drawCrosshair(plot, column(CandleIndexAt(candles, cfg.Crosshair)))
+	}
 
-	series := ohlcToSeries(data)
-	return assembleChart(plot, yLabels, yAxisWidth, cfg, series)
+	series := ohlcToSeries(candles)
+	panels := []chartPanel{{plot: plot, colors: colors, yLabels: yLabels, tickRows: tickRows}}
+	if len(cfg.Volume) > 0 {
+		rising := make([]bool, len(candles))
+		for i, c := range candles {
+			rising[i] = c.Close >= c.Open
+		}
+		panels = append(panels, volumePanel(series, rising, cfg, column))
+	}
+	panels = append(panels, indicatorPanels(closes, indices, cfg, column)...)
+	return assembleChart(panels, cfg, series, legend)
 }
 
 func normalizeChartConfig(cfg ChartConfig) ChartConfig {
@@ -257,6 +519,9 @@ func normalizeChartConfig(cfg ChartConfig) ChartConfig {
 	if cfg.CurrencySymbol == "" {
 		cfg.CurrencySymbol = "$"
 	}
+	if cfg.Style == "" {
+		cfg.Style = ChartStyleASCII
+	}
 	return cfg
 }
 
@@ -269,16 +534,51 @@ func padPriceRange(minP, maxP float64) (float64, float64) {
 	return minP - padding, maxP + padding
 }
 
-func buildYTickLabels(minP, maxP float64, tickCount int, symbol string) []string {
+// padValueRange pads the plotted range; on a log axis minP and maxP are
+// log10 values, so the padding is applied in log space.
+func padValueRange(minP, maxP float64, logScale bool) (float64, float64) {
+	if !logScale {
+		return padPriceRange(minP, maxP)
+	}
+	if minP == maxP {
+		return minP - 0.005, maxP + 0.005
+	}
+	padding := (maxP - minP) * 0.05
+	return minP - padding, maxP + padding
+}
+
+// buildYTickLabels returns price labels for the Y axis. Linear ticks are
+// evenly spaced and return nil rows; log ticks (minP and maxP in log10) land
+// on rounded prices and return the plot row of each label.
+func buildYTickLabels(minP, maxP float64, height, tickCount int, symbol string, logScale bool) ([]string, []int) {
+	if logScale {
+		return buildLogTickLabels(minP, maxP, height, tickCount, symbol)
+	}
 	labels := make([]string, tickCount)
 	for i := 0; i < tickCount; i++ {
 		ratio := float64(i) / float64(tickCount-1)
 		price := maxP - ratio*(maxP-minP)
 		labels[i] = symbol + FormatCurrency(price)
 	}
+	return labels, nil
+}
+
+func buildPercentTickLabels(minP, maxP float64, tickCount int) []string {
+	labels := make([]string, tickCount)
+	for i := 0; i < tickCount; i++ {
+		ratio := float64(i) / float64(tickCount-1)
+		labels[i] = formatPercentTick(maxP - ratio*(maxP-minP))
+	}
 	return labels
 }
 
+func formatPercentTick(value float64) string {
+	if value >= 0 {
+		return fmt.Sprintf("+%.2f%%", value)
+	}
+	return fmt.Sprintf("%.2f%%", value)
+}
+
 func maxStringLen(strs []string) int {
 	maxLen := 0
 	for _, s := range strs {
@@ -300,6 +600,14 @@ func newPlotGrid(width, height int) [][]rune {
 	return grid
 }
 
+func newColorGrid(width, height int) [][]color.Attribute {
+	grid := make([][]color.Attribute, height)
+	for y := 0; y < height; y++ {
+		grid[y] = make([]color.Attribute, width)
+	}
+	return grid
+}
+
 func priceToRow(price, minP, maxP float64, height int) int {
 	if maxP == minP {
 		return height / 2
@@ -316,7 +624,29 @@ func indexToX(index, total, width int) int {
 	return int(float64(index) / float64(total-1) * float64(width-1))
 }
 
-func drawLine(grid [][]rune, x0, y0, x1, y1 int, up, down, flat rune) {
+func timeToX(t, start, end time.Time, width int) int {
+	if !end.After(start) {
+		return width / 2
+	}
+	ratio := float64(t.Sub(start)) / float64(end.Sub(start))
+	return clampInt(int(math.Round(ratio*float64(width-1))), 0, width-1)
+}
+
+// timeAxisPoints returns one evenly spaced point per column so the index-based
+// X tick logic in assembleChart lines up with time-based plotting.
+func timeAxisPoints(start, end time.Time, width int) []SeriesPoint {
+	points := make([]SeriesPoint, width)
+	step := time.Duration(0)
+	if width > 1 {
+		step = end.Sub(start) / time.Duration(width-1)
+	}
+	for i := range points {
+		points[i] = SeriesPoint{Time: start.Add(time.Duration(i) * step)}
+	}
+	return points
+}
+
+func drawLine(grid [][]rune, colors [][]color.Attribute, attr color.Attribute, x0, y0, x1, y1 int, up, down, flat rune) {
 	dx := intAbs(x1 - x0)
 	dy := intAbs(y1 - y0)
 	sx, sy := 1, 1
@@ -338,6 +668,9 @@ func drawLine(grid [][]rune, x0, y0, x1, y1 int, up, down, flat rune) {
 			}
 			if grid[y][x] == ' ' {
 				grid[y][x] = ch
+				if colors != nil {
+					colors[y][x] = attr
+				}
 			}
 		}
 		if x == x1 && y == y1 {
@@ -355,32 +688,38 @@ func drawLine(grid [][]rune, x0, y0, x1, y1 int, up, down, flat rune) {
 	}
 }
 
-func assembleChart(plot [][]rune, yLabels []string, yAxisWidth int, cfg ChartConfig, points []SeriesPoint) string {
-	height := len(plot)
-	width := len(plot[0])
-	tickCount := len(yLabels)
+// chartPanel is a block of plot rows drawn above the shared X axis. Panels
+// after the first are separated by a header line carrying their legend.
+type chartPanel struct {
+	legend  []legendEntry
+	plot    [][]rune
+	colors  [][]color.Attribute
+	yLabels []string
+	// tickRows places each Y label on a row; nil spaces labels evenly.
+	tickRows []int
+}
 
-	tickRows := make(map[int]int)
-	for i := 0; i < tickCount; i++ {
-		row := 0
-		if tickCount > 1 {
-			row = int(math.Round(float64(i) / float64(tickCount-1) * float64(height-1)))
-		}
-		tickRows[row] = i
+// legendEntry is a colored label shown in a legend line.
+type legendEntry struct {
+	label string
+	color color.Attribute
+}
+
+// assembleChart stacks the panels with a common Y label width, then draws the
+// X axis for points and an optional legend line below it.
+func assembleChart(panels []chartPanel, cfg ChartConfig, points []SeriesPoint, legend []legendEntry) string {
+	width := len(panels[0].plot[0])
+	yAxisWidth := 0
+	for _, panel := range panels {
+		yAxisWidth = intMax(yAxisWidth, maxStringLen(panel.yLabels))
 	}
 
 	var sb strings.Builder
-	for row := 0; row < height; row++ {
-		label := strings.Repeat(" ", yAxisWidth)
-		if labelIdx, ok := tickRows[row]; ok {
-			label = fmt.Sprintf("%*s", yAxisWidth, yLabels[labelIdx])
+	for i, panel := range panels {
+		if i > 0 {
+			writePanelHeader(&sb, panel.legend, yAxisWidth, width)
 		}
-		sb.WriteString(label)
-		sb.WriteString(" ┤")
-		for x := 0; x < width; x++ {
-			sb.WriteRune(plot[row][x])
-		}
-		sb.WriteByte('\n')
+		writePanelRows(&sb, panel, yAxisWidth)
 	}
 
 	sb.WriteString(strings.Repeat(" ", yAxisWidth))
@@ -410,9 +749,92 @@ func assembleChart(plot [][]rune, yLabels []string, yAxisWidth int, cfg ChartCon
 		sb.WriteByte('\n')
 	}
 
+	if len(legend) > 0 {
+		sb.WriteString(strings.Repeat(" ", yAxisWidth+2))
+		writeLegend(&sb, legend)
+		sb.WriteByte('\n')
+	}
+
 	return sb.String()
 }
 
+func writePanelRows(sb *strings.Builder, panel chartPanel, yAxisWidth int) {
+	height := len(panel.plot)
+	tickCount := len(panel.yLabels)
+
+	tickRows := make(map[int]int)
+	for i := 0; i < tickCount; i++ {
+		row := 0
+		if panel.tickRows != nil {
+			row = panel.tickRows[i]
+		} else if tickCount > 1 {
+			row = int(math.Round(float64(i) / float64(tickCount-1) * float64(height-1)))
+		}
+		tickRows[row] = i
+	}
+
+	for row := 0; row < height; row++ {
+		label := strings.Repeat(" ", yAxisWidth)
+		if labelIdx, ok := tickRows[row]; ok {
+			label = fmt.Sprintf("%*s", yAxisWidth, panel.yLabels[labelIdx])
+		}
+		sb.WriteString(label)
+		sb.WriteString(" ┤")
+		if panel.colors == nil {
+			sb.WriteString(string(panel.plot[row]))
+		} else {
+			writeColoredRow(sb, panel.plot[row], panel.colors[row])
+		}
+		sb.WriteByte('\n')
+	}
+}
+
+func writePanelHeader(sb *strings.Builder, legend []legendEntry, yAxisWidth, width int) {
+	sb.WriteString(strings.Repeat(" ", yAxisWidth))
+	sb.WriteString(" ┼─ ")
+	used := 2
+	for i, entry := range legend {
+		if i > 0 {
+			sb.WriteString("  ")
+			used += 2
+		}
+		sb.WriteString(color.New(entry.color).Sprint(entry.label))
+		used += len([]rune(entry.label))
+	}
+	sb.WriteByte(' ')
+	used++
+	if used < width {
+		sb.WriteString(strings.Repeat("─", width-used))
+	}
+	sb.WriteByte('\n')
+}
+
+func writeLegend(sb *strings.Builder, legend []legendEntry) {
+	for i, entry := range legend {
+		if i > 0 {
+			sb.WriteString("  ")
+		}
+		sb.WriteString(color.New(entry.color).Sprint(entry.label))
+	}
+}
+
+// writeColoredRow emits runs of equally colored cells with a single escape sequence each.
+func writeColoredRow(sb *strings.Builder, cells []rune, colors []color.Attribute) {
+	start := 0
+	for x := 1; x <= len(cells); x++ {
+		if x < len(cells) && colors[x] == colors[start] {
+			continue
+		}
+		run := string(cells[start:x])
+		if colors[start] == 0 {
+			sb.WriteString(run)
+		} else {
+			sb.WriteString(color.New(colors[start]).Sprint(run))
+		}
+		start = x
+	}
+}
+
 type xTick struct {
 	index int
 	time  time.Time
@@ -472,12 +894,22 @@ func placeXLabel(line []rune, label string, pos, width, align int) {
 }
 
 func downsampleLTTB(points []SeriesPoint, threshold int) []SeriesPoint {
+	return pickPoints(points, lttbIndices(points, threshold))
+}
+
+// lttbIndices returns the indices of points kept by Largest-Triangle-Three-Buckets
+// downsampling, so parallel series (indicators) can be sampled identically.
+func lttbIndices(points []SeriesPoint, threshold int) []int {
 	if len(points) <= threshold || threshold < 3 {
-		return points
+		indices := make([]int, len(points))
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices
 	}
 
-	result := make([]SeriesPoint, 0, threshold)
-	result = append(result, points[0])
+	result := make([]int, 0, threshold)
+	result = append(result, 0)
 
 	bucketSize := float64(len(points)-2) / float64(threshold-2)
 	a := 0
@@ -519,14 +951,25 @@ func downsampleLTTB(points []SeriesPoint, threshold int) []SeriesPoint {
 				nextIdx = j
 			}
 		}
-		result = append(result, points[nextIdx])
+		result = append(result, nextIdx)
 		a = nextIdx
 	}
 
-	result = append(result, points[len(points)-1])
+	result = append(result, len(points)-1)
 	return result
 }
 
+func pickPoints(points []SeriesPoint, indices []int) []SeriesPoint {
+	if len(indices) == len(points) {
+		return points
+	}
+	picked := make([]SeriesPoint, len(indices))
+	for i, idx := range indices {
+		picked[i] = points[idx]
+	}
+	return picked
+}
+
 func downsampleSeries(points []SeriesPoint, maxPoints int) []SeriesPoint {
 	if len(points) <= maxPoints || maxPoints < 2 {
 		return points
@@ -556,6 +999,18 @@ func seriesMinMax(points []SeriesPoint) (float64, float64) {
 	return minP, maxP
 }
 
+func seriesValues(points []SeriesPoint) []float64 {
+	values := make([]float64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+func seriesTimeRange(points []SeriesPoint) (time.Time, time.Time) {
+	return points[0].Time, points[len(points)-1].Time
+}
+
 func ohlcToSeries(data []models.OHLC) []SeriesPoint {
 	series := make([]SeriesPoint, len(data))
 	for i, c := range data {
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"math"
+
+	"github.com/fatih/color"
+)
+
+// brailleDots maps a dot position [x][y] within a cell to its braille bit.
+var brailleDots = [2][4]uint8{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// dotCanvas is a sub-cell bitmap drawn with braille (2x4 dots per cell) or
+// half-block (1x2 dots per cell) runes. Each cell keeps the color of the
+// last series that touched it.
+type dotCanvas struct {
+	style  string
+	width  int
+	height int
+	dotsX  int
+	dotsY  int
+	bits   [][]uint8
+	colors [][]color.Attribute
+}
+
+// newDotCanvas returns a canvas for the braille or block style, or nil for ascii.
+func newDotCanvas(style string, width, height int) *dotCanvas {
+	c := &dotCanvas{style: style, width: width, height: height}
+	switch style {
+	case ChartStyleBraille:
+		c.dotsX, c.dotsY = 2, 4
+	case ChartStyleBlock:
+		c.dotsX, c.dotsY = 1, 2
+	default:
+		return nil
+	}
+	c.bits = make([][]uint8, height)
+	for y := range c.bits {
+		c.bits[y] = make([]uint8, width)
+	}
+	c.colors = newColorGrid(width, height)
+	return c
+}
+
+func (c *dotCanvas) pixelWidth() int {
+	return c.width * c.dotsX
+}
+
+func (c *dotCanvas) pixelHeight() int {
+	return c.height * c.dotsY
+}
+
+func (c *dotCanvas) set(px, py int, attr color.Attribute) {
+	if px < 0 || py < 0 || px >= c.pixelWidth() || py >= c.pixelHeight() {
+		return
+	}
+	cx, cy := px/c.dotsX, py/c.dotsY
+	dx, dy := px%c.dotsX, py%c.dotsY
+	if c.style == ChartStyleBraille {
+		c.bits[cy][cx] |= brailleDots[dx][dy]
+	} else {
+		c.bits[cy][cx] |= 1 << uint(dy)
+	}
+	c.colors[cy][cx] = attr
+}
+
+// line draws a Bresenham line between two dot positions.
+func (c *dotCanvas) line(x0, y0, x1, y1 int, attr color.Attribute) {
+	dx := intAbs(x1 - x0)
+	dy := intAbs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 >= x1 {
+		sx = -1
+	}
+	if y0 >= y1 {
+		sy = -1
+	}
+	err := dx - dy
+	x, y := x0, y0
+	for {
+		c.set(x, y, attr)
+		if x == x1 && y == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// plotValues connects consecutive defined values; NaN values break the line.
+// pixelX maps a value index to a dot column.
+func (c *dotCanvas) plotValues(values []float64, pixelX func(int) int, minV, maxV float64, attr color.Attribute) {
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		x := pixelX(i)
+		y := priceToRow(v, minV, maxV, c.pixelHeight())
+		if i > 0 && !math.IsNaN(values[i-1]) {
+			c.line(pixelX(i-1), priceToRow(values[i-1], minV, maxV, c.pixelHeight()), x, y, attr)
+		} else {
+			c.set(x, y, attr)
+		}
+	}
+}
+
+// render converts the bitmap to plot runes and per-cell colors.
+func (c *dotCanvas) render() ([][]rune, [][]color.Attribute) {
+	plot := newPlotGrid(c.width, c.height)
+	for y := range c.bits {
+		for x, bits := range c.bits[y] {
+			if bits == 0 {
+				continue
+			}
+			if c.style == ChartStyleBraille {
+				plot[y][x] = rune(0x2800 + int(bits))
+				continue
+			}
+			switch bits {
+			case 1:
+				plot[y][x] = '▀'
+			case 2:
+				plot[y][x] = '▄'
+			default:
+				plot[y][x] = '█'
+			}
+		}
+	}
+	return plot, c.colors
+}
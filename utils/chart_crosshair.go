@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// crosshairRune marks the crosshair column in otherwise empty cells.
+const crosshairRune = '┊'
+
+// NearestPointIndex returns the index of the point closest in time to t, or
+// -1 for an empty series. Points must be sorted by time.
+func NearestPointIndex(points []SeriesPoint, t time.Time) int {
+	if len(points) == 0 {
+		return -1
+	}
+	i := sort.Search(len(points), func(i int) bool { return !points[i].Time.Before(t) })
+	if i == len(points) {
+		return len(points) - 1
+	}
+	if i > 0 && t.Sub(points[i-1].Time) < points[i].Time.Sub(t) {
+		return i - 1
+	}
+	return i
+}
+
+// CandleIndexAt returns the index of the candle whose period contains t: the
+// last candle opening at or before t, or the first candle when t is earlier.
+// It returns -1 when there are no candles.
+func CandleIndexAt(data []models.OHLC, t time.Time) int {
+	if len(data) == 0 {
+		return -1
+	}
+	ms := t.UnixMilli()
+	i := sort.Search(len(data), func(i int) bool { return data[i].Time > ms })
+	return intMax(i-1, 0)
+}
+
+// drawCrosshair draws a vertical guide through the empty cells of column x so
+// plotted data stays visible.
+func drawCrosshair(plot [][]rune, x int) {
+	for y := range plot {
+		if x >= 0 && x < len(plot[y]) && plot[y][x] == ' ' {
+			plot[y][x] = crosshairRune
+		}
+	}
+}
@@ -0,0 +1,469 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// Chart export formats, selected by file extension.
+const (
+	ExportFormatSVG = "svg"
+	ExportFormatPNG = "png"
+)
+
+const (
+	exportWidth       = 1200
+	exportMainHeight  = 460
+	exportSubHeight   = 140
+	exportPanelGap    = 30
+	exportMarginTop   = 76
+	exportMarginRight = 24
+	exportCharWidth   = 12
+	exportFontSize    = 14
+)
+
+// rgb is an export color.
+type rgb struct{ r, g, b uint8 }
+
+func (c rgb) hex() string {
+	return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b)
+}
+
+var (
+	exportBackground = rgb{0xff, 0xff, 0xff}
+	exportText       = rgb{0x1f, 0x29, 0x37}
+	exportMuted      = rgb{0x6b, 0x72, 0x80}
+	exportGrid       = rgb{0xe5, 0xe7, 0xeb}
+	exportAxis       = rgb{0x9c, 0xa3, 0xaf}
+	exportLine       = rgb{0x02, 0x84, 0xc7}
+	exportUp         = rgb{0x16, 0xa3, 0x4a}
+	exportDown       = rgb{0xdc, 0x26, 0x26}
+)
+
+// exportColors maps terminal colors used by chart panels to export colors.
+var exportColors = map[color.Attribute]rgb{
+	color.FgHiYellow:  {0xca, 0x8a, 0x04},
+	color.FgHiMagenta: {0xc0, 0x26, 0xd3},
+	color.FgHiBlue:    {0x25, 0x63, 0xeb},
+	color.FgHiGreen:   exportUp,
+	color.FgHiRed:     exportDown,
+	color.FgHiCyan:    {0x08, 0x91, 0xb2},
+	color.FgHiBlack:   exportAxis,
+	color.FgGreen:     exportUp,
+	color.FgRed:       exportDown,
+}
+
+func exportColor(attr color.Attribute) rgb {
+	if c, ok := exportColors[attr]; ok {
+		return c
+	}
+	return exportText
+}
+
+// textAnchor aligns text horizontally around its x position.
+type textAnchor int
+
+const (
+	anchorStart textAnchor = iota
+	anchorMiddle
+	anchorEnd
+)
+
+type exportPoint struct{ x, y float64 }
+
+// exportCanvas is a drawing surface shared by the SVG and PNG writers.
+// Text y positions are baselines.
+type exportCanvas interface {
+	rect(x, y, w, h float64, fill rgb)
+	polyline(points []exportPoint, stroke rgb, width float64, dashed bool)
+	text(x, y float64, s string, fill rgb, anchor textAnchor)
+}
+
+// ChartExport is a single-coin chart written by ExportChart. Set Candles for
+// a candlestick chart; otherwise Points is drawn as a line. Config supplies
+// the currency symbol, tick counts, indicators, volume and log scale; its
+// Width, Height and Style only apply to terminal output.
+type ChartExport struct {
+	Title string
+	// Label names the line series in the legend; it defaults to "Price".
+	Label   string
+	Points  []SeriesPoint
+	Candles []models.OHLC
+	Config  ChartConfig
+}
+
+// ExportFormat returns the image format for path's extension.
+func ExportFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".svg":
+		return ExportFormatSVG, nil
+	case ".png":
+		return ExportFormatPNG, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use .svg or .png)", ext)
+	}
+}
+
+// ExportChart renders exp to path as SVG or PNG, chosen by the file extension.
+func ExportChart(path string, exp ChartExport) error {
+	format, err := ExportFormat(path)
+	if err != nil {
+		return err
+	}
+	var data []byte
+	if format == ExportFormatSVG {
+		data = RenderChartSVG(exp)
+	} else if data, err = RenderChartPNG(exp); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// RenderChartSVG renders exp as an SVG document.
+func RenderChartSVG(exp ChartExport) []byte {
+	scene := newExportScene(exp)
+	c := newSVGCanvas(scene.width, scene.height)
+	scene.draw(c)
+	return c.bytes()
+}
+
+// RenderChartPNG renders exp as a PNG image.
+func RenderChartPNG(exp ChartExport) ([]byte, error) {
+	scene := newExportScene(exp)
+	c := newPNGCanvas(scene.width, scene.height)
+	scene.draw(c)
+	return c.encode()
+}
+
+// exportPanel is a plot area stacked under the main price panel.
+type exportPanel struct {
+	top, height float64
+	draw        func(c exportCanvas, top, height float64)
+}
+
+// exportScene lays out a chart export. Values are in log10 when logScale is set.
+type exportScene struct {
+	exp      ChartExport
+	cfg      ChartConfig
+	points   []SeriesPoint
+	candles  []models.OHLC
+	values   []float64
+	overlays []overlayLine
+	legend   []legendEntry
+	logScale bool
+	minP     float64
+	maxP     float64
+	yLabels  []string
+	tickRows []int
+	panels   []exportPanel
+
+	width, height int
+	plotLeft      float64
+	plotWidth     float64
+}
+
+func newExportScene(exp ChartExport) *exportScene {
+	s := &exportScene{exp: exp, cfg: normalizeChartConfig(exp.Config)}
+	s.points = exp.Points
+	s.candles = exp.Candles
+	if len(s.candles) > 0 {
+		s.points = ohlcToSeries(s.candles)
+	}
+	s.values = seriesValues(s.points)
+	s.overlays, s.legend = computeOverlays(s.values, s.cfg.Indicators)
+
+	if len(s.candles) > 0 {
+		s.logScale = s.cfg.LogScale && canUseLogScale(ohlcLows(s.candles))
+	} else {
+		s.logScale = s.cfg.LogScale && canUseLogScale(s.values)
+	}
+	plotted := s.values
+	if s.logScale {
+		plotted = logValues(s.values)
+		s.candles = logOHLC(s.candles)
+		s.overlays = logOverlays(s.overlays)
+	}
+
+	s.minP, s.maxP = math.Inf(1), math.Inf(-1)
+	if len(s.candles) > 0 {
+		for _, c := range s.candles {
+			s.minP = math.Min(s.minP, c.Low)
+			s.maxP = math.Max(s.maxP, c.High)
+		}
+	} else {
+		s.minP, s.maxP = valuesMinMax(plotted, s.minP, s.maxP)
+	}
+	if len(s.points) > 0 {
+		s.minP, s.maxP = overlayMinMax(s.overlays, s.minP, s.maxP)
+		s.minP, s.maxP = padValueRange(s.minP, s.maxP, s.logScale)
+	} else {
+		s.minP, s.maxP = 0, 1
+	}
+	s.yLabels, s.tickRows = buildYTickLabels(s.minP, s.maxP, exportMainHeight, s.cfg.YTickCount, s.cfg.CurrencySymbol, s.logScale)
+
+	// Stack the sub-panels under the main panel
+	top := float64(exportMarginTop + exportMainHeight + exportPanelGap)
+	labelLen := maxStringLen(s.yLabels)
+	addPanel := func(labels []string, draw func(c exportCanvas, top, height float64)) {
+		s.panels = append(s.panels, exportPanel{top: top, height: exportSubHeight, draw: draw})
+		top += exportSubHeight + exportPanelGap
+		labelLen = intMax(labelLen, maxStringLen(labels))
+	}
+	if len(s.cfg.Volume) > 0 && len(s.points) > 0 {
+		labels, draw := s.volumePanel()
+		addPanel(labels, draw)
+	}
+	for _, spec := range s.cfg.Indicators {
+		switch spec.Kind {
+		case IndicatorRSI:
+			labels, draw := s.rsiPanel(spec)
+			addPanel(labels, draw)
+		case IndicatorMACD:
+			labels, draw := s.macdPanel(spec)
+			addPanel(labels, draw)
+		}
+	}
+
+	s.plotLeft = float64(labelLen*exportCharWidth + 24)
+	s.width = exportWidth
+	s.plotWidth = float64(s.width) - s.plotLeft - exportMarginRight
+	// X labels and the legend sit under the last panel
+	s.height = int(top) - exportPanelGap + 64
+	return s
+}
+
+// xAt returns the x center of point i. Candles sit in equal slots; line
+// points span the full width.
+func (s *exportScene) xAt(i int) float64 {
+	n := len(s.points)
+	if len(s.candles) > 0 {
+		return s.plotLeft + (float64(i)+0.5)*s.plotWidth/float64(n)
+	}
+	if n < 2 {
+		return s.plotLeft + s.plotWidth/2
+	}
+	return s.plotLeft + float64(i)*s.plotWidth/float64(n-1)
+}
+
+func (s *exportScene) slotWidth() float64 {
+	return s.plotWidth / float64(intMax(1, len(s.points)))
+}
+
+func valueToY(v, minV, maxV, top, height float64) float64 {
+	if maxV == minV {
+		return top + height/2
+	}
+	return top + (maxV-v)/(maxV-minV)*height
+}
+
+func (s *exportScene) draw(c exportCanvas) {
+	c.text(s.plotLeft, 30, s.exp.Title, exportText, anchorStart)
+	if len(s.points) == 0 {
+		c.text(float64(s.width)/2, float64(s.height)/2, "No data available", exportMuted, anchorMiddle)
+		return
+	}
+	stats := FormatPeriodStatsLine(ComputePeriodStats(s.points), s.cfg.CurrencySymbol)
+	if s.logScale {
+		stats += "  | log scale"
+	}
+	c.text(s.plotLeft, 56, stats, exportMuted, anchorStart)
+
+	mainTop := float64(exportMarginTop)
+	s.drawYAxis(c, mainTop, exportMainHeight, s.yLabels, s.tickRows)
+	s.drawMain(c, mainTop, exportMainHeight)
+	for _, panel := range s.panels {
+		panel.draw(c, panel.top, panel.height)
+	}
+
+	bottom := float64(s.height - 64)
+	ticks := buildXTickLabels(s.points, s.cfg.XTickCount)
+	for i, tick := range ticks {
+		anchor := anchorMiddle
+		if i == 0 {
+			anchor = anchorStart
+		} else if i == len(ticks)-1 {
+			anchor = anchorEnd
+		}
+		x := s.xAt(tick.index)
+		c.polyline([]exportPoint{{x, bottom}, {x, bottom + 6}}, exportAxis, 1, false)
+		c.text(x, bottom+24, formatXLabel(tick.time, s.points), exportMuted, anchor)
+	}
+
+	s.drawLegend(c, bottom+52)
+}
+
+// drawYAxis draws the panel frame, grid lines and right-aligned labels.
+// Labels are spaced evenly unless rows gives their offsets.
+func (s *exportScene) drawYAxis(c exportCanvas, top, height float64, labels []string, rows []int) {
+	right := s.plotLeft + s.plotWidth
+	for i, label := range labels {
+		y := top
+		if rows != nil {
+			y += float64(rows[i])
+		} else if len(labels) > 1 {
+			y += float64(i) / float64(len(labels)-1) * height
+		}
+		c.polyline([]exportPoint{{s.plotLeft, y}, {right, y}}, exportGrid, 1, false)
+		c.text(s.plotLeft-10, y+5, label, exportMuted, anchorEnd)
+	}
+	c.polyline([]exportPoint{
+		{s.plotLeft, top}, {s.plotLeft, top + height}, {right, top + height},
+	}, exportAxis, 1, false)
+}
+
+func (s *exportScene) drawMain(c exportCanvas, top, height float64) {
+	y := func(v float64) float64 { return valueToY(v, s.minP, s.maxP, top, height) }
+	if len(s.candles) > 0 {
+		body := math.Max(1, s.slotWidth()*0.6)
+		for i, candle := range s.candles {
+			x := s.xAt(i)
+			fill := exportUp
+			if candle.Close < candle.Open {
+				fill = exportDown
+			}
+			c.polyline([]exportPoint{{x, y(candle.High)}, {x, y(candle.Low)}}, fill, 1, false)
+			bodyTop := y(math.Max(candle.Open, candle.Close))
+			bodyHeight := math.Max(1, y(math.Min(candle.Open, candle.Close))-bodyTop)
+			c.rect(x-body/2, bodyTop, body, bodyHeight, fill)
+		}
+	} else {
+		values := s.values
+		if s.logScale {
+			values = logValues(values)
+		}
+		s.drawValues(c, values, y, exportLine, 2, false)
+	}
+	for _, line := range s.overlays {
+		s.drawValues(c, line.values, y, exportColor(line.color), 1.5, false)
+	}
+}
+
+// drawValues draws values as polylines broken at NaN gaps.
+func (s *exportScene) drawValues(c exportCanvas, values []float64, y func(float64) float64, stroke rgb, width float64, dashed bool) {
+	var run []exportPoint
+	for i, v := range values {
+		if math.IsNaN(v) {
+			if len(run) > 1 {
+				c.polyline(run, stroke, width, dashed)
+			}
+			run = nil
+			continue
+		}
+		run = append(run, exportPoint{s.xAt(i), y(v)})
+	}
+	if len(run) > 1 {
+		c.polyline(run, stroke, width, dashed)
+	}
+}
+
+func (s *exportScene) panelTitle(c exportCanvas, top float64, title string) {
+	c.text(s.plotLeft, top-10, title, exportMuted, anchorStart)
+}
+
+func (s *exportScene) volumePanel() ([]string, func(c exportCanvas, top, height float64)) {
+	volumes := make([]float64, len(s.points))
+	maxV := 0.0
+	for i, p := range s.points {
+		volumes[i] = volumeAt(s.cfg.Volume, p.Time)
+		maxV = math.Max(maxV, volumes[i])
+	}
+	symbol := s.cfg.CurrencySymbol
+	labels := []string{symbol + FormatCurrency(maxV), symbol + FormatCurrency(maxV/2), symbol + FormatCurrency(0)}
+
+	return labels, func(c exportCanvas, top, height float64) {
+		s.panelTitle(c, top, "Volume (24h)")
+		s.drawYAxis(c, top, height, labels, nil)
+		if maxV == 0 {
+			return
+		}
+		bar := math.Max(1, s.slotWidth()*0.7)
+		for i, v := range volumes {
+			fill := exportUp
+			if len(s.candles) > 0 && s.candles[i].Close < s.candles[i].Open {
+				fill = exportDown
+			} else if len(s.candles) == 0 && i > 0 && s.values[i] < s.values[i-1] {
+				fill = exportDown
+			}
+			barTop := valueToY(v, 0, maxV, top, height)
+			c.rect(s.xAt(i)-bar/2, barTop, bar, top+height-barTop, fill)
+		}
+	}
+}
+
+func (s *exportScene) rsiPanel(spec IndicatorSpec) ([]string, func(c exportCanvas, top, height float64)) {
+	labels := []string{"100", "50", "0"}
+	rsi := RSI(s.values, spec.Period)
+	return labels, func(c exportCanvas, top, height float64) {
+		s.panelTitle(c, top, spec.Label())
+		s.drawYAxis(c, top, height, labels, nil)
+		y := func(v float64) float64 { return valueToY(v, 0, 100, top, height) }
+		for _, level := range []float64{rsiOverbought, rsiOversold} {
+			c.polyline([]exportPoint{{s.plotLeft, y(level)}, {s.plotLeft + s.plotWidth, y(level)}}, exportAxis, 1, true)
+		}
+		s.drawValues(c, rsi, y, exportColor(color.FgHiCyan), 1.5, false)
+	}
+}
+
+func (s *exportScene) macdPanel(spec IndicatorSpec) ([]string, func(c exportCanvas, top, height float64)) {
+	macd, signal, hist := MACD(s.values, spec.Fast, spec.Slow, spec.Signal)
+	minV, maxV := 0.0, 0.0
+	minV, maxV = valuesMinMax(macd, minV, maxV)
+	minV, maxV = valuesMinMax(signal, minV, maxV)
+	minV, maxV = valuesMinMax(hist, minV, maxV)
+	minV, maxV = padPriceRange(minV, maxV)
+	labels := make([]string, 3)
+	for i := range labels {
+		labels[i] = formatSignedCompact(maxV - float64(i)/2*(maxV-minV))
+	}
+
+	return labels, func(c exportCanvas, top, height float64) {
+		s.panelTitle(c, top, spec.Label()+"  macd / signal / histogram")
+		s.drawYAxis(c, top, height, labels, nil)
+		y := func(v float64) float64 { return valueToY(v, minV, maxV, top, height) }
+		zero := y(0)
+		bar := math.Max(1, s.slotWidth()*0.6)
+		for i, h := range hist {
+			if math.IsNaN(h) {
+				continue
+			}
+			fill := exportUp
+			if h < 0 {
+				fill = exportDown
+			}
+			c.rect(s.xAt(i)-bar/2, math.Min(zero, y(h)), bar, math.Max(1, math.Abs(y(h)-zero)), fill)
+		}
+		s.drawValues(c, macd, y, exportColor(color.FgHiCyan), 1.5, false)
+		s.drawValues(c, signal, y, exportColor(color.FgHiYellow), 1.5, true)
+	}
+}
+
+func (s *exportScene) drawLegend(c exportCanvas, baseline float64) {
+	type item struct {
+		label string
+		color rgb
+	}
+	label := s.exp.Label
+	if label == "" {
+		label = "Price"
+	}
+	items := []item{{label, exportLine}}
+	if len(s.candles) > 0 {
+		items = []item{{"Bullish", exportUp}, {"Bearish", exportDown}}
+	}
+	for _, entry := range s.legend {
+		items = append(items, item{strings.TrimPrefix(entry.label, "· "), exportColor(entry.color)})
+	}
+
+	x := s.plotLeft
+	for _, it := range items {
+		c.rect(x, baseline-10, 18, 10, it.color)
+		c.text(x+26, baseline, it.label, exportText, anchorStart)
+		x += 26 + float64(len([]rune(it.label))*exportCharWidth) + 24
+	}
+}
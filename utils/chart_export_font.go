@@ -0,0 +1,127 @@
+package utils
+
+import "strings"
+
+const (
+	glyphWidth  = 5
+	glyphHeight = 7
+)
+
+// glyphRows is a 5x7 bitmap font for PNG export text, one row per field.
+// Runes without a glyph are drawn as '?'.
+var glyphRows = map[rune]string{
+	' ':  "..... ..... ..... ..... ..... ..... .....",
+	'0':  ".###. #...# #..## #.#.# ##..# #...# .###.",
+	'1':  "..#.. .##.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'2':  ".###. #...# ....# ...#. ..#.. .#... #####",
+	'3':  "##### ...#. ..#.. ...#. ....# #...# .###.",
+	'4':  "...#. ..##. .#.#. #..#. ##### ...#. ...#.",
+	'5':  "##### #.... ####. ....# ....# #...# .###.",
+	'6':  "..##. .#... #.... ####. #...# #...# .###.",
+	'7':  "##### ....# ...#. ..#.. .#... .#... .#...",
+	'8':  ".###. #...# #...# .###. #...# #...# .###.",
+	'9':  ".###. #...# #...# .#### ....# ...#. .##..",
+	'A':  ".###. #...# #...# ##### #...# #...# #...#",
+	'B':  "####. #...# #...# ####. #...# #...# ####.",
+	'C':  ".###. #...# #.... #.... #.... #...# .###.",
+	'D':  "###.. #..#. #...# #...# #...# #..#. ###..",
+	'E':  "##### #.... #.... ####. #.... #.... #####",
+	'F':  "##### #.... #.... ####. #.... #.... #....",
+	'G':  ".###. #...# #.... #.### #...# #...# .####",
+	'H':  "#...# #...# #...# ##### #...# #...# #...#",
+	'I':  ".###. ..#.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'J':  "..### ...#. ...#. ...#. ...#. #..#. .##..",
+	'K':  "#...# #..#. #.#.. ##... #.#.. #..#. #...#",
+	'L':  "#.... #.... #.... #.... #.... #.... #####",
+	'M':  "#...# ##.## #.#.# #.#.# #...# #...# #...#",
+	'N':  "#...# #...# ##..# #.#.# #..## #...# #...#",
+	'O':  ".###. #...# #...# #...# #...# #...# .###.",
+	'P':  "####. #...# #...# ####. #.... #.... #....",
+	'Q':  ".###. #...# #...# #...# #.#.# #..#. .##.#",
+	'R':  "####. #...# #...# ####. #.#.. #..#. #...#",
+	'S':  ".#### #.... #.... .###. ....# ....# ####.",
+	'T':  "##### ..#.. ..#.. ..#.. ..#.. ..#.. ..#..",
+	'U':  "#...# #...# #...# #...# #...# #...# .###.",
+	'V':  "#...# #...# #...# #...# #...# .#.#. ..#..",
+	'W':  "#...# #...# #...# #.#.# #.#.# #.#.# .#.#.",
+	'X':  "#...# #...# .#.#. ..#.. .#.#. #...# #...#",
+	'Y':  "#...# #...# .#.#. ..#.. ..#.. ..#.. ..#..",
+	'Z':  "##### ....# ...#. ..#.. .#... #.... #####",
+	'a':  "..... ..... .###. ....# .#### #...# .####",
+	'b':  "#.... #.... #.##. ##..# #...# #...# ####.",
+	'c':  "..... ..... .###. #.... #.... #...# .###.",
+	'd':  "....# ....# .##.# #..## #...# #...# .####",
+	'e':  "..... ..... .###. #...# ##### #.... .###.",
+	'f':  "..##. .#..# .#... ###.. .#... .#... .#...",
+	'g':  "..... .#### #...# #...# .#### ....# .###.",
+	'h':  "#.... #.... #.##. ##..# #...# #...# #...#",
+	'i':  "..#.. ..... .##.. ..#.. ..#.. ..#.. .###.",
+	'j':  "...#. ..... ..##. ...#. ...#. #..#. .##..",
+	'k':  "#.... #.... #..#. #.#.. ##... #.#.. #..#.",
+	'l':  ".##.. ..#.. ..#.. ..#.. ..#.. ..#.. .###.",
+	'm':  "..... ..... ##.#. #.#.# #.#.# #...# #...#",
+	'n':  "..... ..... #.##. ##..# #...# #...# #...#",
+	'o':  "..... ..... .###. #...# #...# #...# .###.",
+	'p':  "..... ..... ####. #...# ####. #.... #....",
+	'q':  "..... ..... .##.# #..## .#### ....# ....#",
+	'r':  "..... ..... #.##. ##..# #.... #.... #....",
+	's':  "..... ..... .###. #.... .###. ....# ####.",
+	't':  ".#... .#... ###.. .#... .#... .#..# ..##.",
+	'u':  "..... ..... #...# #...# #...# #..## .##.#",
+	'v':  "..... ..... #...# #...# #...# .#.#. ..#..",
+	'w':  "..... ..... #...# #...# #.#.# #.#.# .#.#.",
+	'x':  "..... ..... #...# .#.#. ..#.. .#.#. #...#",
+	'y':  "..... ..... #...# #...# .#### ....# .###.",
+	'z':  "..... ..... ##### ...#. ..#.. .#... #####",
+	'.':  "..... ..... ..... ..... ..... .##.. .##..",
+	',':  "..... ..... ..... ..... .##.. ..#.. .#...",
+	':':  "..... .##.. .##.. ..... .##.. .##.. .....",
+	'%':  "##... ##..# ...#. ..#.. .#... #..## ...##",
+	'+':  "..... ..#.. ..#.. ##### ..#.. ..#.. .....",
+	'-':  "..... ..... ..... ##### ..... ..... .....",
+	'(':  "...#. ..#.. .#... .#... .#... ..#.. ...#.",
+	')':  ".#... ..#.. ...#. ...#. ...#. ..#.. .#...",
+	'/':  "..... ....# ...#. ..#.. .#... #.... .....",
+	'$':  "..#.. .#### #.#.. .###. ..#.# ####. ..#..",
+	'|':  "..#.. ..#.. ..#.. ..#.. ..#.. ..#.. ..#..",
+	'=':  "..... ..... ##### ..... ##### ..... .....",
+	'_':  "..... ..... ..... ..... ..... ..... #####",
+	'\'': "..#.. ..#.. .#... ..... ..... ..... .....",
+	'?':  ".###. #...# ....# ...#. ..#.. ..... ..#..",
+	'!':  "..#.. ..#.. ..#.. ..#.. ..#.. ..... ..#..",
+	'<':  "...#. ..#.. .#... #.... .#... ..#.. ...#.",
+	'>':  ".#... ..#.. ...#. ....# ...#. ..#.. .#...",
+	'#':  ".#.#. .#.#. ##### .#.#. ##### .#.#. .#.#.",
+	'*':  "..... ..#.. #.#.# .###. #.#.# ..#.. .....",
+	'Δ':  "..#.. ..#.. .#.#. .#.#. #...# #...# #####",
+	'·':  "..... ..... ..... .##.. .##.. ..... .....",
+}
+
+// glyphs holds glyphRows decoded to one bit mask per row, high bit leftmost.
+var glyphs = decodeGlyphs(glyphRows)
+
+func decodeGlyphs(rows map[rune]string) map[rune][glyphHeight]uint8 {
+	decoded := make(map[rune][glyphHeight]uint8, len(rows))
+	for r, spec := range rows {
+		var glyph [glyphHeight]uint8
+		for y, row := range strings.Fields(spec) {
+			if y >= glyphHeight {
+				break
+			}
+			for x, cell := range row {
+				if cell == '#' && x < glyphWidth {
+					glyph[y] |= 1 << uint(glyphWidth-1-x)
+				}
+			}
+		}
+		decoded[r] = glyph
+	}
+	return decoded
+}
+
+func lookupGlyph(r rune) [glyphHeight]uint8 {
+	if glyph, ok := glyphs[r]; ok {
+		return glyph
+	}
+	return glyphs['?']
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	imagecolor "image/color"
+	"image/png"
+	"math"
+)
+
+// pngTextScale enlarges the 5x7 glyphs so labels stay readable.
+const pngTextScale = 2
+
+// pngCanvas rasterizes export drawing calls without anti-aliasing.
+type pngCanvas struct {
+	img *image.RGBA
+}
+
+func newPNGCanvas(width, height int) *pngCanvas {
+	c := &pngCanvas{img: image.NewRGBA(image.Rect(0, 0, width, height))}
+	c.rect(0, 0, float64(width), float64(height), exportBackground)
+	return c
+}
+
+func (c *pngCanvas) fill(x0, y0, x1, y1 int, fill rgb) {
+	bounds := c.img.Bounds()
+	x0, y0 = intMax(x0, bounds.Min.X), intMax(y0, bounds.Min.Y)
+	x1, y1 = intMin(x1, bounds.Max.X), intMin(y1, bounds.Max.Y)
+	px := imagecolor.RGBA{R: fill.r, G: fill.g, B: fill.b, A: 0xff}
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			c.img.SetRGBA(x, y, px)
+		}
+	}
+}
+
+func (c *pngCanvas) rect(x, y, w, h float64, fill rgb) {
+	x0, y0 := int(math.Round(x)), int(math.Round(y))
+	x1 := intMax(x0+1, int(math.Round(x+w)))
+	y1 := intMax(y0+1, int(math.Round(y+h)))
+	c.fill(x0, y0, x1, y1, fill)
+}
+
+// polyline stamps a square brush of the stroke width along each segment.
+// Dashes alternate every 6 pixels of path length.
+func (c *pngCanvas) polyline(points []exportPoint, stroke rgb, width float64, dashed bool) {
+	brush := intMax(1, int(math.Round(width)))
+	offset := brush / 2
+	travelled := 0.0
+	for i := 1; i < len(points); i++ {
+		a, b := points[i-1], points[i]
+		length := math.Hypot(b.x-a.x, b.y-a.y)
+		steps := intMax(1, int(math.Ceil(length)))
+		for step := 0; step <= steps; step++ {
+			t := float64(step) / float64(steps)
+			if dashed && int((travelled+t*length)/6)%2 == 1 {
+				continue
+			}
+			x := int(math.Round(a.x+(b.x-a.x)*t)) - offset
+			y := int(math.Round(a.y+(b.y-a.y)*t)) - offset
+			c.fill(x, y, x+brush, y+brush, stroke)
+		}
+		travelled += length
+	}
+}
+
+func (c *pngCanvas) text(x, y float64, s string, fill rgb, anchor textAnchor) {
+	runes := []rune(s)
+	advance := (glyphWidth + 1) * pngTextScale
+	width := len(runes) * advance
+	left := int(math.Round(x))
+	switch anchor {
+	case anchorMiddle:
+		left -= width / 2
+	case anchorEnd:
+		left -= width
+	}
+	top := int(math.Round(y)) - glyphHeight*pngTextScale
+	for i, r := range runes {
+		glyph := lookupGlyph(r)
+		for row := 0; row < glyphHeight; row++ {
+			for col := 0; col < glyphWidth; col++ {
+				if glyph[row]&(1<<uint(glyphWidth-1-col)) == 0 {
+					continue
+				}
+				px := left + i*advance + col*pngTextScale
+				py := top + row*pngTextScale
+				c.fill(px, py, px+pngTextScale, py+pngTextScale, fill)
+			}
+		}
+	}
+}
+
+func (c *pngCanvas) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, c.img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
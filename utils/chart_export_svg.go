@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+var svgEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;")
+
+// svgCanvas writes export drawing calls as SVG elements.
+type svgCanvas struct {
+	sb strings.Builder
+}
+
+func newSVGCanvas(width, height int) *svgCanvas {
+	c := &svgCanvas{}
+	fmt.Fprintf(&c.sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="%d">`+"\n",
+		width, height, width, height, exportFontSize)
+	c.rect(0, 0, float64(width), float64(height), exportBackground)
+	return c
+}
+
+func (c *svgCanvas) rect(x, y, w, h float64, fill rgb) {
+	fmt.Fprintf(&c.sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", x, y, w, h, fill.hex())
+}
+
+func (c *svgCanvas) polyline(points []exportPoint, stroke rgb, width float64, dashed bool) {
+	coords := make([]string, len(points))
+	for i, p := range points {
+		coords[i] = fmt.Sprintf("%.1f,%.1f", p.x, p.y)
+	}
+	dash := ""
+	if dashed {
+		dash = ` stroke-dasharray="6 4"`
+	}
+	fmt.Fprintf(&c.sb, `<polyline points="%s" fill="none" stroke="%s" stroke-width="%.1f" stroke-linejoin="round"%s/>`+"\n",
+		strings.Join(coords, " "), stroke.hex(), width, dash)
+}
+
+func (c *svgCanvas) text(x, y float64, s string, fill rgb, anchor textAnchor) {
+	anchorName := "start"
+	switch anchor {
+	case anchorMiddle:
+		anchorName = "middle"
+	case anchorEnd:
+		anchorName = "end"
+	}
+	fmt.Fprintf(&c.sb, `<text x="%.1f" y="%.1f" fill="%s" text-anchor="%s">%s</text>`+"\n",
+		x, y, fill.hex(), anchorName, svgEscaper.Replace(s))
+}
+
+func (c *svgCanvas) bytes() []byte {
+	return []byte(c.sb.String() + "</svg>\n")
+}
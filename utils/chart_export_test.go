@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+func exportTestPoints() []SeriesPoint {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 40)
+	for i := range points {
+		points[i] = SeriesPoint{Time: base.Add(time.Duration(i) * 24 * time.Hour), Value: 100 + float64(i%10)}
+	}
+	return points
+}
+
+func TestExportFormat(t *testing.T) {
+	if f, err := ExportFormat("chart.SVG"); err != nil || f != ExportFormatSVG {
+		t.Fatalf("ExportFormat(svg) = (%q, %v)", f, err)
+	}
+	if f, err := ExportFormat("out/chart.png"); err != nil || f != ExportFormatPNG {
+		t.Fatalf("ExportFormat(png) = (%q, %v)", f, err)
+	}
+	if _, err := ExportFormat("chart.jpg"); err == nil {
+		t.Fatal("expected error for .jpg")
+	}
+}
+
+func TestRenderChartSVGIncludesStatsAndPanels(t *testing.T) {
+	specs, _ := ParseIndicators("sma:5,rsi:14")
+	svg := string(RenderChartSVG(ChartExport{
+		Title:  "BTC <Line> Chart",
+		Points: exportTestPoints(),
+		Config: ChartConfig{CurrencySymbol: "$", Indicators: specs},
+	}))
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("not an SVG document:\n%s", svg)
+	}
+	for _, want := range []string{"BTC &lt;Line&gt; Chart", "O: $100.00", "RSI(14)", "SMA(5)", "<polyline", "stroke-dasharray"} {
+		if !strings.Contains(svg, want) {
+			t.Fatalf("SVG missing %q", want)
+		}
+	}
+}
+
+func TestRenderChartPNGDrawsCandles(t *testing.T) {
+	candles := make([]models.OHLC, 20)
+	for i := range candles {
+		v := 100 + float64(i)
+		candles[i] = models.OHLC{Time: int64(i) * 86400000, Open: v, High: v + 3, Low: v - 3, Close: v + 2}
+	}
+	data, err := RenderChartPNG(ChartExport{Title: "BTC", Candles: candles, Config: ChartConfig{CurrencySymbol: "$"}})
+	if err != nil {
+		t.Fatalf("RenderChartPNG() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode PNG: %v", err)
+	}
+	if img.Bounds().Dx() != exportWidth {
+		t.Fatalf("width = %d, want %d", img.Bounds().Dx(), exportWidth)
+	}
+
+	green := 0
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			if uint8(r>>8) == exportUp.r && uint8(g>>8) == exportUp.g && uint8(b>>8) == exportUp.b {
+				green++
+			}
+		}
+	}
+	if green < 100 {
+		t.Fatalf("expected bullish candle bodies, found %d green pixels", green)
+	}
+}
+
+func TestExportChartWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart.svg")
+	if err := ExportChart(path, ChartExport{Title: "BTC", Points: exportTestPoints()}); err != nil {
+		t.Fatalf("ExportChart() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || !bytes.Contains(data, []byte("<svg")) {
+		t.Fatalf("unexpected export file: %v", err)
+	}
+}
+
+func TestGlyphRowsAreWellFormed(t *testing.T) {
+	for r, spec := range glyphRows {
+		rows := strings.Fields(spec)
+		if len(rows) != glyphHeight {
+			t.Fatalf("glyph %q has %d rows", r, len(rows))
+		}
+		for _, row := range rows {
+			if len(row) != glyphWidth {
+				t.Fatalf("glyph %q row %q is not %d wide", r, row, glyphWidth)
+			}
+		}
+	}
+}
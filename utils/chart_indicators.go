@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/fatih/color"
+)
+
+const (
+	rsiOverbought = 70
+	rsiOversold   = 30
+)
+
+// indicatorPalette colors price overlays in the order they are requested.
+var indicatorPalette = []color.Attribute{
+	color.FgHiYellow,
+	color.FgHiMagenta,
+	color.FgHiBlue,
+	color.FgHiGreen,
+	color.FgHiRed,
+}
+
+// overlayLine is an indicator series drawn on the price panel.
+type overlayLine struct {
+	values []float64
+	color  color.Attribute
+}
+
+// computeOverlays evaluates SMA/EMA/Bollinger specs over values and returns
+// the lines to draw plus one legend entry per spec.
+func computeOverlays(values []float64, specs []IndicatorSpec) ([]overlayLine, []legendEntry) {
+	var lines []overlayLine
+	var legend []legendEntry
+	for _, spec := range specs {
+		if !spec.IsOverlay() {
+			continue
+		}
+		attr := indicatorPalette[len(legend)%len(indicatorPalette)]
+		switch spec.Kind {
+		case IndicatorSMA:
+			lines = append(lines, overlayLine{values: SMA(values, spec.Period), color: attr})
+		case IndicatorEMA:
+			lines = append(lines, overlayLine{values: EMA(values, spec.Period), color: attr})
+		case IndicatorBB:
+			mid, upper, lower := BollingerBands(values, spec.Period, bollingerStdDevs)
+			lines = append(lines,
+				overlayLine{values: upper, color: attr},
+				overlayLine{values: mid, color: attr},
+				overlayLine{values: lower, color: attr},
+			)
+		}
+		legend = append(legend, legendEntry{label: "· " + spec.Label(), color: attr})
+	}
+	return lines, legend
+}
+
+// sampleOverlays picks overlay values at the plotted point indices.
+func sampleOverlays(lines []overlayLine, indices []int) []overlayLine {
+	sampled := make([]overlayLine, len(lines))
+	for i, line := range lines {
+		sampled[i] = overlayLine{values: sampleValues(line.values, indices), color: line.color}
+	}
+	return sampled
+}
+
+func sampleValues(values []float64, indices []int) []float64 {
+	sampled := make([]float64, len(indices))
+	for i, idx := range indices {
+		sampled[i] = values[idx]
+	}
+	return sampled
+}
+
+// overlayMinMax widens [minP, maxP] so every defined overlay value fits.
+func overlayMinMax(lines []overlayLine, minP, maxP float64) (float64, float64) {
+	for _, line := range lines {
+		minP, maxP = valuesMinMax(line.values, minP, maxP)
+	}
+	return minP, maxP
+}
+
+func valuesMinMax(values []float64, minV, maxV float64) (float64, float64) {
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		minV = math.Min(minV, v)
+		maxV = math.Max(maxV, v)
+	}
+	return minV, maxV
+}
+
+func drawOverlays(plot [][]rune, colors [][]color.Attribute, lines []overlayLine, column func(int) int, minP, maxP float64) {
+	for _, line := range lines {
+		drawValueLine(plot, colors, line.color, line.values, column, minP, maxP, '·', '·', '·')
+	}
+}
+
+// drawValueLine connects consecutive defined values; NaN values break the line.
+func drawValueLine(plot [][]rune, colors [][]color.Attribute, attr color.Attribute, values []float64, column func(int) int, minV, maxV float64, up, down, flat rune) {
+	height := len(plot)
+	width := len(plot[0])
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		x := column(i)
+		y := priceToRow(v, minV, maxV, height)
+		if i > 0 && !math.IsNaN(values[i-1]) {
+			drawLine(plot, colors, attr, column(i-1), priceToRow(values[i-1], minV, maxV, height), x, y, up, down, flat)
+		} else if x < width && plot[y][x] == ' ' {
+			plot[y][x] = flat
+			colors[y][x] = attr
+		}
+	}
+}
+
+// indicatorPanels builds RSI and MACD sub-panels from the full value series,
+// sampled at indices so they share the price panel's columns.
+func indicatorPanels(values []float64, indices []int, cfg ChartConfig, column func(int) int) []chartPanel {
+	var panels []chartPanel
+	for _, spec := range cfg.Indicators {
+		switch spec.Kind {
+		case IndicatorRSI:
+			panels = append(panels, rsiPanel(sampleValues(RSI(values, spec.Period), indices), spec, cfg, column))
+		case IndicatorMACD:
+			macd, signal, hist := MACD(values, spec.Fast, spec.Slow, spec.Signal)
+			panels = append(panels, macdPanel(
+				sampleValues(macd, indices),
+				sampleValues(signal, indices),
+				sampleValues(hist, indices),
+				spec, cfg, column,
+			))
+		}
+	}
+	return panels
+}
+
+func subPanelHeight(cfg ChartConfig) int {
+	return intMax(5, cfg.Height/3)
+}
+
+func rsiPanel(rsi []float64, spec IndicatorSpec, cfg ChartConfig, column func(int) int) chartPanel {
+	height := subPanelHeight(cfg)
+	plot := newPlotGrid(cfg.Width, height)
+	colors := newColorGrid(cfg.Width, height)
+
+	drawValueLine(plot, colors, color.FgHiCyan, rsi, column, 0, 100, '╱', '╲', '─')
+	for _, level := range []float64{rsiOverbought, rsiOversold} {
+		row := priceToRow(level, 0, 100, height)
+		for x := range plot[row] {
+			if plot[row][x] == ' ' {
+				plot[row][x] = '┄'
+				colors[row][x] = color.FgHiBlack
+			}
+		}
+	}
+
+	return chartPanel{
+		legend:  []legendEntry{{label: spec.Label(), color: color.FgHiCyan}, {label: "┄ 70/30", color: color.FgHiBlack}},
+		plot:    plot,
+		colors:  colors,
+		yLabels: []string{"100", "50", "0"},
+	}
+}
+
+func macdPanel(macd, signal, hist []float64, spec IndicatorSpec, cfg ChartConfig, column func(int) int) chartPanel {
+	height := subPanelHeight(cfg)
+	plot := newPlotGrid(cfg.Width, height)
+	colors := newColorGrid(cfg.Width, height)
+
+	minV, maxV := 0.0, 0.0
+	minV, maxV = valuesMinMax(macd, minV, maxV)
+	minV, maxV = valuesMinMax(signal, minV, maxV)
+	minV, maxV = valuesMinMax(hist, minV, maxV)
+	minV, maxV = padPriceRange(minV, maxV)
+
+	drawValueLine(plot, colors, color.FgHiCyan, macd, column, minV, maxV, '╱', '╲', '─')
+	drawValueLine(plot, colors, color.FgHiYellow, signal, column, minV, maxV, '·', '·', '·')
+
+	zeroRow := priceToRow(0, minV, maxV, height)
+	for i, h := range hist {
+		if math.IsNaN(h) {
+			continue
+		}
+		x := column(i)
+		if x >= cfg.Width {
+			continue
+		}
+		attr := color.FgGreen
+		if h < 0 {
+			attr = color.FgRed
+		}
+		row := priceToRow(h, minV, maxV, height)
+		for y := intMin(row, zeroRow); y <= intMax(row, zeroRow); y++ {
+			if plot[y][x] == ' ' {
+				plot[y][x] = '│'
+				colors[y][x] = attr
+			}
+		}
+	}
+
+	yLabels := make([]string, 3)
+	for i := range yLabels {
+		v := maxV - float64(i)/2*(maxV-minV)
+		yLabels[i] = formatSignedCompact(v)
+	}
+
+	return chartPanel{
+		legend: []legendEntry{
+			{label: spec.Label(), color: color.FgHiCyan},
+			{label: "· signal", color: color.FgHiYellow},
+			{label: "│ histogram", color: color.FgGreen},
+		},
+		plot:    plot,
+		colors:  colors,
+		yLabels: yLabels,
+	}
+}
+
+func formatSignedCompact(v float64) string {
+	if v < 0 {
+		return "-" + FormatCurrency(-v)
+	}
+	return FormatCurrency(v)
+}
+
+// FormatIndicatorSummary returns the latest defined value of each sub-panel
+// indicator, e.g. "RSI(14): 63.20  MACD(12,26,9): 120.50 / 98.10".
+func FormatIndicatorSummary(points []SeriesPoint, specs []IndicatorSpec) string {
+	values := seriesValues(points)
+	summary := ""
+	for _, spec := range specs {
+		var text string
+		switch spec.Kind {
+		case IndicatorRSI:
+			text = fmt.Sprintf("%s: %s", spec.Label(), formatIndicatorValue(lastDefined(RSI(values, spec.Period))))
+		case IndicatorMACD:
+			macd, signal, _ := MACD(values, spec.Fast, spec.Slow, spec.Signal)
+			text = fmt.Sprintf("%s: %s / %s", spec.Label(),
+				formatIndicatorValue(lastDefined(macd)), formatIndicatorValue(lastDefined(signal)))
+		default:
+			continue
+		}
+		if summary != "" {
+			summary += "  "
+		}
+		summary += text
+	}
+	return summary
+}
+
+func formatIndicatorValue(v float64) string {
+	if math.IsNaN(v) {
+		return "n/a"
+	}
+	return formatSignedCompact(v)
+}
+
+func lastDefined(values []float64) float64 {
+	for i := len(values) - 1; i >= 0; i-- {
+		if !math.IsNaN(values[i]) {
+			return values[i]
+		}
+	}
+	return math.NaN()
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"math"
+	"sort"
+
+	"github.com/mrcnserkan/crypto/models"
+)
+
+// logTickMantissas lists "nice" log-axis tick mantissas in order of preference.
+var logTickMantissas = []float64{1, 5, 2}
+
+// canUseLogScale reports whether every value is positive so it can be
+// plotted on a log axis.
+func canUseLogScale(values []float64) bool {
+	for _, v := range values {
+		if !math.IsNaN(v) && v <= 0 {
+			return false
+		}
+	}
+	return len(values) > 0
+}
+
+// ohlcLows returns candle lows; a log axis needs them all positive.
+func ohlcLows(data []models.OHLC) []float64 {
+	lows := make([]float64, len(data))
+	for i, c := range data {
+		lows[i] = c.Low
+	}
+	return lows
+}
+
+func logValues(values []float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) || v <= 0 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = math.Log10(v)
+	}
+	return out
+}
+
+func logSeries(points []SeriesPoint) []SeriesPoint {
+	out := make([]SeriesPoint, len(points))
+	for i, p := range points {
+		out[i] = SeriesPoint{Time: p.Time, Value: math.Log10(p.Value)}
+	}
+	return out
+}
+
+func logOverlays(lines []overlayLine) []overlayLine {
+	out := make([]overlayLine, len(lines))
+	for i, line := range lines {
+		out[i] = overlayLine{values: logValues(line.values), color: line.color}
+	}
+	return out
+}
+
+func logOHLC(data []models.OHLC) []models.OHLC {
+	out := make([]models.OHLC, len(data))
+	for i, c := range data {
+		out[i] = models.OHLC{
+			Time:  c.Time,
+			Open:  math.Log10(c.Open),
+			High:  math.Log10(c.High),
+			Low:   math.Log10(c.Low),
+			Close: math.Log10(c.Close),
+		}
+	}
+	return out
+}
+
+// buildLogTickLabels picks tick values at 1, 2 and 5 times powers of ten
+// between 10^minLog and 10^maxLog, preferring powers of ten and keeping
+// labels apart vertically. It returns labels with their plot rows. When the
+// range holds fewer than two nice values, ticks are spaced geometrically.
+func buildLogTickLabels(minLog, maxLog float64, height, tickCount int, symbol string) ([]string, []int) {
+	minGap := intMax(2, height/(tickCount+1))
+
+	type tick struct {
+		value float64
+		row   int
+	}
+	var ticks []tick
+	for _, mantissa := range logTickMantissas {
+		for exp := math.Floor(minLog); exp <= math.Ceil(maxLog); exp++ {
+			value := mantissa * math.Pow(10, exp)
+			logValue := math.Log10(value)
+			if logValue < minLog || logValue > maxLog {
+				continue
+			}
+			row := priceToRow(logValue, minLog, maxLog, height)
+			crowded := false
+			for _, t := range ticks {
+				if intAbs(t.row-row) < minGap {
+					crowded = true
+					break
+				}
+			}
+			if !crowded {
+				ticks = append(ticks, tick{value: value, row: row})
+			}
+		}
+	}
+
+	if len(ticks) < 2 {
+		ticks = ticks[:0]
+		for i := 0; i < tickCount; i++ {
+			ratio := float64(i) / float64(tickCount-1)
+			logValue := maxLog - ratio*(maxLog-minLog)
+			ticks = append(ticks, tick{
+				value: math.Pow(10, logValue),
+				row:   priceToRow(logValue, minLog, maxLog, height),
+			})
+		}
+	}
+
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i].row < ticks[j].row })
+	labels := make([]string, len(ticks))
+	rows := make([]int, len(ticks))
+	for i, t := range ticks {
+		labels[i] = symbol + FormatCurrency(t.value)
+		rows[i] = t.row
+	}
+	return labels, rows
+}
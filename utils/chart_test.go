@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -132,3 +133,239 @@ func TestDownsampleLTTBPreservesEndpoints(t *testing.T) {
 		t.Fatalf("LTTB should preserve first and last values, got %.0f and %.0f", out[0].Value, out[len(out)-1].Value)
 	}
 }
+
+func TestNormalizeSeries(t *testing.T) {
+	out := NormalizeSeries([]SeriesPoint{{Value: 200}, {Value: 220}, {Value: 150}})
+	if out[0].Value != 0 || out[1].Value != 10 || out[2].Value != -25 {
+		t.Fatalf("NormalizeSeries() = %+v", out)
+	}
+}
+
+func TestRenderMultiLineChartLegendAndPercentAxis(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	btc := make([]SeriesPoint, 10)
+	eth := make([]SeriesPoint, 10)
+	for i := range btc {
+		ts := base.Add(time.Duration(i) * 24 * time.Hour)
+		btc[i] = SeriesPoint{Time: ts, Value: 60000 + float64(i)*600}
+		eth[i] = SeriesPoint{Time: ts, Value: 3000 - float64(i)*30}
+	}
+	out := RenderMultiLineChart([]ChartSeries{
+		{Name: "BITCOIN", Points: btc},
+		{Name: "ETHEREUM", Points: eth},
+	}, ChartConfig{Width: 40, Height: 10, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3, Normalize: true})
+
+	if !strings.Contains(out, "BITCOIN +9.00%") || !strings.Contains(out, "ETHEREUM -9.00%") {
+		t.Fatalf("legend missing normalized series values:\n%s", out)
+	}
+	if strings.Contains(out, "$") {
+		t.Fatalf("normalized chart should use percent ticks:\n%s", out)
+	}
+	if !strings.Contains(out, "┤") || !strings.Contains(out, "└") {
+		t.Fatal("multi-series chart missing axes")
+	}
+}
+
+func TestPairsToSeriesSkipsMalformedPairs(t *testing.T) {
+	series := PairsToSeries([][]float64{{1700000000000, 10}, {1700000000000}, {1700003600000, 12}})
+	if len(series) != 2 || series[1].Value != 12 {
+		t.Fatalf("PairsToSeries() = %+v", series)
+	}
+	if series[0].Time.Unix() != 1700000000 {
+		t.Fatalf("unexpected timestamp: %v", series[0].Time)
+	}
+}
+
+func TestRenderLineChartVolumePanel(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 10)
+	volume := make([]SeriesPoint, 10)
+	for i := range points {
+		ts := base.Add(time.Duration(i) * 24 * time.Hour)
+		points[i] = SeriesPoint{Time: ts, Value: 100 + float64(i)}
+		volume[i] = SeriesPoint{Time: ts, Value: 1_000_000 * float64(i+1)}
+	}
+	out := RenderLineChart(points, ChartConfig{Width: 40, Height: 10, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3, Volume: volume})
+	if !strings.Contains(out, "Volume (24h)") || !strings.Contains(out, "$10.00M") {
+		t.Fatalf("missing volume panel:\n%s", out)
+	}
+	if !strings.Contains(out, "█") {
+		t.Fatalf("volume panel has no bars:\n%s", out)
+	}
+}
+
+func TestVolumeAtUsesLatestSampleAtOrBefore(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	volume := []SeriesPoint{
+		{Time: base, Value: 1},
+		{Time: base.Add(time.Hour), Value: 2},
+	}
+	if v := volumeAt(volume, base.Add(30*time.Minute)); v != 1 {
+		t.Fatalf("volumeAt(mid) = %v, want 1", v)
+	}
+	if v := volumeAt(volume, base.Add(-time.Hour)); v != 1 {
+		t.Fatalf("volumeAt(before) = %v, want 1", v)
+	}
+	if v := volumeAt(volume, base.Add(2*time.Hour)); v != 2 {
+		t.Fatalf("volumeAt(after) = %v, want 2", v)
+	}
+}
+
+func TestParseChartStyle(t *testing.T) {
+	if style, err := ParseChartStyle(""); err != nil || style != ChartStyleASCII {
+		t.Fatalf("ParseChartStyle(\"\") = (%q, %v)", style, err)
+	}
+	if style, err := ParseChartStyle("Braille"); err != nil || style != ChartStyleBraille {
+		t.Fatalf("ParseChartStyle(Braille) = (%q, %v)", style, err)
+	}
+	if _, err := ParseChartStyle("sixel"); err == nil {
+		t.Fatal("expected error for unknown style")
+	}
+}
+
+func TestRenderLineChartBrailleStyle(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 200)
+	for i := range points {
+		points[i] = SeriesPoint{Time: base.Add(time.Duration(i) * time.Hour), Value: 100 + float64(i%40)}
+	}
+	out := RenderLineChart(points, ChartConfig{Width: 40, Height: 10, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3, Style: ChartStyleBraille})
+	if strings.Contains(out, "●") {
+		t.Fatalf("braille chart should not draw ASCII markers:\n%s", out)
+	}
+	hasBraille := false
+	for _, r := range out {
+		if r > 0x2800 && r <= 0x28FF {
+			hasBraille = true
+			break
+		}
+	}
+	if !hasBraille {
+		t.Fatalf("expected braille runes in output:\n%s", out)
+	}
+	if !strings.Contains(out, "┤") || !strings.Contains(out, "└") {
+		t.Fatal("braille chart missing axes")
+	}
+}
+
+func TestDotCanvasHalfBlockRunes(t *testing.T) {
+	canvas := newDotCanvas(ChartStyleBlock, 3, 1)
+	canvas.set(0, 0, 0)
+	canvas.set(1, 1, 0)
+	canvas.set(2, 0, 0)
+	canvas.set(2, 1, 0)
+	plot, _ := canvas.render()
+	if got := string(plot[0]); got != "▀▄█" {
+		t.Fatalf("half-block row = %q, want %q", got, "▀▄█")
+	}
+}
+
+func TestBuildLogTickLabelsPicksRoundPrices(t *testing.T) {
+	labels, rows := buildLogTickLabels(0, 5, 20, 5, "$")
+	if len(labels) < 2 || len(labels) != len(rows) {
+		t.Fatalf("labels = %v, rows = %v", labels, rows)
+	}
+	if labels[0] != "$100.00K" || labels[len(labels)-1] != "$1.00" {
+		t.Fatalf("labels = %v, want $100.00K at top and $1.00 at bottom", labels)
+	}
+	for i := 1; i < len(rows); i++ {
+		if rows[i] <= rows[i-1] {
+			t.Fatalf("rows not increasing: %v", rows)
+		}
+	}
+}
+
+func TestRenderLineChartLogScale(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 40)
+	for i := range points {
+		points[i] = SeriesPoint{Time: base.Add(time.Duration(i) * 24 * time.Hour), Value: math.Pow(10, float64(i)/10)}
+	}
+	cfg := ChartConfig{Width: 40, Height: 12, CurrencySymbol: "$", YTickCount: 4, XTickCount: 3, LogScale: true}
+	out := RenderLineChart(points, cfg)
+	if !strings.Contains(out, "$1.00K") || !strings.Contains(out, "$10.00 ┤") {
+		t.Fatalf("expected decade ticks on log axis:\n%s", out)
+	}
+
+	// Exponential growth is a straight line on a log axis, so no row may be
+	// skipped between the first and last point
+	lines := strings.Split(out, "\n")[:cfg.Height]
+	for i, line := range lines {
+		if !strings.ContainsAny(line, "●╱") {
+			t.Fatalf("row %d is empty on log axis:\n%s", i, out)
+		}
+	}
+}
+
+func TestRenderLineChartLogScaleFallsBackForNonPositive(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := []SeriesPoint{{Time: base, Value: -5}, {Time: base.Add(time.Hour), Value: 5}}
+	cfg := ChartConfig{Width: 40, Height: 10, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3}
+	linear := RenderLineChart(points, cfg)
+	cfg.LogScale = true
+	if got := RenderLineChart(points, cfg); got != linear {
+		t.Fatalf("log scale with negative values should render linearly:\n%s", got)
+	}
+}
+
+func TestRenderCandleChartLogScale(t *testing.T) {
+	data := make([]models.OHLC, 8)
+	for i := range data {
+		price := math.Pow(10, float64(i)/2)
+		data[i] = models.OHLC{Time: int64(i) * 86400000, Open: price, High: price * 1.1, Low: price * 0.9, Close: price * 1.05}
+	}
+	out := RenderCandleChart(data, ChartConfig{Width: 40, Height: 12, CurrencySymbol: "$", YTickCount: 4, XTickCount: 3, LogScale: true})
+	if !strings.Contains(out, "$100.00 ┤") || !strings.Contains(out, "█") {
+		t.Fatalf("expected log ticks and candles:\n%s", out)
+	}
+}
+
+func TestNearestPointIndexAndCandleIndexAt(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := []SeriesPoint{{Time: base}, {Time: base.Add(time.Hour)}, {Time: base.Add(2 * time.Hour)}}
+	cases := map[time.Duration]int{-time.Hour: 0, 20 * time.Minute: 0, 40 * time.Minute: 1, 5 * time.Hour: 2}
+	for offset, want := range cases {
+		if got := NearestPointIndex(points, base.Add(offset)); got != want {
+			t.Fatalf("NearestPointIndex(+%s) = %d, want %d", offset, got, want)
+		}
+	}
+	if NearestPointIndex(nil, base) != -1 || CandleIndexAt(nil, base) != -1 {
+		t.Fatal("empty input should give -1")
+	}
+
+	candles := CandlesFromSeries(points, time.Hour)
+	if got := CandleIndexAt(candles, base.Add(90*time.Minute)); got != 1 {
+		t.Fatalf("CandleIndexAt = %d, want 1", got)
+	}
+	if got := CandleIndexAt(candles, base.Add(-time.Hour)); got != 0 {
+		t.Fatalf("CandleIndexAt before data = %d, want 0", got)
+	}
+}
+
+func TestRenderChartsDrawCrosshair(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 20)
+	for i := range points {
+		points[i] = SeriesPoint{Time: base.Add(time.Duration(i) * time.Hour), Value: 100 + float64(i)}
+	}
+	cfg := ChartConfig{Width: 40, Height: 10, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3}
+	if out := RenderLineChart(points, cfg); strings.ContainsRune(out, crosshairRune) {
+		t.Fatalf("crosshair drawn without being set:\n%s", out)
+	}
+
+	cfg.Crosshair = points[0].Time
+	out := RenderLineChart(points, cfg)
+	// The first point is in the bottom-left corner, so the guide fills the
+	// rest of the first plot column
+	rows := strings.Split(out, "\n")[:cfg.Height-1]
+	for _, row := range rows {
+		if !strings.Contains(row, "┤"+string(crosshairRune)) && !strings.Contains(row, "│"+string(crosshairRune)) {
+			t.Fatalf("missing crosshair in row %q:\n%s", row, out)
+		}
+	}
+
+	out = RenderCandleChart(CandlesFromSeries(points, 4*time.Hour), cfg)
+	if !strings.ContainsRune(out, crosshairRune) {
+		t.Fatalf("candle chart missing crosshair:\n%s", out)
+	}
+}
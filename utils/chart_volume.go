@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"sort"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// volumePanel draws cfg.Volume as a histogram under the plotted points.
+// Bars are green when the point rose (or the candle closed bullish) and red otherwise.
+func volumePanel(points []SeriesPoint, rising []bool, cfg ChartConfig, column func(int) int) chartPanel {
+	height := subPanelHeight(cfg)
+	plot := newPlotGrid(cfg.Width, height)
+	colors := newColorGrid(cfg.Width, height)
+
+	volumes := make([]float64, len(points))
+	maxV := 0.0
+	for i, p := range points {
+		volumes[i] = volumeAt(cfg.Volume, p.Time)
+		if volumes[i] > maxV {
+			maxV = volumes[i]
+		}
+	}
+
+	for i, v := range volumes {
+		x := column(i)
+		if x >= cfg.Width || v <= 0 || maxV == 0 {
+			continue
+		}
+		attr := color.FgGreen
+		if !rising[i] {
+			attr = color.FgRed
+		}
+		top := priceToRow(v, 0, maxV, height)
+		for y := top; y < height; y++ {
+			plot[y][x] = '█'
+			colors[y][x] = attr
+		}
+	}
+
+	return chartPanel{
+		legend: []legendEntry{{label: "Volume (24h)", color: color.FgHiBlue}},
+		plot:   plot,
+		colors: colors,
+		yLabels: []string{
+			cfg.CurrencySymbol + FormatCurrency(maxV),
+			cfg.CurrencySymbol + FormatCurrency(maxV/2),
+			cfg.CurrencySymbol + FormatCurrency(0),
+		},
+	}
+}
+
+// volumeAt returns the latest volume sample at or before t, or the first
+// sample when t precedes the series. Volume must be sorted by time.
+func volumeAt(volume []SeriesPoint, t time.Time) float64 {
+	if len(volume) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(volume), func(i int) bool { return volume[i].Time.After(t) })
+	if idx == 0 {
+		return volume[0].Value
+	}
+	return volume[idx-1].Value
+}
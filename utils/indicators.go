@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Supported technical indicator kinds.
+const (
+	IndicatorSMA  = "sma"
+	IndicatorEMA  = "ema"
+	IndicatorBB   = "bb"
+	IndicatorRSI  = "rsi"
+	IndicatorMACD = "macd"
+)
+
+const (
+	defaultIndicatorPeriod = 20
+	defaultRSIPeriod       = 14
+	defaultMACDFast        = 12
+	defaultMACDSlow        = 26
+	defaultMACDSignal      = 9
+	bollingerStdDevs       = 2.0
+)
+
+// IndicatorSpec selects a technical indicator and its parameters.
+// Fast, Slow and Signal are only used by MACD.
+type IndicatorSpec struct {
+	Kind   string
+	Period int
+	Fast   int
+	Slow   int
+	Signal int
+}
+
+// Label returns a short display name such as "SMA(20)" or "MACD(12,26,9)".
+func (s IndicatorSpec) Label() string {
+	if s.Kind == IndicatorMACD {
+		return fmt.Sprintf("MACD(%d,%d,%d)", s.Fast, s.Slow, s.Signal)
+	}
+	return fmt.Sprintf("%s(%d)", strings.ToUpper(s.Kind), s.Period)
+}
+
+// IsOverlay reports whether the indicator is drawn on the price panel
+// rather than in its own sub-panel.
+func (s IndicatorSpec) IsOverlay() bool {
+	return s.Kind == IndicatorSMA || s.Kind == IndicatorEMA || s.Kind == IndicatorBB
+}
+
+// ParseIndicators parses a list like "sma:20,ema:50,bb:20,rsi:14,macd".
+// MACD accepts optional "macd:fast:slow:signal" parameters.
+func ParseIndicators(s string) ([]IndicatorSpec, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var specs []IndicatorSpec
+	for _, item := range strings.Split(s, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, ":")
+		params := make([]int, 0, len(parts)-1)
+		for _, p := range parts[1:] {
+			n, err := strconv.Atoi(p)
+			if err != nil || n < 2 {
+				return nil, fmt.Errorf("invalid indicator period in %q (must be an integer >= 2)", item)
+			}
+			params = append(params, n)
+		}
+
+		spec := IndicatorSpec{Kind: parts[0]}
+		switch spec.Kind {
+		case IndicatorSMA, IndicatorEMA, IndicatorBB, IndicatorRSI:
+			if len(params) > 1 {
+				return nil, fmt.Errorf("indicator %q takes a single period", item)
+			}
+			spec.Period = defaultIndicatorPeriod
+			if spec.Kind == IndicatorRSI {
+				spec.Period = defaultRSIPeriod
+			}
+			if len(params) == 1 {
+				spec.Period = params[0]
+			}
+		case IndicatorMACD:
+			spec.Fast, spec.Slow, spec.Signal = defaultMACDFast, defaultMACDSlow, defaultMACDSignal
+			switch len(params) {
+			case 0:
+			case 3:
+				spec.Fast, spec.Slow, spec.Signal = params[0], params[1], params[2]
+			default:
+				return nil, fmt.Errorf("indicator %q must be 'macd' or 'macd:fast:slow:signal'", item)
+			}
+			if spec.Fast >= spec.Slow {
+				return nil, fmt.Errorf("indicator %q: fast period must be below slow period", item)
+			}
+		default:
+			return nil, fmt.Errorf("unknown indicator %q (use sma, ema, bb, rsi or macd)", parts[0])
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// SMA returns the simple moving average. Values before the first full
+// window are NaN.
+func SMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period < 1 || len(values) < period {
+		return out
+	}
+	sum := 0.0
+	for i, v := range values {
+		sum += v
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			out[i] = sum / float64(period)
+		}
+	}
+	return out
+}
+
+// EMA returns the exponential moving average seeded with the SMA of the
+// first window. Values before the seed are NaN.
+func EMA(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period < 1 || len(values) < period {
+		return out
+	}
+	k := 2.0 / float64(period+1)
+	seed := 0.0
+	for _, v := range values[:period] {
+		seed += v
+	}
+	out[period-1] = seed / float64(period)
+	for i := period; i < len(values); i++ {
+		out[i] = values[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// BollingerBands returns the middle (SMA), upper and lower bands at
+// stdDevs population standard deviations.
+func BollingerBands(values []float64, period int, stdDevs float64) (mid, upper, lower []float64) {
+	mid = SMA(values, period)
+	upper = nanSlice(len(values))
+	lower = nanSlice(len(values))
+	for i := range values {
+		if math.IsNaN(mid[i]) {
+			continue
+		}
+		variance := 0.0
+		for _, v := range values[i-period+1 : i+1] {
+			variance += (v - mid[i]) * (v - mid[i])
+		}
+		sd := math.Sqrt(variance / float64(period))
+		upper[i] = mid[i] + stdDevs*sd
+		lower[i] = mid[i] - stdDevs*sd
+	}
+	return mid, upper, lower
+}
+
+// RSI returns Wilder's relative strength index in the range 0-100.
+func RSI(values []float64, period int) []float64 {
+	out := nanSlice(len(values))
+	if period < 1 || len(values) <= period {
+		return out
+	}
+	var gain, loss float64
+	for i := 1; i <= period; i++ {
+		change := values[i] - values[i-1]
+		if change > 0 {
+			gain += change
+		} else {
+			loss -= change
+		}
+	}
+	avgGain := gain / float64(period)
+	avgLoss := loss / float64(period)
+	out[period] = rsiValue(avgGain, avgLoss)
+	for i := period + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		g, l := 0.0, 0.0
+		if change > 0 {
+			g = change
+		} else {
+			l = -change
+		}
+		avgGain = (avgGain*float64(period-1) + g) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + l) / float64(period)
+		out[i] = rsiValue(avgGain, avgLoss)
+	}
+	return out
+}
+
+func rsiValue(avgGain, avgLoss float64) float64 {
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
+
+// MACD returns the MACD line (fast EMA - slow EMA), its signal EMA and the
+// histogram (MACD - signal).
+func MACD(values []float64, fast, slow, signal int) (macd, signalLine, hist []float64) {
+	fastEMA := EMA(values, fast)
+	slowEMA := EMA(values, slow)
+	macd = nanSlice(len(values))
+	first := -1
+	for i := range values {
+		if math.IsNaN(fastEMA[i]) || math.IsNaN(slowEMA[i]) {
+			continue
+		}
+		macd[i] = fastEMA[i] - slowEMA[i]
+		if first < 0 {
+			first = i
+		}
+	}
+
+	signalLine = nanSlice(len(values))
+	hist = nanSlice(len(values))
+	if first < 0 {
+		return macd, signalLine, hist
+	}
+	tail := EMA(macd[first:], signal)
+	for i, v := range tail {
+		signalLine[first+i] = v
+		if !math.IsNaN(v) {
+			hist[first+i] = macd[first+i] - v
+		}
+	}
+	return macd, signalLine, hist
+}
+
+func nanSlice(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
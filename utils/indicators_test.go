@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseIndicators(t *testing.T) {
+	specs, err := ParseIndicators("sma:20, EMA:50,bb,rsi,macd:5:10:3")
+	if err != nil {
+		t.Fatalf("ParseIndicators() error = %v", err)
+	}
+	if len(specs) != 5 {
+		t.Fatalf("expected 5 specs, got %d", len(specs))
+	}
+	if specs[1].Kind != IndicatorEMA || specs[1].Period != 50 {
+		t.Fatalf("unexpected EMA spec: %+v", specs[1])
+	}
+	if specs[2].Period != 20 || specs[3].Period != 14 {
+		t.Fatalf("expected default periods, got bb=%d rsi=%d", specs[2].Period, specs[3].Period)
+	}
+	if specs[4].Label() != "MACD(5,10,3)" {
+		t.Fatalf("MACD label = %q", specs[4].Label())
+	}
+
+	for _, bad := range []string{"vwap", "sma:x", "sma:1", "macd:26:12:9", "rsi:14:2"} {
+		if _, err := ParseIndicators(bad); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestSMAAndEMA(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	sma := SMA(values, 3)
+	if !math.IsNaN(sma[1]) || sma[2] != 2 || sma[4] != 4 {
+		t.Fatalf("SMA() = %v", sma)
+	}
+
+	ema := EMA(values, 3)
+	// Seed is SMA(1,2,3)=2, then k=0.5: 4*0.5+2*0.5=3, 5*0.5+3*0.5=4
+	if ema[2] != 2 || ema[3] != 3 || ema[4] != 4 {
+		t.Fatalf("EMA() = %v", ema)
+	}
+}
+
+func TestBollingerBandsFlatSeries(t *testing.T) {
+	mid, upper, lower := BollingerBands([]float64{10, 10, 10, 10}, 3, 2)
+	if mid[3] != 10 || upper[3] != 10 || lower[3] != 10 {
+		t.Fatalf("flat series bands = %v %v %v", mid[3], upper[3], lower[3])
+	}
+}
+
+func TestRSIBounds(t *testing.T) {
+	rising := []float64{1, 2, 3, 4, 5, 6}
+	if rsi := RSI(rising, 3); rsi[5] != 100 {
+		t.Fatalf("RSI of rising series = %v, want 100", rsi[5])
+	}
+	falling := []float64{6, 5, 4, 3, 2, 1}
+	if rsi := RSI(falling, 3); rsi[5] != 0 {
+		t.Fatalf("RSI of falling series = %v, want 0", rsi[5])
+	}
+}
+
+func TestMACDHistogramIsDifference(t *testing.T) {
+	values := make([]float64, 60)
+	for i := range values {
+		values[i] = 100 + float64(i)
+	}
+	macd, signal, hist := MACD(values, 12, 26, 9)
+	last := len(values) - 1
+	if math.IsNaN(hist[last]) {
+		t.Fatal("expected defined histogram at end of series")
+	}
+	if math.Abs(hist[last]-(macd[last]-signal[last])) > 1e-9 {
+		t.Fatalf("hist = %v, want macd-signal = %v", hist[last], macd[last]-signal[last])
+	}
+	if !math.IsNaN(macd[10]) {
+		t.Fatal("MACD should be undefined before slow EMA warm-up")
+	}
+}
+
+func TestRenderLineChartIndicatorPanels(t *testing.T) {
+	base := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	points := make([]SeriesPoint, 120)
+	for i := range points {
+		points[i] = SeriesPoint{Time: base.Add(time.Duration(i) * time.Hour), Value: 100 + math.Sin(float64(i)/8)*10}
+	}
+	specs, _ := ParseIndicators("sma:10,rsi:14,macd")
+	out := RenderLineChart(points, ChartConfig{Width: 50, Height: 12, CurrencySymbol: "$", YTickCount: 3, XTickCount: 3, Indicators: specs})
+
+	if !strings.Contains(out, "RSI(14)") || !strings.Contains(out, "MACD(12,26,9)") {
+		t.Fatalf("missing indicator sub-panels:\n%s", out)
+	}
+	if !strings.Contains(out, "SMA(10)") {
+		t.Fatalf("missing overlay legend:\n%s", out)
+	}
+	if strings.Count(out, "└") != 1 {
+		t.Fatalf("panels should share a single X axis:\n%s", out)
+	}
+	if strings.Count(out, "┼") != 2 {
+		t.Fatalf("expected two panel separators:\n%s", out)
+	}
+}